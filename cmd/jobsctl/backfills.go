@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jobs/scheduler/pkg/client"
+)
+
+// tasksBackfill 为任务补跑 [--start, --end] 区间内的触发时间
+func tasksBackfill(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("tasks backfill")
+	start := fs.String("start", "", "range start, RFC3339 or YYYY-MM-DD (start of day)")
+	end := fs.String("end", "", "range end, RFC3339 or YYYY-MM-DD (end of day), inclusive")
+	concurrency := fs.Int("max-concurrency", 1, "maximum number of unfinished executions at a time")
+	params := keyValues{}
+	fs.Var(params, "p", "parameter override as key=value, repeatable")
+	paramsJSON := fs.String("params", "", "parameter overrides as a JSON object")
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 1, "TASK --start TIME --end TIME [--max-concurrency N] [-p key=value]..."); err != nil {
+		return err
+	}
+
+	req := client.BackfillRequest{MaxConcurrency: *concurrency}
+	if req.StartTime, err = parseRangeTime(*start, false); err != nil {
+		return fmt.Errorf("invalid --start: %w", err)
+	}
+	if req.EndTime, err = parseRangeTime(*end, true); err != nil {
+		return fmt.Errorf("invalid --end: %w", err)
+	}
+	if *paramsJSON != "" || len(params) > 0 {
+		req.Parameters = map[string]interface{}{}
+		if *paramsJSON != "" {
+			if err := json.Unmarshal([]byte(*paramsJSON), &req.Parameters); err != nil {
+				return fmt.Errorf("invalid --params: %w", err)
+			}
+		}
+		for k, v := range params {
+			req.Parameters[k] = v
+		}
+	}
+
+	c := opts.client()
+	task, err := resolveTask(ctx, c, positional[0])
+	if err != nil {
+		return err
+	}
+	backfill, err := c.CreateBackfill(ctx, task.ID, req)
+	if err != nil {
+		return err
+	}
+	return render(opts, backfill, func(w io.Writer) {
+		fmt.Fprintf(w, "backfill %s created for task %s: %d executions, max concurrency %d\n",
+			backfill.ID, task.Name, backfill.Total, backfill.MaxConcurrency)
+	})
+}
+
+// parseRangeTime 解析 RFC3339 时间或本地日期，日期作为区间终点时取当天最后一秒
+func parseRangeTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("required")
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC3339 or YYYY-MM-DD, got %q", value)
+	}
+	if endOfDay {
+		return day.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return day, nil
+}
+
+func backfillsList(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("backfills list")
+	taskRef := fs.String("task", "", "filter by task ID or name")
+	status := fs.String("status", "", "filter by status")
+	limit := fs.Int("limit", 20, "maximum number of backfills, 0 for all")
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 0, "[--task TASK] [--status STATUS] [--limit N]"); err != nil {
+		return err
+	}
+
+	c := opts.client()
+	filter := client.BackfillFilter{Status: client.BackfillStatus(*status), PageSize: 100}
+	if *taskRef != "" {
+		task, err := resolveTask(ctx, c, *taskRef)
+		if err != nil {
+			return err
+		}
+		filter.TaskID = task.ID
+	}
+
+	backfills := []client.Backfill{}
+	for backfill, err := range c.Backfills(ctx, filter) {
+		if err != nil {
+			return err
+		}
+		backfills = append(backfills, backfill)
+		if *limit > 0 && len(backfills) >= *limit {
+			break
+		}
+	}
+
+	return render(opts, backfills, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tTASK\tSTATUS\tRANGE\tCREATED\tCONCURRENCY")
+		for _, backfill := range backfills {
+			taskName := backfill.TaskID
+			if backfill.Task != nil {
+				taskName = backfill.Task.Name
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s ~ %s\t%d/%d\t%d\n",
+				backfill.ID, taskName, backfill.Status,
+				formatTime(&backfill.StartTime), formatTime(&backfill.EndTime),
+				backfill.Created, backfill.Total, backfill.MaxConcurrency)
+		}
+	})
+}
+
+func backfillsDescribe(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("backfills describe")
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 1, "BACKFILL_ID"); err != nil {
+		return err
+	}
+
+	backfill, err := opts.client().GetBackfill(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	return render(opts, backfill, func(w io.Writer) {
+		fmt.Fprintf(w, "ID:\t%s\n", backfill.ID)
+		if backfill.Task != nil {
+			fmt.Fprintf(w, "Task:\t%s (%s)\n", backfill.Task.Name, backfill.TaskID)
+		} else {
+			fmt.Fprintf(w, "Task:\t%s\n", backfill.TaskID)
+		}
+		fmt.Fprintf(w, "Status:\t%s\n", backfill.Status)
+		fmt.Fprintf(w, "Range:\t%s ~ %s\n", formatTime(&backfill.StartTime), formatTime(&backfill.EndTime))
+		fmt.Fprintf(w, "Cron:\t%s %s\n", backfill.CronExpression, backfill.Timezone)
+		fmt.Fprintf(w, "Max concurrency:\t%d\n", backfill.MaxConcurrency)
+		fmt.Fprintf(w, "Created:\t%d/%d\n", backfill.Created, backfill.Total)
+		fmt.Fprintf(w, "Next:\t%s\n", formatTime(backfill.NextTime))
+		fmt.Fprintf(w, "Finished:\t%s\n", formatTime(backfill.FinishedAt))
+		if len(backfill.Parameters) > 0 {
+			params, _ := json.Marshal(backfill.Parameters)
+			fmt.Fprintf(w, "Parameters:\t%s\n", params)
+		}
+		if len(backfill.Executions) > 0 {
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, "EXECUTION\tSCHEDULED\tSTATUS\tDURATION")
+			for _, execution := range backfill.Executions {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+					execution.ID, formatTime(&execution.ScheduledTime), execution.Status,
+					formatDuration(execution.StartTime, execution.EndTime))
+			}
+		}
+	})
+}
+
+func backfillsCancel(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("backfills cancel")
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 1, "BACKFILL_ID"); err != nil {
+		return err
+	}
+
+	backfill, err := opts.client().CancelBackfill(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	return render(opts, backfill, func(w io.Writer) {
+		fmt.Fprintf(w, "backfill %s cancelled after %d/%d executions\n", backfill.ID, backfill.Created, backfill.Total)
+	})
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/jobs/scheduler/pkg/client"
+)
+
+func executorsList(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("executors list")
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 0, ""); err != nil {
+		return err
+	}
+
+	executors, err := opts.client().ListExecutors(ctx, false)
+	if err != nil {
+		return err
+	}
+	return render(opts, executors, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tNAME\tSTATUS\tHEALTHY\tURL\tLAST CHECK")
+		for _, executor := range executors {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%s\t%s\n",
+				executor.ID, executor.Name, executor.Status, executor.IsHealthy,
+				executor.BaseURL, formatTime(executor.LastHealthCheck))
+		}
+	})
+}
+
+func executorsDrain(ctx context.Context, args []string) error {
+	return setExecutorStatus(ctx, "executors drain", args, client.ExecutorStatusMaintenance)
+}
+
+func executorsUndrain(ctx context.Context, args []string) error {
+	return setExecutorStatus(ctx, "executors undrain", args, client.ExecutorStatusOnline)
+}
+
+// setExecutorStatus 维护状态的执行器不再接收新的执行，运行中的执行不受影响
+func setExecutorStatus(ctx context.Context, name string, args []string, status client.ExecutorStatus) error {
+	fs, opts := newFlagSet(name)
+	reason := fs.String("reason", "", "reason recorded in the scheduler log")
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 1, "EXECUTOR_ID [--reason TEXT]"); err != nil {
+		return err
+	}
+
+	c := opts.client()
+	if err := c.UpdateExecutorStatus(ctx, positional[0], status, *reason); err != nil {
+		return err
+	}
+	executor, err := c.GetExecutor(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	return render(opts, executor, func(w io.Writer) {
+		fmt.Fprintf(w, "executor %s is now %s\n", executor.ID, executor.Status)
+	})
+}
+
+func clusterStatus(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("cluster status")
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 0, ""); err != nil {
+		return err
+	}
+
+	status, err := opts.client().SchedulerStatus(ctx)
+	if err != nil {
+		return err
+	}
+	return render(opts, status, func(w io.Writer) {
+		for _, lease := range status.Leases {
+			state := "valid"
+			if lease.ExpiresAt.Before(status.Time) {
+				state = "expired"
+			}
+			fmt.Fprintf(w, "Leader:\t%s (lease %s, fencing token %d, expires %s, %s)\n",
+				lease.Holder, lease.Name, lease.FencingToken, formatTime(&lease.ExpiresAt), state)
+		}
+		if len(status.Leases) == 0 {
+			fmt.Fprintln(w, "Leader:\t-")
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "INSTANCE\tADDRESS\tLEADER\tLAST HEARTBEAT")
+		for _, instance := range status.Instances {
+			fmt.Fprintf(w, "%s\t%s:%d\t%t\t%s\n",
+				instance.InstanceID, instance.Host, instance.Port, instance.IsLeader, formatTime(&instance.UpdatedAt))
+		}
+	})
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jobs/scheduler/pkg/client"
+)
+
+func executionsList(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("executions list")
+	taskRef := fs.String("task", "", "filter by task ID or name")
+	status := fs.String("status", "", "filter by status")
+	runID := fs.String("workflow-run", "", "filter by workflow run ID")
+	backfillID := fs.String("backfill", "", "filter by backfill ID")
+	limit := fs.Int("limit", 20, "maximum number of executions, 0 for all")
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 0, "[--task TASK] [--status STATUS] [--limit N]"); err != nil {
+		return err
+	}
+
+	c := opts.client()
+	filter := client.ExecutionFilter{
+		Status:        client.ExecutionStatus(*status),
+		WorkflowRunID: *runID,
+		BackfillID:    *backfillID,
+		PageSize:      100,
+	}
+	if *taskRef != "" {
+		task, err := resolveTask(ctx, c, *taskRef)
+		if err != nil {
+			return err
+		}
+		filter.TaskID = task.ID
+	}
+
+	executions := []client.TaskExecution{}
+	for execution, err := range c.Executions(ctx, filter) {
+		if err != nil {
+			return err
+		}
+		executions = append(executions, execution)
+		if *limit > 0 && len(executions) >= *limit {
+			break
+		}
+	}
+
+	return render(opts, executions, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tTASK\tSTATUS\tSCHEDULED\tDURATION\tEXECUTOR\tRETRIES")
+		for _, execution := range executions {
+			taskName := execution.TaskID
+			if execution.Task != nil {
+				taskName = execution.Task.Name
+			}
+			executor := "-"
+			if execution.Executor != nil {
+				executor = execution.Executor.Name
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+				execution.ID, taskName, execution.Status, formatTime(&execution.ScheduledTime),
+				formatDuration(execution.StartTime, execution.EndTime), executor, execution.RetryCount)
+		}
+	})
+}
+
+func executionsDescribe(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("executions describe")
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 1, "EXECUTION_ID"); err != nil {
+		return err
+	}
+
+	execution, err := opts.client().GetExecution(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	return render(opts, execution, func(w io.Writer) {
+		fmt.Fprintf(w, "ID:\t%s\n", execution.ID)
+		if execution.Task != nil {
+			fmt.Fprintf(w, "Task:\t%s (%s)\n", execution.Task.Name, execution.TaskID)
+		} else {
+			fmt.Fprintf(w, "Task:\t%s\n", execution.TaskID)
+		}
+		fmt.Fprintf(w, "Status:\t%s\n", execution.Status)
+		if execution.Executor != nil {
+			fmt.Fprintf(w, "Executor:\t%s (%s)\n", execution.Executor.Name, execution.Executor.ID)
+		}
+		fmt.Fprintf(w, "Scheduled:\t%s\n", formatTime(&execution.ScheduledTime))
+		fmt.Fprintf(w, "Started:\t%s\n", formatTime(execution.StartTime))
+		fmt.Fprintf(w, "Ended:\t%s\n", formatTime(execution.EndTime))
+		fmt.Fprintf(w, "Duration:\t%s\n", formatDuration(execution.StartTime, execution.EndTime))
+		fmt.Fprintf(w, "Retries:\t%d\n", execution.RetryCount)
+		if execution.ProgressPercent != nil || execution.ProgressStage != "" {
+			percent := "-"
+			if execution.ProgressPercent != nil {
+				percent = fmt.Sprintf("%.1f%%", *execution.ProgressPercent)
+			}
+			fmt.Fprintf(w, "Progress:\t%s %s\n", percent, execution.ProgressStage)
+		}
+		if execution.WorkflowRunID != nil {
+			fmt.Fprintf(w, "Workflow run:\t%s\n", *execution.WorkflowRunID)
+		}
+		if execution.RerunOfExecutionID != nil {
+			fmt.Fprintf(w, "Rerun of:\t%s\n", *execution.RerunOfExecutionID)
+		}
+		if execution.BackfillID != nil {
+			fmt.Fprintf(w, "Backfill:\t%s\n", *execution.BackfillID)
+		}
+		if len(execution.Parameters) > 0 {
+			params, _ := json.Marshal(execution.Parameters)
+			fmt.Fprintf(w, "Parameters:\t%s\n", params)
+		}
+		if len(execution.RenderedParameters) > 0 {
+			rendered, _ := json.Marshal(execution.RenderedParameters)
+			fmt.Fprintf(w, "Rendered parameters:\t%s\n", rendered)
+		}
+		if len(execution.Result) > 0 {
+			result, _ := json.Marshal(execution.Result)
+			fmt.Fprintf(w, "Result:\t%s\n", result)
+		}
+		if execution.Logs != "" {
+			fmt.Fprintf(w, "Logs:\t%s\n", execution.Logs)
+		}
+	})
+}
+
+func executionsStop(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("executions stop")
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 1, "EXECUTION_ID"); err != nil {
+		return err
+	}
+
+	resp, err := opts.client().StopExecution(ctx, positional[0])
+	if err != nil {
+		return err
+	}
+	return render(opts, resp, func(w io.Writer) {
+		fmt.Fprintf(w, "execution %s is now %s\n", resp.ExecutionID, resp.Status)
+	})
+}
+
+// executionsRerun 重跑执行，不带参数时沿用原参数，-p/--params 覆盖原参数中的同名项后作为完整参数提交
+func executionsRerun(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("executions rerun")
+	params := keyValues{}
+	fs.Var(params, "p", "parameter override as key=value, repeatable")
+	paramsJSON := fs.String("params", "", "parameter overrides as a JSON object")
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 1, "EXECUTION_ID [-p key=value]... [--params JSON]"); err != nil {
+		return err
+	}
+
+	c := opts.client()
+	var parameters map[string]interface{}
+	if len(params) > 0 || *paramsJSON != "" {
+		overrides := map[string]interface{}{}
+		if *paramsJSON != "" {
+			if err := json.Unmarshal([]byte(*paramsJSON), &overrides); err != nil {
+				return fmt.Errorf("invalid --params: %w", err)
+			}
+		}
+		for k, v := range params {
+			overrides[k] = v
+		}
+
+		source, err := c.GetExecution(ctx, positional[0])
+		if err != nil {
+			return err
+		}
+		// 与原样重跑一致，以原执行实际下发的参数为基础
+		base := source.RenderedParameters
+		if base == nil {
+			base = source.Parameters
+		}
+		parameters = map[string]interface{}{}
+		for k, v := range base {
+			parameters[k] = v
+		}
+		for k, v := range overrides {
+			parameters[k] = v
+		}
+	}
+
+	execution, err := c.RerunExecution(ctx, positional[0], parameters)
+	if err != nil {
+		return err
+	}
+	return render(opts, execution, func(w io.Writer) {
+		fmt.Fprintf(w, "execution %s created as rerun of %s (%s)\n", execution.ID, positional[0], execution.Status)
+	})
+}
+
+// executionsLogs 输出执行日志，-f 时持续跟踪直到执行结束；日志内容原样输出，不受 -o 影响
+func executionsLogs(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("executions logs")
+	follow := fs.Bool("f", false, "follow the logs until the execution finishes")
+	after := fs.Uint64("after", 0, "only show chunks after this chunk ID")
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 1, "EXECUTION_ID [-f]"); err != nil {
+		return err
+	}
+
+	c := opts.client()
+	executionID := positional[0]
+	cursor := *after
+
+	if *follow {
+		_, err := c.FollowExecutionLogs(ctx, executionID, cursor, func(chunk client.ExecutionLogChunk) error {
+			_, err := io.WriteString(stdout, chunk.Content)
+			return err
+		})
+		return err
+	}
+
+	for {
+		page, err := c.ExecutionLogs(ctx, executionID, cursor)
+		if err != nil {
+			return err
+		}
+		if cursor == *after && page.LogsRotated {
+			fmt.Fprintln(stdout, "[earlier logs were rotated]")
+		}
+		for _, chunk := range page.Chunks {
+			if _, err := io.WriteString(stdout, chunk.Content); err != nil {
+				return err
+			}
+		}
+		if !page.HasMore {
+			return nil
+		}
+		cursor = page.NextAfter
+	}
+}
@@ -0,0 +1,228 @@
+// jobsctl 调度器命令行工具
+//
+//	jobsctl tasks list|describe|trigger|backfill|pause|resume|export|apply|diff|import
+//	jobsctl executions list|describe|stop|rerun|logs
+//	jobsctl backfills list|describe|cancel
+//	jobsctl executors list|drain|undrain
+//	jobsctl cluster status
+//
+// 调度器地址通过 --server 或环境变量 JOBSCTL_SERVER 指定，输出格式通过 -o table|json|yaml 指定。
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jobs/scheduler/pkg/client"
+)
+
+// globalOptions 每个子命令都支持的参数
+type globalOptions struct {
+	server  string
+	output  string
+	timeout time.Duration
+}
+
+// command 子命令，args 为去掉命令名后的参数
+type command struct {
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+// commands 资源 -> 动作 -> 子命令
+var commands = map[string]map[string]command{
+	"tasks": {
+		"list":     {"列出任务", tasksList},
+		"describe": {"查看任务详情（ID或名称）", tasksDescribe},
+		"trigger":  {"手动触发任务", tasksTrigger},
+		"backfill": {"补跑 --start 到 --end 区间内的触发时间", tasksBackfill},
+		"pause":    {"暂停任务调度", tasksPause},
+		"resume":   {"恢复任务调度", tasksResume},
+		"export":   {"导出任务清单", tasksExport},
+		"apply":    {"应用任务清单，--prune 删除清单之外的任务", tasksApply},
+		"diff":     {"比较任务清单与调度器中的任务", tasksDiff},
+		"import":   {"apply 的别名，保留以兼容旧脚本", tasksImport},
+	},
+	"executions": {
+		"list":     {"列出执行历史", executionsList},
+		"describe": {"查看执行详情", executionsDescribe},
+		"stop":     {"停止执行", executionsStop},
+		"rerun":    {"重跑已结束的执行，-p/--params 在原参数基础上修改", executionsRerun},
+		"logs":     {"查看执行日志，-f 持续跟踪", executionsLogs},
+	},
+	"backfills": {
+		"list":     {"列出补跑", backfillsList},
+		"describe": {"查看补跑进度和执行", backfillsDescribe},
+		"cancel":   {"取消补跑及其未结束的执行", backfillsCancel},
+	},
+	"executors": {
+		"list":    {"列出执行器", executorsList},
+		"drain":   {"将执行器置为维护状态，不再分发新的执行", executorsDrain},
+		"undrain": {"将执行器恢复为在线状态", executorsUndrain},
+	},
+	"cluster": {
+		"status": {"查看调度器实例和领导者", clusterStatus},
+	},
+}
+
+var stdout io.Writer = os.Stdout
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) < 2 {
+		usage(os.Stderr)
+		if len(args) == 1 && (args[0] == "help" || args[0] == "-h" || args[0] == "--help") {
+			return nil
+		}
+		return errors.New("a resource and an action are required")
+	}
+
+	actions, ok := commands[args[0]]
+	if !ok {
+		usage(os.Stderr)
+		return fmt.Errorf("unknown resource %q", args[0])
+	}
+	cmd, ok := actions[args[1]]
+	if !ok {
+		usage(os.Stderr)
+		return fmt.Errorf("unknown action %q for %s", args[1], args[0])
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return cmd.run(ctx, args[2:])
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: jobsctl <resource> <action> [flags] [args]")
+	fmt.Fprintln(w)
+
+	resources := make([]string, 0, len(commands))
+	for resource := range commands {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	for _, resource := range resources {
+		actions := make([]string, 0, len(commands[resource]))
+		for action := range commands[resource] {
+			actions = append(actions, action)
+		}
+		sort.Strings(actions)
+		for _, action := range actions {
+			fmt.Fprintf(w, "  %-12s %-10s %s\n", resource, action, commands[resource][action].summary)
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Global flags:")
+	fmt.Fprintln(w, "  --server   scheduler address (default $JOBSCTL_SERVER or http://localhost:8080)")
+	fmt.Fprintln(w, "  -o         output format: table, json or yaml (default table)")
+	fmt.Fprintln(w, "  --timeout  request timeout (default 30s)")
+}
+
+// newFlagSet 创建包含全局参数的子命令参数集
+func newFlagSet(name string) (*flag.FlagSet, *globalOptions) {
+	opts := &globalOptions{}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	server := os.Getenv("JOBSCTL_SERVER")
+	if server == "" {
+		server = "http://localhost:8080"
+	}
+	fs.StringVar(&opts.server, "server", server, "scheduler address")
+	fs.StringVar(&opts.output, "o", "table", "output format: table, json or yaml")
+	fs.DurationVar(&opts.timeout, "timeout", 30*time.Second, "request timeout")
+	return fs, opts
+}
+
+// parseArgs 解析参数，允许参数与位置参数交错，返回位置参数
+func parseArgs(fs *flag.FlagSet, opts *globalOptions, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	switch opts.output {
+	case "table", "json", "yaml":
+	default:
+		return nil, fmt.Errorf("unsupported output format %q", opts.output)
+	}
+	return positional, nil
+}
+
+// exactArgs 校验位置参数个数
+func exactArgs(fs *flag.FlagSet, positional []string, n int, names string) error {
+	if len(positional) != n {
+		return fmt.Errorf("usage: jobsctl %s %s", fs.Name(), names)
+	}
+	return nil
+}
+
+func (o *globalOptions) client() *client.Client {
+	return client.New(client.Config{BaseURL: o.server, HTTPClient: newHTTPClient(o.timeout)})
+}
+
+// resolveTask 按ID或名称查找任务
+func resolveTask(ctx context.Context, c *client.Client, ref string) (*client.Task, error) {
+	task, err := c.GetTask(ctx, ref)
+	if err == nil {
+		return task, nil
+	}
+	if !errors.Is(err, client.ErrNotFound) {
+		return nil, err
+	}
+
+	tasks, err := c.ListTasks(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		if tasks[i].Name == ref && tasks[i].Status != client.TaskStatusDeleted {
+			return c.GetTask(ctx, tasks[i].ID)
+		}
+	}
+	return nil, fmt.Errorf("task %q not found", ref)
+}
+
+// keyValues 可重复的 key=value 参数
+type keyValues map[string]interface{}
+
+func (kv keyValues) String() string {
+	pairs := make([]string, 0, len(kv))
+	for k, v := range kv {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", k, v))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (kv keyValues) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	kv[key] = val
+	return nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jobs/scheduler/pkg/client"
+)
+
+// tasksExport 将未删除的任务导出为清单，默认 YAML 格式，可直接用于 tasks apply
+func tasksExport(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("tasks export")
+	file := fs.String("f", "", "write to file instead of stdout")
+	opts.output = "yaml"
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 0, "[-f FILE]"); err != nil {
+		return err
+	}
+	if opts.output == "table" {
+		return fmt.Errorf("tasks export supports -o json or yaml")
+	}
+
+	c := opts.client()
+	tasks, err := c.ListTasks(ctx, "")
+	if err != nil {
+		return err
+	}
+	names := make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		names[task.ID] = task.Name
+	}
+
+	m := client.Manifest{Tasks: []client.TaskSpec{}}
+	for _, summary := range tasks {
+		if summary.Status == client.TaskStatusDeleted {
+			continue
+		}
+		task, err := c.GetTask(ctx, summary.ID)
+		if err != nil {
+			return err
+		}
+		deps, err := c.TaskDependencies(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+
+		maxRetry := task.MaxRetry
+		spec := client.TaskSpec{
+			Name:                task.Name,
+			CronExpression:      task.CronExpression,
+			Timezone:            task.Timezone,
+			Parameters:          task.Parameters,
+			ExecutionMode:       task.ExecutionMode,
+			LoadBalanceStrategy: task.LoadBalanceStrategy,
+			MaxRetry:            &maxRetry,
+			RetryPolicy:         task.RetryPolicy,
+			TimeoutSeconds:      task.TimeoutSeconds,
+			MisfirePolicy:       task.MisfirePolicy,
+			MisfireLimit:        task.MisfireLimit,
+			Status:              task.Status,
+			Executors:           []client.ExecutorSpec{},
+		}
+		for _, dep := range deps.Upstream {
+			name, ok := names[dep.DependsOnTaskID]
+			if !ok {
+				name = dep.DependsOnTaskID
+			}
+			spec.Dependencies = append(spec.Dependencies, client.DependencySpec{Task: name, FailurePolicy: dep.FailurePolicy})
+		}
+		for _, assignment := range task.TaskExecutors {
+			spec.Executors = append(spec.Executors, client.ExecutorSpec{
+				ID:       assignment.ExecutorID,
+				Priority: assignment.Priority,
+				Weight:   assignment.Weight,
+			})
+		}
+		m.Tasks = append(m.Tasks, spec)
+	}
+	sort.Slice(m.Tasks, func(i, j int) bool { return m.Tasks[i].Name < m.Tasks[j].Name })
+
+	if *file == "" {
+		return render(opts, m, nil)
+	}
+	f, err := os.Create(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	out := stdout
+	stdout = f
+	defer func() { stdout = out }()
+	return render(opts, m, nil)
+}
+
+func tasksApply(ctx context.Context, args []string) error {
+	return applyManifest(ctx, "tasks apply", args, false)
+}
+
+// tasksImport 早期的导入命令，旧的任务定义文件是清单格式的子集，直接按 apply 处理
+func tasksImport(ctx context.Context, args []string) error {
+	return applyManifest(ctx, "tasks import", args, false)
+}
+
+func tasksDiff(ctx context.Context, args []string) error {
+	return applyManifest(ctx, "tasks diff", args, true)
+}
+
+// applyManifest 读取清单并提交到调度器，diff 固定为 dry-run
+func applyManifest(ctx context.Context, name string, args []string, diffOnly bool) error {
+	fs, opts := newFlagSet(name)
+	file := fs.String("f", "", "manifest file (YAML or JSON), - for stdin")
+	prune := fs.Bool("prune", false, "delete tasks that are not in the manifest")
+	dryRun := &diffOnly
+	if !diffOnly {
+		dryRun = fs.Bool("dry-run", false, "show the changes without applying them")
+	}
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 0, "-f FILE [--prune]"); err != nil {
+		return err
+	}
+	if *file == "" {
+		return fmt.Errorf("-f is required")
+	}
+
+	var data []byte
+	if *file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*file)
+	}
+	if err != nil {
+		return err
+	}
+	var m client.Manifest
+	if err := fromYAML(data, &m); err != nil {
+		return fmt.Errorf("parse %s: %w", *file, err)
+	}
+
+	plan, err := opts.client().Apply(ctx, &m, client.ApplyOptions{DryRun: *dryRun, Prune: *prune})
+	if err != nil {
+		return err
+	}
+	return render(opts, plan, func(w io.Writer) {
+		fmt.Fprintln(w, "ACTION\tTASK\tCHANGES")
+		for _, change := range plan.Changes {
+			fields := make([]string, 0, len(change.Fields))
+			for _, f := range change.Fields {
+				fields = append(fields, fmt.Sprintf("%s: %s -> %s", f.Field, compactJSON(f.Old), compactJSON(f.New)))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", change.Action, change.Task, orDash(strings.Join(fields, "; ")))
+		}
+		fmt.Fprintf(w, "%d changed, %d unchanged", len(plan.Changes), len(plan.Unchanged))
+		if plan.DryRun {
+			fmt.Fprint(w, " (dry run, nothing applied)")
+		}
+		fmt.Fprintln(w)
+	})
+}
+
+func compactJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// render 按 -o 输出 v，table 格式时调用 table 写入表格
+func render(opts *globalOptions, v interface{}, table func(w io.Writer)) error {
+	switch opts.output {
+	case "json":
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(stdout, string(data))
+		return err
+	case "yaml":
+		data, err := toYAML(v)
+		if err != nil {
+			return err
+		}
+		_, err = stdout.Write(data)
+		return err
+	default:
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		table(w)
+		return w.Flush()
+	}
+}
+
+// toYAML 经 JSON 转换后输出 YAML，字段名与 API 保持一致，字段顺序与结构体定义一致
+// JSON 本身是合法的 YAML，解析为节点可以保留字段顺序，再清除流式风格输出为块格式
+func toYAML(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	resetStyle(&node)
+	return yaml.Marshal(&node)
+}
+
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// fromYAML 将 YAML 或 JSON 解码到 v，同样经 JSON 转换以使用 json 标签，未知字段视为错误
+func fromYAML(data []byte, v interface{}) error {
+	var generic interface{}
+	if err := yaml.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+	converted, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(converted))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout}
+}
+
+// formatTime 格式化可选时间，空值显示为 -
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// formatDuration 计算开始到结束（未结束时到现在）的耗时
+func formatDuration(start, end *time.Time) string {
+	if start == nil {
+		return "-"
+	}
+	stop := time.Now()
+	if end != nil {
+		stop = *end
+	}
+	return stop.Sub(*start).Round(time.Second).String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jobs/scheduler/pkg/client"
+)
+
+func tasksList(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("tasks list")
+	status := fs.String("status", "", "filter by status: active, paused or deleted")
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 0, "[--status STATUS]"); err != nil {
+		return err
+	}
+
+	tasks, err := opts.client().ListTasks(ctx, client.TaskStatus(*status))
+	if err != nil {
+		return err
+	}
+	return render(opts, tasks, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tNAME\tSTATUS\tCRON\tTIMEZONE\tMODE\tNEXT RUN")
+		for _, task := range tasks {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				task.ID, task.Name, task.Status, task.CronExpression, orDash(task.Timezone),
+				task.ExecutionMode, formatTime(task.NextRunTime))
+		}
+	})
+}
+
+func tasksDescribe(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("tasks describe")
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 1, "TASK"); err != nil {
+		return err
+	}
+
+	c := opts.client()
+	task, err := resolveTask(ctx, c, positional[0])
+	if err != nil {
+		return err
+	}
+	deps, err := c.TaskDependencies(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+
+	detail := struct {
+		*client.Task
+		Dependencies *client.TaskDependencies `json:"dependencies"`
+	}{task, deps}
+	return render(opts, detail, func(w io.Writer) {
+		params, _ := json.Marshal(task.Parameters)
+		fmt.Fprintf(w, "ID:\t%s\n", task.ID)
+		fmt.Fprintf(w, "Name:\t%s\n", task.Name)
+		fmt.Fprintf(w, "Status:\t%s\n", task.Status)
+		fmt.Fprintf(w, "Cron:\t%s\n", task.CronExpression)
+		fmt.Fprintf(w, "Timezone:\t%s\n", orDash(task.Timezone))
+		fmt.Fprintf(w, "Next run:\t%s\n", formatTime(task.NextRunTime))
+		fmt.Fprintf(w, "Execution mode:\t%s\n", task.ExecutionMode)
+		fmt.Fprintf(w, "Load balance:\t%s\n", task.LoadBalanceStrategy)
+		fmt.Fprintf(w, "Max retry:\t%d\n", task.MaxRetry)
+		if task.RetryPolicy != nil {
+			policy, _ := json.Marshal(task.RetryPolicy)
+			fmt.Fprintf(w, "Retry policy:\t%s\n", policy)
+		} else {
+			fmt.Fprintf(w, "Retry policy:\t-\n")
+		}
+		fmt.Fprintf(w, "Timeout:\t%ds\n", task.TimeoutSeconds)
+		fmt.Fprintf(w, "Misfire policy:\t%s\n", task.MisfirePolicy)
+		fmt.Fprintf(w, "Parameters:\t%s\n", params)
+		if len(task.ParametersSchema) > 0 {
+			schema, _ := json.Marshal(task.ParametersSchema)
+			fmt.Fprintf(w, "Parameters schema:\t%s\n", schema)
+		}
+		for _, dep := range deps.Upstream {
+			fmt.Fprintf(w, "Depends on:\t%s (%s)\n", dep.DependsOnTaskID, dep.FailurePolicy)
+		}
+		for _, dep := range deps.Downstream {
+			fmt.Fprintf(w, "Required by:\t%s (%s)\n", dep.TaskID, dep.FailurePolicy)
+		}
+		for _, assignment := range task.TaskExecutors {
+			name := assignment.ExecutorID
+			if assignment.Executor != nil {
+				name = fmt.Sprintf("%s (%s, %s)", assignment.Executor.Name, assignment.ExecutorID, assignment.Executor.Status)
+			}
+			fmt.Fprintf(w, "Executor:\t%s priority=%d weight=%d\n", name, assignment.Priority, assignment.Weight)
+		}
+	})
+}
+
+func tasksTrigger(ctx context.Context, args []string) error {
+	fs, opts := newFlagSet("tasks trigger")
+	params := keyValues{}
+	fs.Var(params, "p", "parameter override as key=value, repeatable")
+	paramsJSON := fs.String("params", "", "parameter overrides as a JSON object")
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 1, "TASK [-p key=value]... [--params JSON]"); err != nil {
+		return err
+	}
+
+	parameters := map[string]interface{}{}
+	if *paramsJSON != "" {
+		if err := json.Unmarshal([]byte(*paramsJSON), &parameters); err != nil {
+			return fmt.Errorf("invalid --params: %w", err)
+		}
+	}
+	for k, v := range params {
+		parameters[k] = v
+	}
+
+	c := opts.client()
+	task, err := resolveTask(ctx, c, positional[0])
+	if err != nil {
+		return err
+	}
+	execution, err := c.TriggerTask(ctx, task.ID, parameters)
+	if err != nil {
+		return err
+	}
+	return render(opts, execution, func(w io.Writer) {
+		fmt.Fprintf(w, "execution %s created for task %s (%s)\n", execution.ID, task.Name, execution.Status)
+	})
+}
+
+func tasksPause(ctx context.Context, args []string) error {
+	return setTaskScheduling(ctx, "tasks pause", args, false)
+}
+
+func tasksResume(ctx context.Context, args []string) error {
+	return setTaskScheduling(ctx, "tasks resume", args, true)
+}
+
+// setTaskScheduling 暂停或恢复任务调度
+func setTaskScheduling(ctx context.Context, name string, args []string, active bool) error {
+	fs, opts := newFlagSet(name)
+	positional, err := parseArgs(fs, opts, args)
+	if err != nil {
+		return err
+	}
+	if err := exactArgs(fs, positional, 1, "TASK"); err != nil {
+		return err
+	}
+
+	c := opts.client()
+	task, err := resolveTask(ctx, c, positional[0])
+	if err != nil {
+		return err
+	}
+	if active {
+		err = c.ResumeTask(ctx, task.ID)
+	} else {
+		err = c.PauseTask(ctx, task.ID)
+	}
+	if err != nil {
+		return err
+	}
+
+	task, err = c.GetTask(ctx, task.ID)
+	if err != nil {
+		return err
+	}
+	return render(opts, task, func(w io.Writer) {
+		fmt.Fprintf(w, "task %s is now %s\n", task.Name, task.Status)
+	})
+}
@@ -40,10 +40,15 @@ func main() {
 	defer zapLogger.Sync()
 
 	zapLogger.Info("Starting job scheduler",
-		zap.String("instance_id", cfg.Scheduler.InstanceID))
+		zap.String("instance_id", cfg.Scheduler.InstanceID),
+		zap.String("advertise_addr", cfg.Scheduler.AdvertiseAddr),
+		zap.String("callback_base_url", cfg.Scheduler.CallbackBase()))
 
 	// 创建存储
 	storageConfig := storage.Config{
+		Driver:                cfg.Database.Driver,
+		DSN:                   cfg.Database.DSN,
+		SSLMode:               cfg.Database.SSLMode,
 		Host:                  cfg.Database.Host,
 		Port:                  cfg.Database.Port,
 		Database:              cfg.Database.Database,
@@ -73,6 +78,7 @@ func main() {
 
 	// 创建执行器管理器
 	executorManager := executor.NewManager(db, zapLogger)
+	executorManager.SetRegistrationToken(cfg.Scheduler.RegistrationToken)
 
 	// 创建API服务器
 	apiServer := api.NewServer(db, sched, executorManager, sched.GetTaskRunner(), zapLogger)
@@ -8,8 +8,11 @@ import (
 	"log"
 	"math/rand"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/jobs/scheduler/pkg/callbackauth"
 )
 
 // ExecuteRequest 执行请求
@@ -18,17 +21,45 @@ type ExecuteRequest struct {
 	TaskID      string                 `json:"task_id"`
 	TaskName    string                 `json:"task_name"`
 	Parameters  map[string]interface{} `json:"parameters"`
+	Attempt     int                    `json:"attempt"` // 回调、进度和日志上报时原样回显
 	CallbackURL string                 `json:"callback_url"`
 }
 
 // CallbackRequest 回调请求
 type CallbackRequest struct {
 	ExecutionID string                 `json:"execution_id"`
+	Attempt     int                    `json:"attempt"`
 	Status      string                 `json:"status"`
 	Result      map[string]interface{} `json:"result"`
 	Logs        string                 `json:"logs"`
 }
 
+// ProgressRequest 进度上报请求，同时作为心跳
+type ProgressRequest struct {
+	ExecutionID string           `json:"execution_id"`
+	Attempt     int              `json:"attempt"`
+	Percent     *float64         `json:"percent,omitempty"`
+	Stage       string           `json:"stage,omitempty"`
+	Counters    map[string]int64 `json:"counters,omitempty"`
+}
+
+// LogRequest 日志追加请求，Seq 在每次执行内从1开始递增
+type LogRequest struct {
+	ExecutionID string `json:"execution_id"`
+	Attempt     int    `json:"attempt"`
+	Seq         int64  `json:"seq"`
+	Content     string `json:"content"`
+}
+
+// RegisterResponse 注册响应中用于签名回调的凭证
+type RegisterResponse struct {
+	ID             string `json:"id"`
+	CallbackSecret string `json:"callback_secret"`
+}
+
+// credentials 注册后获得的回调凭证
+var credentials RegisterResponse
+
 // TaskDefinition 任务定义
 type TaskDefinition struct {
 	Name                string                 `json:"name"`
@@ -64,17 +95,56 @@ type RunningTask struct {
 	StartTime   time.Time
 }
 
+// FinishedTask 已结束的任务，保留一段时间供调度器查询状态
+type FinishedTask struct {
+	Callback   CallbackRequest
+	FinishedAt time.Time
+}
+
+// finishedRetention 已结束任务的保留时间
+const finishedRetention = time.Hour
+
 // TaskManager 任务管理器
 type TaskManager struct {
-	mu    sync.RWMutex
-	tasks map[string]*RunningTask
+	mu       sync.RWMutex
+	tasks    map[string]*RunningTask
+	finished map[string]*FinishedTask
 }
 
 // NewTaskManager 创建任务管理器
 func NewTaskManager() *TaskManager {
 	return &TaskManager{
-		tasks: make(map[string]*RunningTask),
+		tasks:    make(map[string]*RunningTask),
+		finished: make(map[string]*FinishedTask),
+	}
+}
+
+// Finish 记录任务结果，并清理超过保留时间的记录
+func (tm *TaskManager) Finish(callback CallbackRequest) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	now := time.Now()
+	for id, task := range tm.finished {
+		if now.Sub(task.FinishedAt) > finishedRetention {
+			delete(tm.finished, id)
+		}
+	}
+	tm.finished[callback.ExecutionID] = &FinishedTask{Callback: callback, FinishedAt: now}
+}
+
+// Status 查询任务状态：运行中、已结束（返回回调内容）或 unknown
+func (tm *TaskManager) Status(executionID string) CallbackRequest {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if task, ok := tm.finished[executionID]; ok {
+		return task.Callback
 	}
+	if _, ok := tm.tasks[executionID]; ok {
+		return CallbackRequest{ExecutionID: executionID, Status: "running"}
+	}
+	return CallbackRequest{ExecutionID: executionID, Status: "unknown"}
 }
 
 // Add 添加任务
@@ -129,6 +199,7 @@ func main() {
 	http.HandleFunc("/health", healthHandler)
 	http.HandleFunc("/execute", executeHandler)
 	http.HandleFunc("/stop", stopHandler)
+	http.HandleFunc("/status/", statusHandler)
 
 	// 启动时自动注册到调度器
 	if err := registerToScheduler(); err != nil {
@@ -227,14 +298,15 @@ func registerToScheduler() error {
 		return fmt.Errorf("registration failed with status %d", resp.StatusCode)
 	}
 
-	// 解析响应
-	var response map[string]interface{}
+	// 解析响应，保存回调签名凭证
+	var response RegisterResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return fmt.Errorf("failed to decode response: %w", err)
 	}
+	credentials = response
 
 	log.Printf("Successfully registered to scheduler!")
-	log.Printf("Response: %+v", response)
+	log.Printf("Registered as executor %s", response.ID)
 	return nil
 }
 
@@ -281,22 +353,56 @@ func executeHandler(w http.ResponseWriter, r *http.Request) {
 		timer := time.NewTimer(duration)
 		defer timer.Stop()
 
-		select {
-		case <-ctx.Done():
-			// 任务被取消
-			log.Printf("Task %s (ID: %s) was cancelled", req.TaskName, req.ExecutionID)
-			callback := CallbackRequest{
-				ExecutionID: req.ExecutionID,
-				Status:      "cancelled",
-				Result:      map[string]interface{}{"reason": "Task was stopped by user"},
-				Logs:        fmt.Sprintf("Task %s was cancelled after %v", req.TaskName, time.Since(task.StartTime)),
-			}
-			if err := sendCallback(req.CallbackURL, callback); err != nil {
-				log.Printf("Failed to send cancellation callback: %v", err)
+		// 每秒上报一次进度和日志，调度器据此顺延超时
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		var logSeq int64
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				// 任务被取消
+				log.Printf("Task %s (ID: %s) was cancelled", req.TaskName, req.ExecutionID)
+				callback := CallbackRequest{
+					ExecutionID: req.ExecutionID,
+					Attempt:     req.Attempt,
+					Status:      "cancelled",
+					Result:      map[string]interface{}{"reason": "Task was stopped by user"},
+					Logs:        fmt.Sprintf("Task %s was cancelled after %v", req.TaskName, time.Since(task.StartTime)),
+				}
+				if err := sendCallback(req.CallbackURL, callback); err != nil {
+					log.Printf("Failed to send cancellation callback: %v", err)
+				}
+				return
+			case <-ticker.C:
+				elapsed := time.Since(task.StartTime)
+				percent := float64(elapsed) / float64(duration) * 100
+				if percent > 100 {
+					percent = 100
+				}
+				if err := sendProgress(req.CallbackURL, ProgressRequest{
+					ExecutionID: req.ExecutionID,
+					Attempt:     req.Attempt,
+					Percent:     &percent,
+					Stage:       "running",
+					Counters:    map[string]int64{"elapsed_seconds": int64(elapsed.Seconds())},
+				}); err != nil {
+					log.Printf("Failed to send progress: %v", err)
+				}
+				logSeq++
+				if err := sendLog(req.CallbackURL, LogRequest{
+					ExecutionID: req.ExecutionID,
+					Attempt:     req.Attempt,
+					Seq:         logSeq,
+					Content:     fmt.Sprintf("%s [INFO] %s running, %.0f%% done\n", time.Now().Format(time.RFC3339), req.TaskName, percent),
+				}); err != nil {
+					log.Printf("Failed to send log: %v", err)
+				}
+			case <-timer.C:
+				// 继续执行任务
+				break wait
 			}
-			return
-		case <-timer.C:
-			// 继续执行任务
 		}
 
 		// 根据任务名称执行不同的逻辑
@@ -350,6 +456,7 @@ func executeHandler(w http.ResponseWriter, r *http.Request) {
 			log.Printf("Task %s (ID: %s) was cancelled before callback", req.TaskName, req.ExecutionID)
 			callback := CallbackRequest{
 				ExecutionID: req.ExecutionID,
+				Attempt:     req.Attempt,
 				Status:      "cancelled",
 				Result:      map[string]interface{}{"reason": "Task was stopped by user"},
 				Logs:        fmt.Sprintf("Task %s was cancelled after %v", req.TaskName, time.Since(task.StartTime)),
@@ -362,6 +469,7 @@ func executeHandler(w http.ResponseWriter, r *http.Request) {
 			// 回调调度器
 			callback := CallbackRequest{
 				ExecutionID: req.ExecutionID,
+				Attempt:     req.Attempt,
 				Status:      status,
 				Result:      result,
 				Logs:        logs,
@@ -411,20 +519,62 @@ func stopHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// statusHandler 处理执行状态查询，调度器在回调丢失时据此结束执行
+func statusHandler(w http.ResponseWriter, r *http.Request) {
+	executionID := strings.TrimPrefix(r.URL.Path, "/status/")
+	if executionID == "" {
+		http.Error(w, "execution id is required", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(taskManager.Status(executionID))
+}
+
+// sendProgress 上报执行进度，进度接口与回调接口位于同一路径下
+func sendProgress(callbackURL string, progress ProgressRequest) error {
+	url := strings.TrimSuffix(callbackURL, "/callback") + "/progress"
+	return postSigned(url, progress)
+}
+
+// sendLog 追加执行日志，日志接口与回调接口位于同一路径下
+func sendLog(callbackURL string, entry LogRequest) error {
+	url := strings.TrimSuffix(callbackURL, "/callback") + "/logs"
+	return postSigned(url, entry)
+}
+
 func sendCallback(url string, callback CallbackRequest) error {
-	jsonData, err := json.Marshal(callback)
+	// 先记录结果，回调失败时调度器仍可通过状态查询获知
+	taskManager.Finish(callback)
+
+	return postSigned(url, callback)
+}
+
+// postSigned 使用注册时获得的密钥签名后发送请求，调度器拒绝未签名的回调、进度和日志
+func postSigned(url string, payload interface{}) error {
+	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return err
 	}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := callbackauth.SignRequest(req, credentials.ID, credentials.CallbackSecret, jsonData); err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("callback returned status %d", resp.StatusCode)
+		return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
 	}
 
 	return nil
@@ -7,7 +7,10 @@ import (
 	"log"
 	"math/rand"
 	"net/http"
+	"os"
 	"time"
+
+	"github.com/jobs/scheduler/pkg/callbackauth"
 )
 
 // ExecuteRequest 执行请求
@@ -16,17 +19,31 @@ type ExecuteRequest struct {
 	TaskID      string                 `json:"task_id"`
 	TaskName    string                 `json:"task_name"`
 	Parameters  map[string]interface{} `json:"parameters"`
+	Attempt     int                    `json:"attempt"` // 回调、进度和日志上报时原样回显
 	CallbackURL string                 `json:"callback_url"`
 }
 
 // CallbackRequest 回调请求
 type CallbackRequest struct {
 	ExecutionID string                 `json:"execution_id"`
+	Attempt     int                    `json:"attempt"`
 	Status      string                 `json:"status"`
 	Result      map[string]interface{} `json:"result"`
 	Logs        string                 `json:"logs"`
 }
 
+// RegisterResponse 注册响应中用于签名回调的凭证
+type RegisterResponse struct {
+	ID             string
+	CallbackSecret string
+}
+
+// credentials 本示例不自动注册，回调凭证从环境变量读取
+var credentials = RegisterResponse{
+	ID:             os.Getenv("EXECUTOR_ID"),
+	CallbackSecret: os.Getenv("CALLBACK_SECRET"),
+}
+
 func main() {
 	http.HandleFunc("/health", healthHandler)
 	http.HandleFunc("/execute", executeHandler)
@@ -75,6 +92,7 @@ func executeHandler(w http.ResponseWriter, r *http.Request) {
 		// 回调调度器
 		callback := CallbackRequest{
 			ExecutionID: req.ExecutionID,
+			Attempt:     req.Attempt,
 			Status:      status,
 			Result: map[string]interface{}{
 				"duration":   duration.Seconds(),
@@ -104,7 +122,18 @@ func sendCallback(url string, callback CallbackRequest) error {
 		return err
 	}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// 使用注册时获得的执行器ID和密钥签名，调度器拒绝未签名的回调
+	if err := callbackauth.SignRequest(req, credentials.ID, credentials.CallbackSecret, jsonData); err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
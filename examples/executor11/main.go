@@ -8,6 +8,8 @@ import (
 	"math/rand"
 	"net/http"
 	"time"
+
+	"github.com/jobs/scheduler/pkg/callbackauth"
 )
 
 // ExecuteRequest 执行请求
@@ -27,6 +29,15 @@ type CallbackRequest struct {
 	Logs        string                 `json:"logs"`
 }
 
+// RegisterResponse 注册响应中用于签名回调的凭证
+type RegisterResponse struct {
+	ID             string `json:"id"`
+	CallbackSecret string `json:"callback_secret"`
+}
+
+// credentials 注册后获得的回调凭证
+var credentials RegisterResponse
+
 // TaskDefinition 任务定义
 type TaskDefinition struct {
 	Name                string                 `json:"name"`
@@ -158,14 +169,15 @@ func registerToScheduler() error {
 		return fmt.Errorf("registration failed with status %d", resp.StatusCode)
 	}
 
-	// 解析响应
-	var response map[string]interface{}
+	// 解析响应，保存回调签名凭证
+	var response RegisterResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return fmt.Errorf("failed to decode response: %w", err)
 	}
+	credentials = response
 
 	log.Printf("Successfully registered to scheduler!")
-	log.Printf("Response: %+v", response)
+	log.Printf("Registered as executor %s", response.ID)
 	return nil
 }
 
@@ -270,7 +282,18 @@ func sendCallback(url string, callback CallbackRequest) error {
 		return err
 	}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// 使用注册时获得的密钥签名，调度器拒绝未签名的回调
+	if err := callbackauth.SignRequest(req, credentials.ID, credentials.CallbackSecret, jsonData); err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
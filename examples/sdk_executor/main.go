@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jobs/scheduler/pkg/executor"
+	"go.uber.org/zap"
+)
+
+func main() {
+	logger, _ := zap.NewDevelopment()
+	defer logger.Sync()
+
+	sdk, err := executor.New(executor.Config{
+		SchedulerURL:   "http://localhost:8080",
+		ExecutorID:     "sdk-executor-1",
+		ExecutorName:   "SDK示例执行器",
+		ListenAddr:     ":9092",
+		MaxConcurrency: 4,
+		Metadata:       map[string]interface{}{"language": "go", "sdk": true},
+		Logger:         logger,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create executor: %v", err)
+	}
+
+	// 任务不存在时按定义创建，初始为暂停状态
+	sdk.HandleTask(executor.TaskDefinition{
+		Name:           "daily_report",
+		CronExpression: "0 0 6 * * *",
+		TimeoutSeconds: 600,
+		Parameters:     map[string]interface{}{"region": "cn"},
+		// 调度器按 schema 校验默认参数和手动触发时覆盖的参数
+		ParametersSchema: map[string]interface{}{
+			"type":     "object",
+			"required": []string{"region"},
+			"properties": map[string]interface{}{
+				"region": map[string]interface{}{"type": "string", "enum": []string{"cn", "us", "eu"}},
+				"date":   map[string]interface{}{"type": "string", "format": "date"},
+			},
+			"additionalProperties": false,
+		},
+	}, dailyReport)
+
+	go func() {
+		if err := sdk.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Executor stopped: %v", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	// 最多等待 30 秒让运行中的执行结束
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := sdk.Shutdown(ctx); err != nil {
+		log.Printf("Executor shutdown: %v", err)
+	}
+}
+
+// dailyReport 模拟分阶段生成报表，期间上报进度和日志
+func dailyReport(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error) {
+	stages := []string{"extract", "aggregate", "render"}
+	rows := int64(0)
+
+	for i, stage := range stages {
+		executor.Logf(ctx, "%s [INFO] stage %s started, region=%v", time.Now().Format(time.RFC3339), stage, params["region"])
+
+		select {
+		case <-time.After(time.Duration(rand.Intn(3)+1) * time.Second):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		rows += int64(rand.Intn(1000))
+		percent := float64(i+1) / float64(len(stages)) * 100
+		if err := executor.ReportProgress(ctx, executor.Progress{
+			Percent:  &percent,
+			Stage:    stage,
+			Counters: map[string]int64{"rows": rows},
+		}); err != nil {
+			log.Printf("Failed to report progress: %v", err)
+		}
+	}
+
+	return map[string]interface{}{"rows": rows}, nil
+}
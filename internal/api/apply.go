@@ -0,0 +1,48 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jobs/scheduler/internal/manifest"
+	"github.com/jobs/scheduler/internal/scheduler"
+)
+
+// applyManifest 应用 YAML/JSON 任务清单，dry_run=true 只返回差异，prune=true 删除清单之外的任务
+func (s *Server) applyManifest(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	m, err := manifest.Parse(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := s.manifestApplier.Apply(c.Request.Context(), m, manifest.Options{
+		DryRun: c.Query("dry_run") == "true",
+		Prune:  c.Query("prune") == "true",
+	})
+	switch {
+	case errors.Is(err, manifest.ErrInvalidManifest), errors.Is(err, scheduler.ErrDependencyCycle):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// syncTask 调度器未启用时（如测试）跳过刷新调度条目
+func (s *Server) syncTask(taskID string) error {
+	if s.scheduler == nil {
+		return nil
+	}
+	return s.scheduler.SyncTask(taskID)
+}
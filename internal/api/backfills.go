@@ -0,0 +1,142 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/internal/scheduler"
+	"gorm.io/gorm"
+)
+
+// createBackfill 为任务创建历史区间补跑
+func (s *Server) createBackfill(c *gin.Context) {
+	taskID := c.Param("id")
+
+	var req BackfillRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	backfill, err := s.scheduler.Backfills().Create(c.Request.Context(), taskID, scheduler.BackfillOptions{
+		StartTime:      req.StartTime,
+		EndTime:        req.EndTime,
+		MaxConcurrency: req.MaxConcurrency,
+		Parameters:     req.Parameters,
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	case errors.Is(err, scheduler.ErrInvalidBackfill):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		s.respondParameterError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, backfill)
+}
+
+// listBackfills 获取补跑列表
+func (s *Server) listBackfills(c *gin.Context) {
+	type PaginatedResponse struct {
+		Data       []models.Backfill `json:"data"`
+		Total      int64             `json:"total"`
+		Page       int               `json:"page"`
+		PageSize   int               `json:"page_size"`
+		TotalPages int               `json:"total_pages"`
+	}
+
+	query := s.storage.DB().Model(&models.Backfill{})
+
+	if taskID := c.Query("task_id"); taskID != "" {
+		query = query.Where("task_id = ?", taskID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	page := 1
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	pageSize := 20
+	if ps := c.Query("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+
+	var backfills []models.Backfill
+	if err := query.Preload("Task").
+		Order("created_at DESC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&backfills).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Data:       backfills,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	})
+}
+
+// getBackfill 获取补跑详情（包含已创建的执行，按逻辑时间排序）
+func (s *Server) getBackfill(c *gin.Context) {
+	var backfill models.Backfill
+	if err := s.storage.DB().
+		Preload("Task").
+		Preload("Executions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("scheduled_time ASC")
+		}).
+		Preload("Executions.Executor").
+		Where("id = ?", c.Param("id")).
+		First(&backfill).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "backfill not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, backfill)
+}
+
+// cancelBackfill 取消补跑，不再创建新的执行并取消未结束的执行
+func (s *Server) cancelBackfill(c *gin.Context) {
+	backfill, err := s.scheduler.Backfills().Cancel(c.Request.Context(), c.Param("id"))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "backfill not found"})
+		return
+	case errors.Is(err, scheduler.ErrBackfillState):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, backfill)
+}
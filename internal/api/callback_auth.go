@@ -0,0 +1,140 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/pkg/callbackauth"
+	"go.uber.org/zap"
+	"gorm.io/gorm/clause"
+)
+
+// noncePurgeInterval 清理过期回调 nonce 的最小间隔
+const noncePurgeInterval = time.Minute
+
+// bindSignedRequest 读取执行器签名的请求（回调、进度上报）：解析请求体，校验其中的执行ID与路径一致，
+// 再验证签名；失败时已写入响应并返回 false
+func (s *Server) bindSignedRequest(c *gin.Context, req interface{}, bodyExecutionID func() string) bool {
+	executionID := c.Param("id")
+
+	// 签名针对原始请求体计算，先读取再解析
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if err := binding.JSON.BindBody(body, req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if bodyExecutionID() != executionID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "execution_id does not match the request url"})
+		return false
+	}
+
+	var execution models.TaskExecution
+	if err := s.storage.DB().Where("id = ?", executionID).First(&execution).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return false
+	}
+
+	if status, err := s.authenticateCallback(c, &execution, body); err != nil {
+		s.logger.Warn("executor request rejected",
+			zap.String("path", c.FullPath()),
+			zap.String("execution_id", executionID),
+			zap.String("executor_id", c.GetHeader(callbackauth.HeaderExecutorID)),
+			zap.String("client_ip", c.ClientIP()),
+			zap.Error(err))
+		c.JSON(status, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// authenticateCallback 校验回调来自执行记录所属的执行器，且签名有效、未被重放
+// 校验失败时返回应答使用的HTTP状态码
+func (s *Server) authenticateCallback(c *gin.Context, execution *models.TaskExecution, body []byte) (int, error) {
+	executorID := c.GetHeader(callbackauth.HeaderExecutorID)
+	if executorID == "" {
+		return http.StatusUnauthorized, fmt.Errorf("callback is not signed")
+	}
+
+	if execution.ExecutorID == nil || *execution.ExecutorID != executorID {
+		return http.StatusForbidden, fmt.Errorf("executor %s is not assigned to this execution", executorID)
+	}
+
+	var exec models.Executor
+	if err := s.storage.DB().Select("id", "callback_secret").Where("id = ?", executorID).First(&exec).Error; err != nil {
+		return http.StatusForbidden, fmt.Errorf("executor not found")
+	}
+	if exec.CallbackSecret == "" {
+		return http.StatusUnauthorized, fmt.Errorf("executor has no callback credentials, register again to obtain one")
+	}
+
+	return s.verifySignature(c, executorID, exec.CallbackSecret, body)
+}
+
+// verifySignature 校验请求由 executorID 使用 secret 签名，且时间戳有效、nonce 未被使用
+func (s *Server) verifySignature(c *gin.Context, executorID, secret string, body []byte) (int, error) {
+	timestamp := c.GetHeader(callbackauth.HeaderTimestamp)
+	nonce := c.GetHeader(callbackauth.HeaderNonce)
+	signature := c.GetHeader(callbackauth.HeaderSignature)
+	if timestamp == "" || nonce == "" || signature == "" {
+		return http.StatusUnauthorized, fmt.Errorf("callback is not signed")
+	}
+	if c.GetHeader(callbackauth.HeaderExecutorID) != executorID {
+		return http.StatusForbidden, fmt.Errorf("request is not signed by executor %s", executorID)
+	}
+
+	if err := callbackauth.CheckTimestamp(timestamp, time.Now()); err != nil {
+		return http.StatusUnauthorized, err
+	}
+	if !callbackauth.Verify(secret, timestamp, nonce, body, signature) {
+		return http.StatusUnauthorized, fmt.Errorf("invalid callback signature")
+	}
+
+	return s.useNonce(executorID, nonce)
+}
+
+// useNonce 记录 nonce，有效期内重复出现视为重放
+// nonce 保存在数据库中，多个调度器实例共享
+func (s *Server) useNonce(executorID, nonce string) (int, error) {
+	now := time.Now()
+	s.purgeNonces(now)
+
+	result := s.storage.DB().
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.CallbackNonce{
+			ExecutorID: executorID,
+			Nonce:      nonce,
+			ExpiresAt:  now.Add(2 * callbackauth.MaxSkew),
+		})
+	if result.Error != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to record callback nonce: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return http.StatusUnauthorized, fmt.Errorf("callback nonce has already been used")
+	}
+	return http.StatusOK, nil
+}
+
+// purgeNonces 删除过期的 nonce，至多每 noncePurgeInterval 执行一次
+func (s *Server) purgeNonces(now time.Time) {
+	s.nonceMu.Lock()
+	if now.Sub(s.noncePurgedAt) < noncePurgeInterval {
+		s.nonceMu.Unlock()
+		return
+	}
+	s.noncePurgedAt = now
+	s.nonceMu.Unlock()
+
+	if err := s.storage.DB().
+		Where("expires_at < ?", now).
+		Delete(&models.CallbackNonce{}).Error; err != nil {
+		s.logger.Error("failed to purge expired callback nonces", zap.Error(err))
+	}
+}
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/executor"
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/internal/scheduler"
+	"github.com/jobs/scheduler/internal/storage"
+	"github.com/jobs/scheduler/pkg/callbackauth"
+	"github.com/jobs/scheduler/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestExecutionCallbackAuthentication(t *testing.T) {
+	st, err := storage.New(storage.Config{
+		Driver:   storage.DriverSQLite,
+		Database: filepath.Join(t.TempDir(), "api.db"),
+	})
+	require.NoError(t, err)
+	defer st.Close()
+
+	executorID := "executor-1"
+	require.NoError(t, st.DB().Create(&models.Task{ID: "task-1", Name: "task-1", CronExpression: "0 * * * * *"}).Error)
+	require.NoError(t, st.DB().Create(&models.Executor{ID: executorID, Name: "e1", InstanceID: "e1", BaseURL: "http://e1", CallbackSecret: "secret-1"}).Error)
+	require.NoError(t, st.DB().Create(&models.Executor{ID: "executor-2", Name: "e2", InstanceID: "e2", BaseURL: "http://e2", CallbackSecret: "secret-2"}).Error)
+	require.NoError(t, st.DB().Create(&models.TaskExecution{
+		ID:            "exec-1",
+		TaskID:        "task-1",
+		ExecutorID:    &executorID,
+		ScheduledTime: time.Now(),
+		Status:        models.ExecutionStatusRunning,
+	}).Error)
+
+	logger := zap.NewNop()
+	runner := scheduler.NewTaskRunner(st, nil, nil, logger, config.SchedulerConfig{InstanceID: "test", MaxWorkers: 1})
+	server := NewServer(st, nil, nil, runner, logger)
+
+	body := []byte(`{"execution_id":"exec-1","attempt":0,"status":"success","logs":"done"}`)
+	send := func(sign func(req *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/executions/exec-1/callback", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if sign != nil {
+			sign(req)
+		}
+		w := httptest.NewRecorder()
+		server.Router().ServeHTTP(w, req)
+		return w
+	}
+
+	// 未签名
+	assert.Equal(t, http.StatusUnauthorized, send(nil).Code)
+
+	// 其他执行器用自己的密钥签名
+	assert.Equal(t, http.StatusForbidden, send(func(req *http.Request) {
+		require.NoError(t, callbackauth.SignRequest(req, "executor-2", "secret-2", body))
+	}).Code)
+
+	// 密钥错误
+	assert.Equal(t, http.StatusUnauthorized, send(func(req *http.Request) {
+		require.NoError(t, callbackauth.SignRequest(req, executorID, "secret-2", body))
+	}).Code)
+
+	// 正确签名，重放同一请求被拒绝
+	var headers http.Header
+	assert.Equal(t, http.StatusOK, send(func(req *http.Request) {
+		require.NoError(t, callbackauth.SignRequest(req, executorID, "secret-1", body))
+		headers = req.Header.Clone()
+	}).Code)
+	assert.Equal(t, http.StatusUnauthorized, send(func(req *http.Request) {
+		req.Header = headers
+	}).Code)
+
+	// 重复回调幂等，与当前终态冲突的回调返回409
+	assert.Equal(t, http.StatusOK, send(func(req *http.Request) {
+		require.NoError(t, callbackauth.SignRequest(req, executorID, "secret-1", body))
+	}).Code)
+	body = []byte(`{"execution_id":"exec-1","attempt":0,"status":"failed","logs":"late"}`)
+	assert.Equal(t, http.StatusConflict, send(func(req *http.Request) {
+		require.NoError(t, callbackauth.SignRequest(req, executorID, "secret-1", body))
+	}).Code)
+
+	// 进度上报使用同样的签名，执行结束后返回409
+	progress := []byte(`{"execution_id":"exec-1","attempt":0,"percent":100}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/executions/exec-1/progress", bytes.NewReader(progress))
+	req.Header.Set("Content-Type", "application/json")
+	require.NoError(t, callbackauth.SignRequest(req, executorID, "secret-1", progress))
+	w := httptest.NewRecorder()
+	server.Router().ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var execution models.TaskExecution
+	require.NoError(t, st.DB().Where("id = ?", "exec-1").First(&execution).Error)
+	assert.Equal(t, models.ExecutionStatusSuccess, execution.Status)
+	assert.Equal(t, "done", execution.Logs)
+}
+
+func TestExecutorReRegistrationRequiresCredentials(t *testing.T) {
+	st, err := storage.New(storage.Config{
+		Driver:   storage.DriverSQLite,
+		Database: filepath.Join(t.TempDir(), "api.db"),
+	})
+	require.NoError(t, err)
+	defer st.Close()
+
+	logger := zap.NewNop()
+	runner := scheduler.NewTaskRunner(st, nil, nil, logger, config.SchedulerConfig{InstanceID: "test", MaxWorkers: 1})
+	manager := executor.NewManager(st, logger)
+	server := NewServer(st, nil, manager, runner, logger)
+
+	body := []byte(`{"executor_id":"worker-1","executor_name":"worker-1","executor_url":"http://worker-1:9090"}`)
+	register := func(sign func(req *http.Request)) (*httptest.ResponseRecorder, executor.RegisterResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/executors/register", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if sign != nil {
+			sign(req)
+		}
+		w := httptest.NewRecorder()
+		server.Router().ServeHTTP(w, req)
+		var resp executor.RegisterResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w, resp
+	}
+	currentSecret := func() string {
+		var exec models.Executor
+		require.NoError(t, st.DB().Where("instance_id = ?", "worker-1").First(&exec).Error)
+		return exec.CallbackSecret
+	}
+
+	// 首次注册签发密钥
+	w, first := register(nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotEmpty(t, first.CallbackSecret)
+
+	// 未出示凭证的重新注册被拒绝，密钥不轮换也不返回
+	w, _ = register(nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.NotContains(t, w.Body.String(), "callback_secret")
+	assert.Equal(t, first.CallbackSecret, currentSecret())
+
+	// 用错误的密钥签名
+	w, _ = register(func(req *http.Request) {
+		require.NoError(t, callbackauth.SignRequest(req, first.ID, "guessed", body))
+	})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, first.CallbackSecret, currentSecret())
+
+	// 未配置令牌时随意携带令牌无效
+	w, _ = register(func(req *http.Request) {
+		req.Header.Set(callbackauth.HeaderRegistrationToken, "anything")
+	})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// 当前密钥签名的重新注册轮换密钥
+	w, second := register(func(req *http.Request) {
+		require.NoError(t, callbackauth.SignRequest(req, first.ID, first.CallbackSecret, body))
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, first.ID, second.ID)
+	assert.NotEqual(t, first.CallbackSecret, second.CallbackSecret)
+
+	// 旧密钥随之失效
+	w, _ = register(func(req *http.Request) {
+		require.NoError(t, callbackauth.SignRequest(req, first.ID, first.CallbackSecret, body))
+	})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// 配置注册令牌后，丢失密钥的执行器凭令牌重新注册
+	manager.SetRegistrationToken("bootstrap")
+	w, _ = register(func(req *http.Request) {
+		req.Header.Set(callbackauth.HeaderRegistrationToken, "wrong")
+	})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, second.CallbackSecret, currentSecret())
+
+	w, third := register(func(req *http.Request) {
+		req.Header.Set(callbackauth.HeaderRegistrationToken, "bootstrap")
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, second.CallbackSecret, third.CallbackSecret)
+}
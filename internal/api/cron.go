@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jobs/scheduler/pkg/cronexpr"
+)
+
+// 预览触发时间的数量限制
+const (
+	defaultPreviewCount = 5
+	maxPreviewCount     = 100
+)
+
+// previewCron 预览表达式在指定时区下接下来的触发时间
+func (s *Server) previewCron(c *gin.Context) {
+	var req CronPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	count := req.Count
+	if count <= 0 {
+		count = defaultPreviewCount
+	}
+	if count > maxPreviewCount {
+		count = maxPreviewCount
+	}
+
+	from := time.Now()
+	if req.From != nil {
+		from = *req.From
+	}
+
+	times, err := cronexpr.NextTimes(req.Expression, req.Timezone, from, count)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"expression": req.Expression,
+		"timezone":   req.Timezone,
+		"next_times": times,
+	})
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/internal/scheduler"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// getTaskDependencies 获取任务的上下游依赖
+func (s *Server) getTaskDependencies(c *gin.Context) {
+	taskID := c.Param("id")
+
+	var task models.Task
+	if err := s.storage.DB().Where("id = ?", taskID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+
+	upstream, err := s.scheduler.DAG().GetUpstream(c.Request.Context(), taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	downstream, err := s.scheduler.DAG().GetDownstream(c.Request.Context(), taskID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"upstream":   upstream,
+		"downstream": downstream,
+	})
+}
+
+// addTaskDependency 为任务新增上游依赖
+func (s *Server) addTaskDependency(c *gin.Context) {
+	taskID := c.Param("id")
+
+	var req DependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !validFailurePolicy(req.FailurePolicy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid failure_policy"})
+		return
+	}
+
+	var task models.Task
+	if err := s.storage.DB().Where("id = ?", taskID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+
+	dep, err := s.scheduler.DAG().AddDependency(c.Request.Context(), taskID, req.DependsOnTaskID, req.FailurePolicy)
+	if err != nil {
+		s.respondDependencyError(c, err)
+		return
+	}
+	s.syncDependentTask(taskID)
+
+	c.JSON(http.StatusCreated, dep)
+}
+
+// setTaskDependencies 替换任务的全部上游依赖
+func (s *Server) setTaskDependencies(c *gin.Context) {
+	taskID := c.Param("id")
+
+	var req SetDependenciesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, d := range req.Dependencies {
+		if !validFailurePolicy(d.FailurePolicy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid failure_policy"})
+			return
+		}
+	}
+
+	var task models.Task
+	if err := s.storage.DB().Where("id = ?", taskID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+		return
+	}
+
+	deps, err := s.scheduler.DAG().SetDependencies(c.Request.Context(), taskID, toDependencies(req.Dependencies))
+	if err != nil {
+		s.respondDependencyError(c, err)
+		return
+	}
+	s.syncDependentTask(taskID)
+
+	c.JSON(http.StatusOK, deps)
+}
+
+// removeTaskDependency 删除任务的上游依赖
+func (s *Server) removeTaskDependency(c *gin.Context) {
+	taskID := c.Param("id")
+	upstreamID := c.Param("upstream_id")
+
+	err := s.scheduler.DAG().RemoveDependency(c.Request.Context(), taskID, upstreamID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "dependency not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	s.syncDependentTask(taskID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "dependency removed"})
+}
+
+// syncDependentTask 有上游依赖的任务不由cron触发，依赖变化后立即更新其cron条目
+func (s *Server) syncDependentTask(taskID string) {
+	if err := s.syncTask(taskID); err != nil {
+		s.logger.Error("failed to sync task after dependency change", zap.String("task_id", taskID), zap.Error(err))
+	}
+}
+
+// respondDependencyError 依赖校验错误返回400，其余返回500
+func (s *Server) respondDependencyError(c *gin.Context, err error) {
+	if errors.Is(err, scheduler.ErrDependencyCycle) || errors.Is(err, scheduler.ErrInvalidDependency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jobs/scheduler/internal/executor"
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/internal/scheduler"
+)
+
+const (
+	// logPageLimit 单次返回的最大日志块数
+	logPageLimit = 500
+	// logFollowPollInterval 跟踪日志时轮询新日志块的间隔，日志可能由其他实例写入，因此从数据库轮询
+	logFollowPollInterval = time.Second
+	// logFollowKeepAlive 跟踪日志时没有新日志也定期发送注释，避免连接被代理断开
+	logFollowKeepAlive = 15 * time.Second
+)
+
+// appendExecutionLog 执行器追加执行日志
+func (s *Server) appendExecutionLog(c *gin.Context) {
+	executionID := c.Param("id")
+
+	var req executor.ExecutionLogRequest
+	if !s.bindSignedRequest(c, &req, func() string { return req.ExecutionID }) {
+		return
+	}
+
+	err := s.taskRunner.AppendLog(c.Request.Context(), executionID, req)
+	switch {
+	case errors.Is(err, scheduler.ErrLogChunkTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, scheduler.ErrExecutionNotRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "log appended"})
+}
+
+// getExecutionLogs 获取执行日志，after 为上次收到的最后一个日志块ID
+// follow=true 时以 SSE 持续推送新日志块，执行结束且日志读完后发送 end 事件并关闭
+func (s *Server) getExecutionLogs(c *gin.Context) {
+	executionID := c.Param("id")
+
+	after, err := strconv.ParseUint(c.DefaultQuery("after", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid after"})
+		return
+	}
+
+	execution, err := s.loadExecutionLogState(executionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return
+	}
+
+	if c.Query("follow") == "true" {
+		// 断线重连时浏览器通过 Last-Event-ID 携带最后收到的日志块ID
+		if lastEventID := c.GetHeader("Last-Event-ID"); lastEventID != "" {
+			if id, err := strconv.ParseUint(lastEventID, 10, 64); err == nil {
+				after = id
+			}
+		}
+		s.followExecutionLogs(c, executionID, after)
+		return
+	}
+
+	chunks, err := s.listExecutionLogs(executionID, after)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	next := after
+	if len(chunks) > 0 {
+		next = chunks[len(chunks)-1].ID
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"execution_id": executionID,
+		"status":       execution.Status,
+		"logs_rotated": execution.LogsRotated,
+		"chunks":       chunks,
+		"next_after":   next,
+		"has_more":     len(chunks) == logPageLimit,
+	})
+}
+
+// followExecutionLogs 以 SSE 推送 after 之后的日志块
+func (s *Server) followExecutionLogs(c *gin.Context, executionID string, after uint64) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// 跟踪可能持续很久，不受服务端写超时限制
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	ticker := time.NewTicker(logFollowPollInterval)
+	defer ticker.Stop()
+	lastWrite := time.Now()
+
+	for {
+		// 先读取状态再读取日志，状态为终态时读到的日志已包含结束前写入的全部日志块
+		execution, err := s.loadExecutionLogState(executionID)
+		if err != nil {
+			writeSSE(c, "error", "", gin.H{"error": "execution not found"})
+			return
+		}
+
+		chunks, err := s.listExecutionLogs(executionID, after)
+		if err != nil {
+			writeSSE(c, "error", "", gin.H{"error": err.Error()})
+			return
+		}
+		for i := range chunks {
+			writeSSE(c, "log", strconv.FormatUint(chunks[i].ID, 10), chunks[i])
+			after = chunks[i].ID
+		}
+		if len(chunks) > 0 {
+			lastWrite = time.Now()
+		}
+		if len(chunks) == logPageLimit {
+			continue
+		}
+
+		if execution.Status.IsTerminal() {
+			writeSSE(c, "end", "", gin.H{
+				"execution_id": executionID,
+				"status":       execution.Status,
+				"logs_rotated": execution.LogsRotated,
+			})
+			return
+		}
+
+		if time.Since(lastWrite) >= logFollowKeepAlive {
+			fmt.Fprint(c.Writer, ": keep-alive\n\n")
+			c.Writer.Flush()
+			lastWrite = time.Now()
+		}
+
+		select {
+		case <-ticker.C:
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+func (s *Server) loadExecutionLogState(executionID string) (*models.TaskExecution, error) {
+	var execution models.TaskExecution
+	if err := s.storage.DB().
+		Select("id", "status", "logs_rotated").
+		Where("id = ?", executionID).
+		First(&execution).Error; err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+func (s *Server) listExecutionLogs(executionID string, after uint64) ([]models.ExecutionLogChunk, error) {
+	var chunks []models.ExecutionLogChunk
+	if err := s.storage.DB().
+		Where("execution_id = ? AND id > ?", executionID, after).
+		Order("id").
+		Limit(logPageLimit).
+		Find(&chunks).Error; err != nil {
+		return nil, fmt.Errorf("failed to load execution logs: %w", err)
+	}
+	return chunks, nil
+}
+
+// writeSSE 写入一个 SSE 事件并立即刷新
+func writeSSE(c *gin.Context, event, id string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	if id != "" {
+		fmt.Fprintf(c.Writer, "id: %s\n", id)
+	}
+	fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event, payload)
+	c.Writer.Flush()
+}
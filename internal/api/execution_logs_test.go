@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/internal/scheduler"
+	"github.com/jobs/scheduler/internal/storage"
+	"github.com/jobs/scheduler/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestGetExecutionLogs(t *testing.T) {
+	st, err := storage.New(storage.Config{
+		Driver:   storage.DriverSQLite,
+		Database: filepath.Join(t.TempDir(), "api.db"),
+	})
+	require.NoError(t, err)
+	defer st.Close()
+
+	end := time.Now()
+	require.NoError(t, st.DB().Create(&models.Task{ID: "task-1", Name: "task-1", CronExpression: "0 * * * * *"}).Error)
+	require.NoError(t, st.DB().Create(&models.TaskExecution{
+		ID:            "exec-1",
+		TaskID:        "task-1",
+		ScheduledTime: end,
+		EndTime:       &end,
+		Status:        models.ExecutionStatusSuccess,
+	}).Error)
+	first := models.ExecutionLogChunk{ExecutionID: "exec-1", Seq: 1, Content: "line 1\n", Size: 7}
+	require.NoError(t, st.DB().Create(&first).Error)
+	require.NoError(t, st.DB().Create(&models.ExecutionLogChunk{ExecutionID: "exec-1", Seq: 2, Content: "line 2\n", Size: 7}).Error)
+
+	logger := zap.NewNop()
+	runner := scheduler.NewTaskRunner(st, nil, nil, logger, config.SchedulerConfig{InstanceID: "test", MaxWorkers: 1})
+	server := NewServer(st, nil, nil, runner, logger)
+	get := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		server.Router().ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		return w
+	}
+
+	// 分页读取 after 之后的日志块
+	w := get(fmt.Sprintf("/api/v1/executions/exec-1/logs?after=%d", first.ID))
+	require.Equal(t, http.StatusOK, w.Code)
+	var page struct {
+		Chunks    []models.ExecutionLogChunk `json:"chunks"`
+		NextAfter uint64                     `json:"next_after"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page.Chunks, 1)
+	assert.Equal(t, "line 2\n", page.Chunks[0].Content)
+	assert.Equal(t, page.Chunks[0].ID, page.NextAfter)
+
+	// 已结束的执行推送完全部日志后发送 end 事件
+	w = get("/api/v1/executions/exec-1/logs?follow=true")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	assert.Equal(t, 2, strings.Count(body, "event: log\n"))
+	assert.Contains(t, body, fmt.Sprintf("id: %d\n", first.ID))
+	assert.Contains(t, body, "event: end\n")
+
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/executions/missing/logs").Code)
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jobs/scheduler/pkg/jsonschema"
+	"github.com/jobs/scheduler/pkg/paramtemplate"
+)
+
+// respondParameterError 参数模板不合法或参数不满足 schema 时返回 400 和逐字段的错误
+func (s *Server) respondParameterError(c *gin.Context, err error) {
+	var verr *jsonschema.ValidationError
+	var terr *paramtemplate.Error
+	switch {
+	case errors.As(err, &verr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "fields": verr.Errors})
+	case errors.As(err, &terr):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  err.Error(),
+			"fields": []jsonschema.FieldError{{Field: terr.Field, Message: terr.Err.Error()}},
+		})
+	case errors.Is(err, jsonschema.ErrInvalidSchema):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
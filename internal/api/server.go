@@ -1,20 +1,24 @@
 package api
 
 import (
-	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sort"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/jobs/scheduler/internal/executor"
+	"github.com/jobs/scheduler/internal/manifest"
 	"github.com/jobs/scheduler/internal/models"
 	"github.com/jobs/scheduler/internal/scheduler"
 	"github.com/jobs/scheduler/internal/storage"
+	"github.com/jobs/scheduler/pkg/callbackauth"
+	"github.com/jobs/scheduler/pkg/cronexpr"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -25,8 +29,13 @@ type Server struct {
 	scheduler       *scheduler.Scheduler
 	executorManager *executor.Manager
 	taskRunner      *scheduler.TaskRunner
+	manifestApplier *manifest.Applier
 	logger          *zap.Logger
 	router          *gin.Engine
+
+	// 上次清理过期回调 nonce 的时间
+	nonceMu       sync.Mutex
+	noncePurgedAt time.Time
 }
 
 // NewServer 创建API服务器
@@ -44,6 +53,7 @@ func NewServer(
 		taskRunner:      taskRunner,
 		logger:          logger,
 	}
+	s.manifestApplier = manifest.NewApplier(storage, s.syncTask, logger)
 
 	s.setupRouter()
 	return s
@@ -83,6 +93,7 @@ func (s *Server) setupRouter() {
 			tasks.PUT("/:id", s.updateTask)
 			tasks.DELETE("/:id", s.deleteTask)
 			tasks.POST("/:id/trigger", s.triggerTask)
+			tasks.POST("/:id/backfill", s.createBackfill)
 			tasks.POST("/:id/pause", s.pauseTask)
 			tasks.POST("/:id/resume", s.resumeTask)
 			tasks.GET("/:id/executors", s.getTaskExecutors)
@@ -90,6 +101,10 @@ func (s *Server) setupRouter() {
 			tasks.PUT("/:id/executors/:executor_id", s.updateExecutorAssignment)
 			tasks.DELETE("/:id/executors/:executor_id", s.unassignExecutor)
 			tasks.GET("/:id/stats", s.getTaskStats) // 新增：获取任务统计
+			tasks.GET("/:id/dependencies", s.getTaskDependencies)
+			tasks.POST("/:id/dependencies", s.addTaskDependency)
+			tasks.PUT("/:id/dependencies", s.setTaskDependencies)
+			tasks.DELETE("/:id/dependencies/:upstream_id", s.removeTaskDependency)
 		}
 
 		// 执行器管理
@@ -110,9 +125,36 @@ func (s *Server) setupRouter() {
 			executions.GET("/stats", s.getExecutionStats)
 			executions.GET("/:id", s.getExecution)
 			executions.POST("/:id/callback", s.executionCallback)
+			executions.POST("/:id/progress", s.executionProgress)
+			executions.POST("/:id/logs", s.appendExecutionLog)
+			executions.GET("/:id/logs", s.getExecutionLogs)
 			executions.POST("/:id/stop", s.stopExecution)
+			executions.POST("/:id/rerun", s.rerunExecution)
 		}
 
+		// 工作流运行
+		workflowRuns := api.Group("/workflow-runs")
+		{
+			workflowRuns.GET("", s.listWorkflowRuns)
+			workflowRuns.GET("/:id", s.getWorkflowRun)
+			workflowRuns.POST("/:id/cancel", s.cancelWorkflowRun)
+			workflowRuns.POST("/:id/rerun", s.rerunWorkflowRun)
+		}
+
+		// 历史区间补跑
+		backfills := api.Group("/backfills")
+		{
+			backfills.GET("", s.listBackfills)
+			backfills.GET("/:id", s.getBackfill)
+			backfills.POST("/:id/cancel", s.cancelBackfill)
+		}
+
+		// Cron表达式
+		api.POST("/cron/preview", s.previewCron)
+
+		// 声明式任务清单
+		api.POST("/apply", s.applyManifest)
+
 		// 调度器状态
 		api.GET("/scheduler/status", s.getSchedulerStatus)
 	}
@@ -159,6 +201,11 @@ func (s *Server) listTasks(c *gin.Context) {
 		return
 	}
 
+	now := time.Now()
+	for i := range tasks {
+		setNextRunTime(&tasks[i], now)
+	}
+
 	c.JSON(http.StatusOK, tasks)
 }
 
@@ -176,6 +223,8 @@ func (s *Server) getTask(c *gin.Context) {
 		return
 	}
 
+	setNextRunTime(&task, time.Now())
+
 	c.JSON(http.StatusOK, task)
 }
 
@@ -191,11 +240,16 @@ func (s *Server) createTask(c *gin.Context) {
 		ID:                  generateID(),
 		Name:                req.Name,
 		CronExpression:      req.CronExpression,
+		Timezone:            req.Timezone,
 		Parameters:          req.Parameters,
+		ParametersSchema:    req.ParametersSchema,
 		ExecutionMode:       req.ExecutionMode,
 		LoadBalanceStrategy: req.LoadBalanceStrategy,
 		MaxRetry:            req.MaxRetry,
+		RetryPolicy:         req.RetryPolicy,
 		TimeoutSeconds:      req.TimeoutSeconds,
+		MisfirePolicy:       req.MisfirePolicy,
+		MisfireLimit:        req.MisfireLimit,
 		Status:              models.TaskStatusActive,
 	}
 
@@ -212,12 +266,59 @@ func (s *Server) createTask(c *gin.Context) {
 	if task.TimeoutSeconds == 0 {
 		task.TimeoutSeconds = 300
 	}
+	if task.MisfirePolicy == "" {
+		task.MisfirePolicy = models.MisfirePolicyIgnore
+	}
+	if !validMisfirePolicy(task.MisfirePolicy) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid misfire_policy"})
+		return
+	}
+	if err := task.ValidateMisfire(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if task.RetryPolicy != nil {
+		if err := task.RetryPolicy.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if _, err := cronexpr.Parse(task.CronExpression, task.Timezone); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := task.ValidateParameters(task.Parameters); err != nil {
+		s.respondParameterError(c, err)
+		return
+	}
 
-	if err := s.storage.DB().Create(&task).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	for _, d := range req.Dependencies {
+		if !validFailurePolicy(d.FailurePolicy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid failure_policy"})
+			return
+		}
+	}
+
+	// 任务与依赖在同一事务中创建，依赖不合法（自依赖、重复或上游不存在）时不留下任务
+	err := s.storage.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&task).Error; err != nil {
+			return err
+		}
+		if len(req.Dependencies) == 0 {
+			return nil
+		}
+		_, err := s.scheduler.DAG().ReplaceDependencies(tx, task.ID, toDependencies(req.Dependencies))
+		return err
+	})
+	if err != nil {
+		s.respondDependencyError(c, err)
 		return
 	}
 
+	if err := s.scheduler.SyncTask(task.ID); err != nil {
+		s.logger.Error("failed to sync task after create", zap.String("task_id", task.ID), zap.Error(err))
+	}
+
 	c.JSON(http.StatusCreated, task)
 }
 
@@ -237,37 +338,127 @@ func (s *Server) updateTask(c *gin.Context) {
 		return
 	}
 
-	// 更新字段
+	// 只写入请求中修改的列，避免覆盖并发触发刚推进的 last_scheduled_time
+	updates := map[string]interface{}{}
 	if req.Name != "" {
 		task.Name = req.Name
+		updates["name"] = task.Name
 	}
 	if req.CronExpression != "" {
 		task.CronExpression = req.CronExpression
+		updates["cron_expression"] = task.CronExpression
+	}
+	if req.Timezone != nil {
+		task.Timezone = *req.Timezone
+		updates["timezone"] = task.Timezone
+	}
+	if req.CronExpression != "" || req.Timezone != nil {
+		if _, err := cronexpr.Parse(task.CronExpression, task.Timezone); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 	}
 	if req.Parameters != nil {
 		task.Parameters = req.Parameters
+		updates["parameters"] = task.Parameters
+	}
+	if req.ParametersSchema != nil {
+		task.ParametersSchema = req.ParametersSchema
+		updates["parameters_schema"] = task.ParametersSchema
+	}
+	if req.Parameters != nil || req.ParametersSchema != nil {
+		if err := task.ValidateParameters(task.Parameters); err != nil {
+			s.respondParameterError(c, err)
+			return
+		}
 	}
 	if req.ExecutionMode != "" {
 		task.ExecutionMode = req.ExecutionMode
+		updates["execution_mode"] = task.ExecutionMode
 	}
 	if req.LoadBalanceStrategy != "" {
 		task.LoadBalanceStrategy = req.LoadBalanceStrategy
+		updates["load_balance_strategy"] = task.LoadBalanceStrategy
 	}
 	if req.MaxRetry > 0 {
 		task.MaxRetry = req.MaxRetry
+		updates["max_retry"] = task.MaxRetry
+	}
+	if req.RetryPolicy != nil {
+		if err := req.RetryPolicy.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		task.RetryPolicy = req.RetryPolicy
+		updates["retry_policy"] = task.RetryPolicy
 	}
 	if req.TimeoutSeconds > 0 {
 		task.TimeoutSeconds = req.TimeoutSeconds
+		updates["timeout_seconds"] = task.TimeoutSeconds
 	}
 	if req.Status != "" {
+		// 恢复调度时从现在开始计算错过的触发，暂停期间的触发不补偿
+		if req.Status == models.TaskStatusActive && task.Status != models.TaskStatusActive {
+			updates["last_scheduled_time"] = time.Now()
+		}
 		task.Status = req.Status
+		updates["status"] = task.Status
+	}
+	if req.MisfirePolicy != "" {
+		if !validMisfirePolicy(req.MisfirePolicy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid misfire_policy"})
+			return
+		}
+		task.MisfirePolicy = req.MisfirePolicy
+		updates["misfire_policy"] = task.MisfirePolicy
+	}
+	if req.MisfireLimit != nil {
+		if *req.MisfireLimit < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "misfire_limit must not be negative"})
+			return
+		}
+		task.MisfireLimit = *req.MisfireLimit
+		updates["misfire_limit"] = task.MisfireLimit
+	}
+	// 按修改后的执行模式和补偿策略整体校验
+	if err := task.ValidateMisfire(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	if err := s.storage.DB().Save(&task).Error; err != nil {
+	for _, d := range req.Dependencies {
+		if !validFailurePolicy(d.FailurePolicy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid failure_policy"})
+			return
+		}
+	}
+
+	// 任务字段与依赖在同一事务中修改，依赖不合法时两者都不生效
+	err := s.storage.DB().Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(&models.Task{}).Where("id = ?", task.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if req.Dependencies == nil {
+			return nil
+		}
+		_, err := s.scheduler.DAG().ReplaceDependencies(tx, task.ID, toDependencies(req.Dependencies))
+		return err
+	})
+	if err != nil {
+		s.respondDependencyError(c, err)
+		return
+	}
+	if err := s.storage.DB().Where("id = ?", task.ID).First(&task).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	if err := s.scheduler.SyncTask(task.ID); err != nil {
+		s.logger.Error("failed to sync task after update", zap.String("task_id", task.ID), zap.Error(err))
+	}
+
 	c.JSON(http.StatusOK, task)
 }
 
@@ -291,6 +482,10 @@ func (s *Server) deleteTask(c *gin.Context) {
 		return
 	}
 
+	if err := s.scheduler.SyncTask(taskID); err != nil {
+		s.logger.Error("failed to sync task after delete", zap.String("task_id", taskID), zap.Error(err))
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "task deleted"})
 }
 
@@ -306,7 +501,7 @@ func (s *Server) triggerTask(c *gin.Context) {
 
 	execution, err := s.scheduler.TriggerTask(c.Request.Context(), taskID, req.Parameters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		s.respondParameterError(c, err)
 		return
 	}
 
@@ -480,20 +675,48 @@ func (s *Server) getExecutor(c *gin.Context) {
 }
 
 // registerExecutor 注册执行器
+// 重新注册已有执行器时需用其当前回调密钥签名请求，或携带注册令牌
 func (s *Server) registerExecutor(c *gin.Context) {
+	// 签名针对原始请求体计算，先读取再解析
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	var req executor.RegisterRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := binding.JSON.BindBody(body, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	executor, err := s.executorManager.RegisterExecutor(c.Request.Context(), req)
+	proof := executor.RegisterProof{
+		Token: c.GetHeader(callbackauth.HeaderRegistrationToken),
+		Verify: func(executorID, secret string) bool {
+			if c.GetHeader(callbackauth.HeaderSignature) == "" {
+				return false
+			}
+			_, err := s.verifySignature(c, executorID, secret, body)
+			return err == nil
+		},
+	}
+
+	registered, err := s.executorManager.RegisterExecutor(c.Request.Context(), req, proof)
 	if err != nil {
+		if errors.Is(err, executor.ErrRegistrationUnauthorized) {
+			s.logger.Warn("executor registration rejected",
+				zap.String("instance_id", req.ExecutorID),
+				zap.String("client_ip", c.ClientIP()))
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, executor)
+	c.JSON(http.StatusOK, executor.RegisterResponse{
+		Executor:       registered,
+		CallbackSecret: registered.CallbackSecret,
+	})
 }
 
 // updateExecutor 更新执行器信息
@@ -610,6 +833,18 @@ func (s *Server) listExecutions(c *gin.Context) {
 		countQuery = countQuery.Where("status = ?", status)
 	}
 
+	// 支持工作流运行过滤
+	if runID := c.Query("workflow_run_id"); runID != "" {
+		query = query.Where("workflow_run_id = ?", runID)
+		countQuery = countQuery.Where("workflow_run_id = ?", runID)
+	}
+
+	// 支持补跑过滤
+	if backfillID := c.Query("backfill_id"); backfillID != "" {
+		query = query.Where("backfill_id = ?", backfillID)
+		countQuery = countQuery.Where("backfill_id = ?", backfillID)
+	}
+
 	// 支持时间范围过滤
 	if start := c.Query("start_time"); start != "" {
 		query = query.Where("scheduled_time >= ?", start)
@@ -740,13 +975,20 @@ func (s *Server) executionCallback(c *gin.Context) {
 	executionID := c.Param("id")
 
 	var req executor.ExecutionCallbackRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !s.bindSignedRequest(c, &req, func() string { return req.ExecutionID }) {
 		return
 	}
 
 	err := s.taskRunner.HandleCallback(c.Request.Context(), executionID, req)
-	if err != nil {
+	switch {
+	case errors.Is(err, scheduler.ErrIllegalTransition), errors.Is(err, scheduler.ErrExecutionConflict):
+		s.logger.Warn("execution callback conflicts with current status",
+			zap.String("execution_id", executionID),
+			zap.String("status", string(req.Status)),
+			zap.Error(err))
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -754,71 +996,84 @@ func (s *Server) executionCallback(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "callback processed"})
 }
 
-// stopExecution 停止正在执行的任务
-func (s *Server) stopExecution(c *gin.Context) {
+// executionProgress 执行进度上报，同时作为心跳顺延超时
+func (s *Server) executionProgress(c *gin.Context) {
 	executionID := c.Param("id")
 
-	// 查找执行记录
-	var execution models.TaskExecution
-	if err := s.storage.DB().
-		Preload("Executor").
-		Where("id = ?", executionID).
-		First(&execution).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+	var req executor.ExecutionProgressRequest
+	if !s.bindSignedRequest(c, &req, func() string { return req.ExecutionID }) {
 		return
 	}
 
-	// 检查执行状态
-	if execution.Status != models.ExecutionStatusRunning {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "execution is not running"})
+	err := s.taskRunner.ReportProgress(c.Request.Context(), executionID, req)
+	switch {
+	case errors.Is(err, scheduler.ErrExecutionNotRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// 调用执行器的停止接口
-	if execution.Executor != nil {
-		stopURL := fmt.Sprintf("%s/stop", execution.Executor.BaseURL)
-		stopReq := map[string]string{
-			"execution_id": executionID,
-		}
+	c.JSON(http.StatusOK, gin.H{"message": "progress recorded"})
+}
 
-		jsonData, err := json.Marshal(stopReq)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to marshal stop request"})
-			return
-		}
+// stopExecution 停止执行：运行中的执行只有执行器确认停止后才标记为已取消
+func (s *Server) stopExecution(c *gin.Context) {
+	executionID := c.Param("id")
+
+	execution, err := s.taskRunner.CancelExecution(c.Request.Context(), executionID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "execution not found"})
+		return
+	case errors.Is(err, scheduler.ErrIllegalTransition), errors.Is(err, scheduler.ErrExecutionConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, scheduler.ErrStopRefused):
+		s.logger.Error("executor refused to stop execution",
+			zap.String("execution_id", executionID),
+			zap.Error(err))
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "execution cancelled",
+		"execution_id": executionID,
+		"status":       execution.Status,
+	})
+}
 
-		resp, err := http.Post(stopURL, "application/json", bytes.NewBuffer(jsonData))
-		if err != nil {
-			s.logger.Error("failed to call executor stop endpoint",
-				zap.String("execution_id", executionID),
-				zap.String("executor_id", *execution.ExecutorID),
-				zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to stop execution on executor"})
+// rerunExecution 重跑已结束的执行，请求体可选，提供 parameters 时以其替换原执行的参数
+func (s *Server) rerunExecution(c *gin.Context) {
+	executionID := c.Param("id")
+
+	var req executor.RerunExecutionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			var errorResp map[string]interface{}
-			json.NewDecoder(resp.Body).Decode(&errorResp)
-			s.logger.Error("executor stop endpoint returned error",
-				zap.String("execution_id", executionID),
-				zap.Int("status_code", resp.StatusCode),
-				zap.Any("error", errorResp))
-		}
 	}
 
-	// 更新执行状态为取消中
-	execution.Status = models.ExecutionStatusCancelled
-	if err := s.storage.DB().Save(&execution).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update execution status"})
+	execution, err := s.scheduler.RerunExecution(c.Request.Context(), executionID, req.Parameters)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, scheduler.ErrExecutionNotFinished):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		s.respondParameterError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":      "stop request sent to executor",
-		"execution_id": executionID,
-	})
+	c.JSON(http.StatusOK, execution)
 }
 
 // pauseTask 暂停任务调度
@@ -852,9 +1107,9 @@ func (s *Server) pauseTask(c *gin.Context) {
 		return
 	}
 
-	// 重新加载调度器任务
-	if err := s.scheduler.ReloadTasks(); err != nil {
-		s.logger.Error("failed to reload tasks after pause", zap.Error(err))
+	// 同步该任务的调度条目
+	if err := s.scheduler.SyncTask(taskID); err != nil {
+		s.logger.Error("failed to sync task after pause", zap.String("task_id", taskID), zap.Error(err))
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -885,18 +1140,21 @@ func (s *Server) resumeTask(c *gin.Context) {
 		return
 	}
 
-	// 更新任务状态为活跃
+	// 更新任务状态为活跃，并从现在开始计算错过的触发，暂停期间的触发不补偿
 	if err := s.storage.DB().
-		Model(&task).
+		Model(&models.Task{}).
 		Where("id = ?", taskID).
-		Update("status", models.TaskStatusActive).Error; err != nil {
+		Updates(map[string]interface{}{
+			"status":              models.TaskStatusActive,
+			"last_scheduled_time": time.Now(),
+		}).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// 重新加载调度器任务
-	if err := s.scheduler.ReloadTasks(); err != nil {
-		s.logger.Error("failed to reload tasks after resume", zap.Error(err))
+	// 同步该任务的调度条目
+	if err := s.scheduler.SyncTask(taskID); err != nil {
+		s.logger.Error("failed to sync task after resume", zap.String("task_id", taskID), zap.Error(err))
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -913,8 +1171,15 @@ func (s *Server) getSchedulerStatus(c *gin.Context) {
 		return
 	}
 
+	var leases []models.SchedulerLease
+	if err := s.storage.DB().Find(&leases).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"instances": instances,
+		"leases":    leases,
 		"time":      time.Now(),
 	})
 }
@@ -1098,7 +1363,7 @@ func (s *Server) calculateHealthStats(taskID string, days int) map[string]interf
 	s.storage.DB().Model(&models.TaskExecution{}).
 		Where("task_id = ? AND created_at >= ? AND start_time IS NOT NULL AND end_time IS NOT NULL",
 			taskID, since).
-		Select("AVG(TIMESTAMPDIFF(SECOND, start_time, end_time))").
+		Select(fmt.Sprintf("AVG(%s)", s.storage.Dialect().SecondsBetween("start_time", "end_time"))).
 		Scan(&avgDuration)
 
 	return map[string]interface{}{
@@ -1,31 +1,57 @@
 package api
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/pkg/cronexpr"
 )
 
 // CreateTaskRequest 创建任务请求
 type CreateTaskRequest struct {
 	Name                string                     `json:"name" binding:"required"`
 	CronExpression      string                     `json:"cron_expression" binding:"required"`
+	Timezone            string                     `json:"timezone"` // IANA时区名称，为空表示服务器本地时区
 	Parameters          models.JSONMap             `json:"parameters"`
+	ParametersSchema    models.JSONMap             `json:"parameters_schema"` // 参数的 JSON Schema，默认参数必须满足
 	ExecutionMode       models.ExecutionMode       `json:"execution_mode"`
 	LoadBalanceStrategy models.LoadBalanceStrategy `json:"load_balance_strategy"`
 	MaxRetry            int                        `json:"max_retry"`
+	RetryPolicy         *models.RetryPolicy        `json:"retry_policy"` // 为空时使用默认策略
 	TimeoutSeconds      int                        `json:"timeout_seconds"`
+	MisfirePolicy       models.MisfirePolicy       `json:"misfire_policy"`
+	MisfireLimit        int                        `json:"misfire_limit"`
+	Dependencies        []DependencyRequest        `json:"dependencies"`
 }
 
 // UpdateTaskRequest 更新任务请求
 type UpdateTaskRequest struct {
 	Name                string                     `json:"name"`
 	CronExpression      string                     `json:"cron_expression"`
+	Timezone            *string                    `json:"timezone"` // nil表示不修改，空字符串表示服务器本地时区
 	Parameters          models.JSONMap             `json:"parameters"`
+	ParametersSchema    models.JSONMap             `json:"parameters_schema"` // nil表示不修改，空对象表示取消校验
 	ExecutionMode       models.ExecutionMode       `json:"execution_mode"`
 	LoadBalanceStrategy models.LoadBalanceStrategy `json:"load_balance_strategy"`
 	MaxRetry            int                        `json:"max_retry"`
+	RetryPolicy         *models.RetryPolicy        `json:"retry_policy"` // nil表示不修改，空对象表示恢复默认策略
 	TimeoutSeconds      int                        `json:"timeout_seconds"`
 	Status              models.TaskStatus          `json:"status"`
+	MisfirePolicy       models.MisfirePolicy       `json:"misfire_policy"`
+	MisfireLimit        *int                       `json:"misfire_limit"` // nil表示不修改，0表示使用默认上限
+	Dependencies        []DependencyRequest        `json:"dependencies"`  // nil表示不修改，空数组表示清空
+}
+
+// DependencyRequest 任务依赖请求
+type DependencyRequest struct {
+	DependsOnTaskID string                         `json:"depends_on_task_id" binding:"required"`
+	FailurePolicy   models.DependencyFailurePolicy `json:"failure_policy"`
+}
+
+// SetDependenciesRequest 替换任务依赖请求
+type SetDependenciesRequest struct {
+	Dependencies []DependencyRequest `json:"dependencies"`
 }
 
 // AssignExecutorRequest 分配执行器请求
@@ -41,6 +67,69 @@ type UpdateExecutorAssignmentRequest struct {
 	Weight   int `json:"weight"`
 }
 
+// RerunWorkflowRequest 从失败节点重跑请求
+type RerunWorkflowRequest struct {
+	TaskIDs []string `json:"task_ids"` // 为空时重跑所有失败节点
+}
+
+// BackfillRequest 补跑请求，区间为闭区间
+type BackfillRequest struct {
+	StartTime      time.Time              `json:"start_time" binding:"required"`
+	EndTime        time.Time              `json:"end_time" binding:"required"`
+	MaxConcurrency int                    `json:"max_concurrency"` // 同时未结束的执行数上限，默认1
+	Parameters     map[string]interface{} `json:"parameters"`      // 覆盖任务默认参数
+}
+
+// CronPreviewRequest 预览cron触发时间请求
+type CronPreviewRequest struct {
+	Expression string     `json:"expression" binding:"required"`
+	Timezone   string     `json:"timezone"` // 为空表示服务器本地时区
+	Count      int        `json:"count"`    // 默认5，最多100
+	From       *time.Time `json:"from"`     // 为空表示当前时间
+}
+
+// toDependencies 转换为依赖模型
+func toDependencies(reqs []DependencyRequest) []models.TaskDependency {
+	deps := make([]models.TaskDependency, 0, len(reqs))
+	for _, r := range reqs {
+		deps = append(deps, models.TaskDependency{
+			DependsOnTaskID: r.DependsOnTaskID,
+			FailurePolicy:   r.FailurePolicy,
+		})
+	}
+	return deps
+}
+
+// validFailurePolicy 校验依赖失败策略
+func validFailurePolicy(policy models.DependencyFailurePolicy) bool {
+	switch policy {
+	case "", models.DependencyPolicySkip, models.DependencyPolicyFail, models.DependencyPolicyRun:
+		return true
+	}
+	return false
+}
+
+// validMisfirePolicy 校验错过触发的补偿策略
+func validMisfirePolicy(policy models.MisfirePolicy) bool {
+	switch policy {
+	case "", models.MisfirePolicyIgnore, models.MisfirePolicyFireOnce, models.MisfirePolicyFireAll:
+		return true
+	}
+	return false
+}
+
+// setNextRunTime 计算活跃任务在其时区下的下次触发时间
+func setNextRunTime(task *models.Task, from time.Time) {
+	if task.Status != models.TaskStatusActive {
+		return
+	}
+	times, err := cronexpr.NextTimes(task.CronExpression, task.Timezone, from, 1)
+	if err != nil || len(times) == 0 {
+		return
+	}
+	task.NextRunTime = &times[0]
+}
+
 // generateID 生成UUID
 func generateID() string {
 	return uuid.New().String()
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/internal/scheduler"
+	"gorm.io/gorm"
+)
+
+// listWorkflowRuns 获取工作流运行列表
+func (s *Server) listWorkflowRuns(c *gin.Context) {
+	type PaginatedResponse struct {
+		Data       []models.WorkflowRun `json:"data"`
+		Total      int64                `json:"total"`
+		Page       int                  `json:"page"`
+		PageSize   int                  `json:"page_size"`
+		TotalPages int                  `json:"total_pages"`
+	}
+
+	query := s.storage.DB().Model(&models.WorkflowRun{})
+
+	if taskID := c.Query("root_task_id"); taskID != "" {
+		query = query.Where("root_task_id = ?", taskID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	page := 1
+	if p := c.Query("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	pageSize := 20
+	if ps := c.Query("page_size"); ps != "" {
+		if parsed, err := strconv.Atoi(ps); err == nil && parsed > 0 && parsed <= 100 {
+			pageSize = parsed
+		}
+	}
+
+	var runs []models.WorkflowRun
+	if err := query.Preload("RootTask").
+		Order("start_time DESC").
+		Limit(pageSize).
+		Offset((page - 1) * pageSize).
+		Find(&runs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	totalPages := int(total) / pageSize
+	if int(total)%pageSize > 0 {
+		totalPages++
+	}
+
+	c.JSON(http.StatusOK, PaginatedResponse{
+		Data:       runs,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	})
+}
+
+// getWorkflowRun 获取工作流运行详情（包含所有执行）
+func (s *Server) getWorkflowRun(c *gin.Context) {
+	runID := c.Param("id")
+
+	var run models.WorkflowRun
+	if err := s.storage.DB().
+		Preload("RootTask").
+		Preload("Executions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Preload("Executions.Task").
+		Preload("Executions.Executor").
+		Where("id = ?", runID).
+		First(&run).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "workflow run not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// cancelWorkflowRun 取消工作流运行
+func (s *Server) cancelWorkflowRun(c *gin.Context) {
+	runID := c.Param("id")
+
+	run, err := s.scheduler.DAG().CancelRun(c.Request.Context(), runID)
+	if err != nil {
+		s.respondWorkflowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// rerunWorkflowRun 从失败节点重跑工作流运行
+func (s *Server) rerunWorkflowRun(c *gin.Context) {
+	runID := c.Param("id")
+
+	var req RerunWorkflowRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	executions, err := s.scheduler.DAG().RerunFromFailed(c.Request.Context(), runID, req.TaskIDs)
+	if err != nil {
+		s.respondWorkflowError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"workflow_run_id": runID,
+		"executions":      executions,
+	})
+}
+
+// respondWorkflowError 运行不存在返回404，状态不允许返回409
+func (s *Server) respondWorkflowError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "workflow run not found"})
+	case errors.Is(err, scheduler.ErrWorkflowRunState):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
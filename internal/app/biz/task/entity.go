@@ -15,6 +15,7 @@ type Task struct {
 	id                  types.ID
 	name                string
 	cronExpression      CronExpression
+	timezone            string // IANA时区名称，为空表示服务器本地时区
 	parameters          types.JSONMap
 	executionMode       ExecutionMode
 	loadBalanceStrategy LoadBalanceStrategy
@@ -85,6 +86,11 @@ func (t *Task) CronExpression() CronExpression {
 	return t.cronExpression
 }
 
+// Timezone 获取任务时区
+func (t *Task) Timezone() string {
+	return t.timezone
+}
+
 // Parameters 获取参数
 func (t *Task) Parameters() types.JSONMap {
 	return t.parameters
@@ -177,7 +183,7 @@ func (t *Task) GetNextExecutionTime(from time.Time) (time.Time, error) {
 	if !t.CanBeScheduled() {
 		return time.Time{}, fmt.Errorf("task cannot be scheduled in current status: %s", t.status)
 	}
-	return t.cronExpression.NextTime(from)
+	return t.cronExpression.NextTimeIn(from, t.timezone)
 }
 
 // 状态变更方法
@@ -304,6 +310,32 @@ func (t *Task) UpdateCronExpression(cronExpr string) error {
 	return nil
 }
 
+// UpdateTimezone 更新任务时区
+func (t *Task) UpdateTimezone(timezone string) error {
+	if _, err := ParseTimezone(timezone); err != nil {
+		return err
+	}
+
+	if timezone == t.timezone {
+		return nil
+	}
+
+	oldTimezone := t.timezone
+	t.timezone = timezone
+	t.updatedAt = time.Now()
+
+	// 添加领域事件
+	t.addDomainEvent(TaskUpdatedEvent{
+		TaskID:    t.id,
+		Field:     "timezone",
+		OldValue:  oldTimezone,
+		NewValue:  timezone,
+		UpdatedAt: t.updatedAt,
+	})
+
+	return nil
+}
+
 // UpdateParameters 更新参数
 func (t *Task) UpdateParameters(parameters types.JSONMap) error {
 	if parameters == nil {
@@ -420,6 +452,13 @@ func (t *Task) UpdateAll(req UpdateTaskRequest) error {
 		}
 	}
 
+	// 更新时区
+	if req.Timezone != nil {
+		if err := t.UpdateTimezone(*req.Timezone); err != nil {
+			return err
+		}
+	}
+
 	// 更新参数
 	if req.Parameters != nil {
 		if err := t.UpdateParameters(req.Parameters); err != nil {
@@ -5,7 +5,7 @@ import (
 	"time"
 
 	"github.com/jobs/scheduler/internal/app/types"
-	"github.com/robfig/cron/v3"
+	"github.com/jobs/scheduler/pkg/cronexpr"
 )
 
 // TaskStatus 任务状态（领域特有）
@@ -44,15 +44,15 @@ func (s TaskStatus) CanBeScheduled() bool {
 type CronExpression string
 
 // NewCronExpression 创建cron表达式
+// 与调度器使用同一方言（pkg/cronexpr）：秒字段可选，支持描述符和 Quartz 的 L/W/#
 func NewCronExpression(expr string) (CronExpression, error) {
 	if expr == "" {
 		return "", fmt.Errorf("cron expression cannot be empty")
 	}
 
 	// 验证cron表达式格式
-	_, err := cron.ParseStandard(expr)
-	if err != nil {
-		return "", fmt.Errorf("invalid cron expression: %w", err)
+	if err := cronexpr.Validate(expr, ""); err != nil {
+		return "", err
 	}
 
 	return CronExpression(expr), nil
@@ -68,15 +68,25 @@ func (c CronExpression) IsEmpty() bool {
 	return string(c) == ""
 }
 
-// NextTime 计算下次执行时间
+// NextTime 计算下次执行时间（服务器本地时区）
 func (c CronExpression) NextTime(from time.Time) (time.Time, error) {
-	schedule, err := cron.ParseStandard(string(c))
+	return c.NextTimeIn(from, "")
+}
+
+// NextTimeIn 按指定时区的挂钟时间计算下次执行时间，timezone 为空时使用服务器本地时区
+func (c CronExpression) NextTimeIn(from time.Time, timezone string) (time.Time, error) {
+	schedule, err := cronexpr.Parse(string(c), timezone)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("invalid cron expression: %w", err)
+		return time.Time{}, err
 	}
 	return schedule.Next(from), nil
 }
 
+// ParseTimezone 校验并加载时区（IANA名称，如 Asia/Shanghai），为空时返回服务器本地时区
+func ParseTimezone(timezone string) (*time.Location, error) {
+	return cronexpr.LoadLocation(timezone)
+}
+
 // ExecutionMode 执行模式（领域特有）
 type ExecutionMode string
 
@@ -195,6 +205,7 @@ type CreateTaskRequest struct {
 	LoadBalanceStrategy LoadBalanceStrategy `json:"load_balance_strategy"`
 	MaxRetry            int                 `json:"max_retry"`
 	TimeoutSeconds      int                 `json:"timeout_seconds"`
+	Timezone            string              `json:"timezone"`
 }
 
 // Validate 验证请求参数
@@ -207,6 +218,10 @@ func (r CreateTaskRequest) Validate() error {
 		return fmt.Errorf("invalid cron expression: %w", err)
 	}
 
+	if _, err := ParseTimezone(r.Timezone); err != nil {
+		return err
+	}
+
 	if r.ExecutionMode != "" && !r.ExecutionMode.IsValid() {
 		return fmt.Errorf("invalid execution mode: %s", r.ExecutionMode)
 	}
@@ -227,6 +242,7 @@ type UpdateTaskRequest struct {
 	LoadBalanceStrategy LoadBalanceStrategy `json:"load_balance_strategy"`
 	MaxRetry            int                 `json:"max_retry"`
 	TimeoutSeconds      int                 `json:"timeout_seconds"`
+	Timezone            *string             `json:"timezone"` // nil表示不修改
 	Status              TaskStatus          `json:"status"`
 }
 
@@ -238,6 +254,12 @@ func (r UpdateTaskRequest) Validate() error {
 		}
 	}
 
+	if r.Timezone != nil {
+		if _, err := ParseTimezone(*r.Timezone); err != nil {
+			return err
+		}
+	}
+
 	if r.ExecutionMode != "" && !r.ExecutionMode.IsValid() {
 		return fmt.Errorf("invalid execution mode: %s", r.ExecutionMode)
 	}
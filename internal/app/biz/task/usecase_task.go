@@ -44,6 +44,7 @@ type CreateTaskRequest struct {
 	LoadBalanceStrategy LoadBalanceStrategy `json:"load_balance_strategy,omitempty"`
 	MaxRetry            int                 `json:"max_retry,omitempty"`
 	TimeoutSeconds      int                 `json:"timeout_seconds,omitempty"`
+	Timezone            string              `json:"timezone,omitempty"`
 }
 
 // Validate 验证请求
@@ -113,6 +114,12 @@ func (uc *UseCase) CreateTask(ctx context.Context, req *CreateTaskRequest) (*Cre
 		}
 
 		// 设置可选参数
+		if req.Timezone != "" {
+			if err := task.UpdateTimezone(req.Timezone); err != nil {
+				return fmt.Errorf("failed to set timezone: %w", err)
+			}
+		}
+
 		if req.Parameters != nil {
 			if err := task.UpdateParameters(req.Parameters); err != nil {
 				return fmt.Errorf("failed to set parameters: %w", err)
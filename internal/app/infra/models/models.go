@@ -16,6 +16,7 @@ type TaskModel struct {
 	ID                  string        `gorm:"primaryKey;type:varchar(64)" json:"id"`
 	Name                string        `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
 	CronExpression      string        `gorm:"type:varchar(100);not null" json:"cron_expression"`
+	Timezone            string        `gorm:"type:varchar(64)" json:"timezone"`
 	Parameters          types.JSONMap `gorm:"type:json" json:"parameters"`
 	ExecutionMode       string        `gorm:"type:varchar(20);not null;default:'parallel'" json:"execution_mode"`
 	LoadBalanceStrategy string        `gorm:"type:varchar(20);not null;default:'round_robin'" json:"load_balance_strategy"`
@@ -54,6 +55,7 @@ func (m *TaskModel) FromEntity(task *taskbiz.Task) {
 	m.ID = string(task.ID())
 	m.Name = task.Name()
 	m.CronExpression = task.CronExpression().String()
+	m.Timezone = task.Timezone()
 	m.Parameters = task.Parameters()
 	m.ExecutionMode = string(task.ExecutionMode())
 	m.LoadBalanceStrategy = string(task.LoadBalanceStrategy())
@@ -196,6 +196,11 @@ func (h *HealthChecker) checkExecutor(executor *models.Executor) {
 	}
 }
 
+// Probe 立即探测执行器是否存活，不更新执行器的健康状态
+func (h *HealthChecker) Probe(ctx context.Context, executor *models.Executor) bool {
+	return h.ping(ctx, executor)
+}
+
 func (h *HealthChecker) ping(ctx context.Context, executor *models.Executor) bool {
 	if executor.HealthCheckURL == "" {
 		// 如果没有健康检查URL，使用基础URL
@@ -2,21 +2,32 @@ package executor
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
+	"reflect"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jobs/scheduler/internal/models"
 	"github.com/jobs/scheduler/internal/storage"
+	"github.com/jobs/scheduler/pkg/callbackauth"
+	"github.com/jobs/scheduler/pkg/jsonschema"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// ErrRegistrationUnauthorized 重新注册已持有凭证的执行器时未能证明持有该凭证
+var ErrRegistrationUnauthorized = errors.New("executor registration is not authorized")
+
 type Manager struct {
 	storage *storage.Storage
 	logger  *zap.Logger
 	mu      sync.RWMutex
+
+	// 重新注册已有执行器时可出示的令牌，为空时只接受当前回调密钥签名的请求
+	registrationToken string
 }
 
 func NewManager(storage *storage.Storage, logger *zap.Logger) *Manager {
@@ -26,14 +37,30 @@ func NewManager(storage *storage.Storage, logger *zap.Logger) *Manager {
 	}
 }
 
+// SetRegistrationToken 设置重新注册使用的令牌
+func (m *Manager) SetRegistrationToken(token string) {
+	m.registrationToken = token
+}
+
 // RegisterExecutor 注册执行器和相关任务
-func (m *Manager) RegisterExecutor(ctx context.Context, req RegisterRequest) (*models.Executor, error) {
+// 已持有回调密钥的 instance_id 重新注册时必须出示凭证，否则返回 ErrRegistrationUnauthorized，密钥不会轮换
+func (m *Manager) RegisterExecutor(ctx context.Context, req RegisterRequest, proof RegisterProof) (*models.Executor, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	// 检查是否存在使用相同 instance_id 的执行器
 	var executor models.Executor
 	err := m.storage.DB().Where("instance_id = ?", req.ExecutorID).First(&executor).Error
+	if err == nil && !m.authorized(&executor, proof) {
+		return nil, fmt.Errorf("%w: instance_id %s is already registered, sign the request with its callback secret or present the registration token",
+			ErrRegistrationUnauthorized, req.ExecutorID)
+	}
+
+	// 每次注册签发新的回调密钥，旧密钥随之失效
+	secret, secretErr := callbackauth.GenerateSecret()
+	if secretErr != nil {
+		return nil, secretErr
+	}
 
 	if err == nil {
 		// 如果执行器已存在且在线，拒绝注册（防止挤掉别人）
@@ -58,6 +85,7 @@ func (m *Manager) RegisterExecutor(ctx context.Context, req RegisterRequest) (*m
 		executor.HealthCheckFailures = 0
 		var now = time.Now()
 		executor.LastHealthCheck = &now
+		executor.CallbackSecret = secret
 
 		if err := m.storage.DB().Save(&executor).Error; err != nil {
 			return nil, fmt.Errorf("failed to update executor: %w", err)
@@ -76,6 +104,7 @@ func (m *Manager) RegisterExecutor(ctx context.Context, req RegisterRequest) (*m
 			HealthCheckFailures: 0,
 			LastHealthCheck:     &now,
 			Metadata:            req.Metadata,
+			CallbackSecret:      secret,
 		}
 
 		if executor.HealthCheckURL == "" {
@@ -111,6 +140,19 @@ func (m *Manager) RegisterExecutor(ctx context.Context, req RegisterRequest) (*m
 	return &executor, nil
 }
 
+// authorized 判断重新注册是否出示了有效凭证
+// 尚未签发过回调密钥的执行器（如旧版本注册的记录）没有可校验的凭证，按首次注册处理
+func (m *Manager) authorized(executor *models.Executor, proof RegisterProof) bool {
+	if executor.CallbackSecret == "" {
+		return true
+	}
+	if m.registrationToken != "" && proof.Token != "" &&
+		subtle.ConstantTimeCompare([]byte(m.registrationToken), []byte(proof.Token)) == 1 {
+		return true
+	}
+	return proof.Verify != nil && proof.Verify(executor.ID, executor.CallbackSecret)
+}
+
 // registerTask 注册单个任务
 func (m *Manager) registerTask(ctx context.Context, executorID string, taskDef TaskDefinition) error {
 	// 查找任务（按名称）
@@ -124,6 +166,7 @@ func (m *Manager) registerTask(ctx context.Context, executorID string, taskDef T
 			Name:                taskDef.Name,
 			CronExpression:      taskDef.CronExpression,
 			Parameters:          taskDef.Parameters,
+			ParametersSchema:    taskDef.ParametersSchema,
 			ExecutionMode:       taskDef.ExecutionMode,
 			LoadBalanceStrategy: taskDef.LoadBalanceStrategy,
 			MaxRetry:            taskDef.MaxRetry,
@@ -144,6 +187,9 @@ func (m *Manager) registerTask(ctx context.Context, executorID string, taskDef T
 		if task.Parameters == nil {
 			task.Parameters = make(map[string]interface{})
 		}
+		if err := task.ValidateParameters(task.Parameters); err != nil {
+			return fmt.Errorf("invalid task parameters: %w", err)
+		}
 
 		if err := m.storage.DB().Create(&task).Error; err != nil {
 			return fmt.Errorf("failed to create task: %w", err)
@@ -155,8 +201,10 @@ func (m *Manager) registerTask(ctx context.Context, executorID string, taskDef T
 			zap.String("status", string(task.Status)))
 	} else if err != nil {
 		return fmt.Errorf("failed to query task: %w", err)
+	} else if err := m.updateParametersSchema(&task, taskDef.ParametersSchema); err != nil {
+		return err
 	}
-	// 如果任务存在，不修改任务信息（按需求）
+	// 如果任务存在，除参数 schema 外不修改任务信息（按需求）
 
 	// 检查任务执行器关联是否已存在
 	var taskExecutor models.TaskExecutor
@@ -187,6 +235,33 @@ func (m *Manager) registerTask(ctx context.Context, executorID string, taskDef T
 	return nil
 }
 
+// updateParametersSchema 已存在的任务以执行器最新声明的参数 schema 为准，未声明时保留原 schema
+func (m *Manager) updateParametersSchema(task *models.Task, schema map[string]interface{}) error {
+	if schema == nil || reflect.DeepEqual(map[string]interface{}(task.ParametersSchema), schema) {
+		return nil
+	}
+	if _, err := jsonschema.Compile(schema); err != nil {
+		return err
+	}
+
+	task.ParametersSchema = schema
+	if err := m.storage.DB().Model(&models.Task{}).
+		Where("id = ?", task.ID).
+		Update("parameters_schema", task.ParametersSchema).Error; err != nil {
+		return fmt.Errorf("failed to update parameters schema: %w", err)
+	}
+
+	m.logger.Info("task parameters schema updated",
+		zap.String("task_id", task.ID),
+		zap.String("task_name", task.Name))
+	if err := task.ValidateParameters(task.Parameters); err != nil {
+		m.logger.Warn("task default parameters do not match the new schema",
+			zap.String("task_id", task.ID),
+			zap.Error(err))
+	}
+	return nil
+}
+
 /* TODO: 重新实现任务执行器注册
 // registerTaskExecutor 注册任务与执行器的关联
 func (m *Manager) registerTaskExecutor(ctx context.Context, executorID string, taskReg TaskRegistration) error {
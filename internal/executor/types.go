@@ -11,7 +11,8 @@ type TaskDefinition struct {
 	MaxRetry            int                        `json:"max_retry"`
 	TimeoutSeconds      int                        `json:"timeout_seconds"`
 	Parameters          map[string]interface{}     `json:"parameters"`
-	Status              models.TaskStatus          `json:"status"` // 初始状态，可以是 active 或 paused
+	ParametersSchema    map[string]interface{}     `json:"parameters_schema"` // 参数的 JSON Schema，默认参数和手动触发的参数都按其校验
+	Status              models.TaskStatus          `json:"status"`            // 初始状态，可以是 active 或 paused
 }
 
 // RegisterRequest 执行器注册请求
@@ -24,6 +25,20 @@ type RegisterRequest struct {
 	Metadata       map[string]interface{} `json:"metadata"`                         // 元数据
 }
 
+// RegisterProof 重新注册已有执行器时出示的凭证，满足其一即可
+type RegisterProof struct {
+	// Token 请求携带的注册令牌
+	Token string
+	// Verify 校验请求是否由指定执行器使用给定的回调密钥签名
+	Verify func(executorID, secret string) bool
+}
+
+// RegisterResponse 执行器注册响应，callback_secret 只在注册时返回，用于签名回调
+type RegisterResponse struct {
+	*models.Executor
+	CallbackSecret string `json:"callback_secret"`
+}
+
 // UpdateStatusRequest 更新执行器状态请求
 type UpdateStatusRequest struct {
 	Status models.ExecutorStatus `json:"status" binding:"required"`
@@ -31,14 +46,50 @@ type UpdateStatusRequest struct {
 }
 
 // ExecutionCallbackRequest 执行回调请求
+// Attempt 回显分发请求中的 attempt，与执行当前的尝试次数不符时视为上一次尝试的重复回调
 type ExecutionCallbackRequest struct {
 	ExecutionID string                 `json:"execution_id" binding:"required"`
+	Attempt     *int                   `json:"attempt" binding:"required"`
 	Status      models.ExecutionStatus `json:"status" binding:"required"`
 	Result      map[string]interface{} `json:"result"`
 	Logs        string                 `json:"logs"`
 }
 
+// ExecutionProgressRequest 执行进度上报请求，同时作为运行中执行的心跳，Attempt 与回调相同
+type ExecutionProgressRequest struct {
+	ExecutionID string           `json:"execution_id" binding:"required"`
+	Attempt     *int             `json:"attempt" binding:"required"`
+	Percent     *float64         `json:"percent" binding:"omitempty,min=0,max=100"`
+	Stage       string           `json:"stage" binding:"max=255"`
+	Counters    map[string]int64 `json:"counters"`
+}
+
+// ExecutionLogRequest 执行日志追加请求，Seq 由执行器在每次执行内从1开始递增，重试上报同一块时保持不变，Attempt 与回调相同
+type ExecutionLogRequest struct {
+	ExecutionID string `json:"execution_id" binding:"required"`
+	Attempt     *int   `json:"attempt" binding:"required"`
+	Seq         int64  `json:"seq" binding:"required,min=1"`
+	Content     string `json:"content" binding:"required"`
+}
+
+// ExecutionStatusUnknown 执行器没有该执行的记录（从未收到或重启后丢失）
+const ExecutionStatusUnknown models.ExecutionStatus = "unknown"
+
+// ExecutionStatusResponse 执行器 GET /status/:execution_id 的响应
+// 执行中返回 running，结束后返回与回调相同的终态、结果和日志，没有记录时返回 unknown
+type ExecutionStatusResponse struct {
+	ExecutionID string                 `json:"execution_id"`
+	Status      models.ExecutionStatus `json:"status"`
+	Result      map[string]interface{} `json:"result"`
+	Logs        string                 `json:"logs"`
+}
+
 // TriggerTaskRequest 触发任务请求
 type TriggerTaskRequest struct {
 	Parameters map[string]interface{} `json:"parameters"`
 }
+
+// RerunExecutionRequest 重跑执行请求，省略 parameters 时沿用原执行的参数
+type RerunExecutionRequest struct {
+	Parameters map[string]interface{} `json:"parameters"`
+}
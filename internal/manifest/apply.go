@@ -0,0 +1,486 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/internal/scheduler"
+	"github.com/jobs/scheduler/internal/storage"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Action 任务的变更类型
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// FieldChange 字段的当前值和期望值
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// Change 单个任务的变更
+type Change struct {
+	Action Action        `json:"action"`
+	Task   string        `json:"task"`
+	TaskID string        `json:"task_id"`
+	Fields []FieldChange `json:"fields,omitempty"` // 仅 update
+}
+
+// Plan 清单与数据库的差异，DryRun 为 false 时表示已应用的变更
+type Plan struct {
+	DryRun    bool     `json:"dry_run"`
+	Changes   []Change `json:"changes"`
+	Unchanged []string `json:"unchanged"`
+}
+
+// Options 应用选项
+type Options struct {
+	DryRun bool // 只计算差异，不修改数据库
+	Prune  bool // 删除清单之外的任务
+}
+
+// Applier 将清单应用到数据库
+type Applier struct {
+	storage  *storage.Storage
+	syncTask func(taskID string) error
+	logger   *zap.Logger
+}
+
+// NewApplier 创建清单应用器，syncTask 在变更提交后刷新任务的调度条目，可以为 nil
+func NewApplier(storage *storage.Storage, syncTask func(taskID string) error, logger *zap.Logger) *Applier {
+	return &Applier{
+		storage:  storage,
+		syncTask: syncTask,
+		logger:   logger,
+	}
+}
+
+// state 数据库中的当前状态
+type state struct {
+	tasks       map[string]*models.Task // 按名称，包含已删除的任务
+	names       map[string]string       // 任务ID -> 名称
+	upstream    map[string][]models.TaskDependency
+	assignments map[string][]models.TaskExecutor
+	executors   map[string]bool
+}
+
+// Apply 计算清单与数据库的差异，非 dry-run 时在一个事务内应用全部变更
+func (a *Applier) Apply(ctx context.Context, m *Manifest, opts Options) (*Plan, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	st, err := a.load()
+	if err != nil {
+		return nil, err
+	}
+
+	plan, ops, err := diff(st, m, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun || len(plan.Changes) == 0 {
+		return plan, nil
+	}
+
+	err = a.storage.DB().Transaction(func(tx *gorm.DB) error {
+		for _, op := range ops {
+			if err := op(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply manifest: %w", err)
+	}
+
+	counts := make(map[Action]int)
+	for _, change := range plan.Changes {
+		counts[change.Action]++
+		if a.syncTask == nil {
+			continue
+		}
+		if err := a.syncTask(change.TaskID); err != nil {
+			a.logger.Error("failed to sync task after apply", zap.String("task_id", change.TaskID), zap.Error(err))
+		}
+	}
+	a.logger.Info("manifest applied",
+		zap.Int("created", counts[ActionCreate]),
+		zap.Int("updated", counts[ActionUpdate]),
+		zap.Int("deleted", counts[ActionDelete]),
+		zap.Int("unchanged", len(plan.Unchanged)))
+
+	return plan, nil
+}
+
+// load 读取任务、依赖、执行器分配和执行器
+func (a *Applier) load() (*state, error) {
+	db := a.storage.DB()
+
+	var tasks []models.Task
+	if err := db.Find(&tasks).Error; err != nil {
+		return nil, fmt.Errorf("failed to load tasks: %w", err)
+	}
+	var deps []models.TaskDependency
+	if err := db.Find(&deps).Error; err != nil {
+		return nil, fmt.Errorf("failed to load dependencies: %w", err)
+	}
+	var assignments []models.TaskExecutor
+	if err := db.Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load task executors: %w", err)
+	}
+	var executorIDs []string
+	if err := db.Model(&models.Executor{}).Pluck("id", &executorIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to load executors: %w", err)
+	}
+
+	st := &state{
+		tasks:       make(map[string]*models.Task, len(tasks)),
+		names:       make(map[string]string, len(tasks)),
+		upstream:    make(map[string][]models.TaskDependency),
+		assignments: make(map[string][]models.TaskExecutor),
+		executors:   make(map[string]bool, len(executorIDs)),
+	}
+	for i := range tasks {
+		st.tasks[tasks[i].Name] = &tasks[i]
+		st.names[tasks[i].ID] = tasks[i].Name
+	}
+	for _, dep := range deps {
+		st.upstream[dep.TaskID] = append(st.upstream[dep.TaskID], dep)
+	}
+	for _, assignment := range assignments {
+		st.assignments[assignment.TaskID] = append(st.assignments[assignment.TaskID], assignment)
+	}
+	for _, id := range executorIDs {
+		st.executors[id] = true
+	}
+	return st, nil
+}
+
+// diff 计算变更及对应的数据库操作，依赖在全部任务写入后再替换
+func diff(st *state, m *Manifest, opts Options) (*Plan, []func(tx *gorm.DB) error, error) {
+	plan := &Plan{DryRun: opts.DryRun, Changes: []Change{}, Unchanged: []string{}}
+	var taskOps, relationOps []func(tx *gorm.DB) error
+
+	// 清单中的任务名称 -> ID，新任务预先分配ID
+	ids := make(map[string]string, len(m.Tasks))
+	for _, spec := range m.Tasks {
+		if existing, ok := st.tasks[spec.Name]; ok {
+			ids[spec.Name] = existing.ID
+		} else {
+			ids[spec.Name] = uuid.New().String()
+		}
+	}
+
+	// 清单之外的未删除任务，prune 时删除，否则保持不变且可以被依赖
+	var pruned []*models.Task
+	unmanaged := make(map[string]*models.Task)
+	for name, task := range st.tasks {
+		if _, ok := ids[name]; ok || task.Status == models.TaskStatusDeleted {
+			continue
+		}
+		if opts.Prune {
+			pruned = append(pruned, task)
+		} else {
+			unmanaged[name] = task
+		}
+	}
+	sort.Slice(pruned, func(i, j int) bool { return pruned[i].Name < pruned[j].Name })
+
+	graph := make(map[string][]string, len(ids)+len(unmanaged))
+	for name, task := range unmanaged {
+		for _, dep := range st.upstream[task.ID] {
+			graph[name] = append(graph[name], st.names[dep.DependsOnTaskID])
+		}
+	}
+
+	for _, spec := range m.Tasks {
+		taskID := ids[spec.Name]
+		existing := st.tasks[spec.Name]
+
+		wantDeps, depRows, err := resolveDependencies(spec, taskID, ids, unmanaged)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, dep := range wantDeps {
+			graph[spec.Name] = append(graph[spec.Name], dep.Task)
+		}
+
+		var wantExecutors []ExecutorSpec
+		if spec.Executors != nil {
+			wantExecutors, err = normalizeExecutors(spec.Executors, st.executors)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: task %q: %v", ErrInvalidManifest, spec.Name, err)
+			}
+		}
+
+		task := spec.desired()
+		task.ID = taskID
+		if existing != nil {
+			// 参数 schema 由执行器注册时声明，清单中的参数同样需要满足
+			task.ParametersSchema = existing.ParametersSchema
+		}
+		if err := task.ValidateParameters(task.Parameters); err != nil {
+			return nil, nil, fmt.Errorf("%w: task %q: %v", ErrInvalidManifest, spec.Name, err)
+		}
+		change := Change{Task: spec.Name, TaskID: taskID}
+		if existing == nil {
+			change.Action = ActionCreate
+			maxRetry := task.MaxRetry
+			taskOps = append(taskOps, func(tx *gorm.DB) error {
+				if err := tx.Create(&task).Error; err != nil {
+					return err
+				}
+				if maxRetry != 0 {
+					return nil
+				}
+				// 零值在插入时会被列默认值替换，需要单独写回
+				return tx.Model(&models.Task{}).Where("id = ?", task.ID).Update("max_retry", 0).Error
+			})
+		} else {
+			change.Action = ActionUpdate
+			change.Fields = taskFieldChanges(existing, &task)
+			if len(change.Fields) > 0 {
+				// 只写入清单管理的列，避免覆盖并发触发刚推进的 last_scheduled_time
+				updates := map[string]interface{}{
+					"cron_expression":       task.CronExpression,
+					"timezone":              task.Timezone,
+					"parameters":            task.Parameters,
+					"execution_mode":        task.ExecutionMode,
+					"load_balance_strategy": task.LoadBalanceStrategy,
+					"max_retry":             task.MaxRetry,
+					"retry_policy":          task.RetryPolicy,
+					"timeout_seconds":       task.TimeoutSeconds,
+					"misfire_policy":        task.MisfirePolicy,
+					"misfire_limit":         task.MisfireLimit,
+					"status":                task.Status,
+				}
+				// 与恢复任务一致，暂停或删除期间错过的触发不补偿
+				if existing.Status != models.TaskStatusActive && task.Status == models.TaskStatusActive {
+					updates["last_scheduled_time"] = time.Now()
+				}
+				taskOps = append(taskOps, func(tx *gorm.DB) error {
+					return tx.Model(&models.Task{}).Where("id = ?", taskID).Updates(updates).Error
+				})
+			}
+		}
+
+		currentDeps := currentDependencies(st, taskID)
+		if !reflect.DeepEqual(currentDeps, wantDeps) {
+			if existing != nil {
+				change.Fields = append(change.Fields, FieldChange{Field: "dependencies", Old: currentDeps, New: wantDeps})
+			}
+			relationOps = append(relationOps, func(tx *gorm.DB) error {
+				if err := tx.Where("task_id = ?", taskID).Delete(&models.TaskDependency{}).Error; err != nil {
+					return err
+				}
+				if len(depRows) > 0 {
+					if err := tx.Create(&depRows).Error; err != nil {
+						return err
+					}
+				}
+				return scheduler.TouchTask(tx, taskID)
+			})
+		}
+
+		if spec.Executors != nil {
+			currentExecutors := currentAssignments(st, taskID)
+			if !reflect.DeepEqual(currentExecutors, wantExecutors) {
+				if existing != nil {
+					change.Fields = append(change.Fields, FieldChange{Field: "executors", Old: currentExecutors, New: wantExecutors})
+				}
+				relationOps = append(relationOps, assignExecutors(taskID, st.assignments[taskID], wantExecutors))
+			}
+		}
+
+		if change.Action == ActionUpdate && len(change.Fields) == 0 {
+			plan.Unchanged = append(plan.Unchanged, spec.Name)
+			continue
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	if err := scheduler.ValidateGraph(graph); err != nil {
+		return nil, nil, err
+	}
+
+	if len(pruned) > 0 {
+		prunedIDs := make([]string, 0, len(pruned))
+		for _, task := range pruned {
+			prunedIDs = append(prunedIDs, task.ID)
+			plan.Changes = append(plan.Changes, Change{Action: ActionDelete, Task: task.Name, TaskID: task.ID})
+		}
+		// 与删除任务接口一致使用软删除，同时移除相关依赖以免残留的边影响工作流
+		relationOps = append(relationOps, func(tx *gorm.DB) error {
+			if err := tx.Model(&models.Task{}).
+				Where("id IN ?", prunedIDs).
+				Update("status", models.TaskStatusDeleted).Error; err != nil {
+				return err
+			}
+			return tx.Where("task_id IN ? OR depends_on_task_id IN ?", prunedIDs, prunedIDs).
+				Delete(&models.TaskDependency{}).Error
+		})
+	}
+
+	return plan, append(taskOps, relationOps...), nil
+}
+
+// taskFieldChanges 比较任务字段，参数为 nil 与空对象视为相同
+func taskFieldChanges(current, want *models.Task) []FieldChange {
+	var changes []FieldChange
+	add := func(field string, old, new interface{}) {
+		if !reflect.DeepEqual(old, new) {
+			changes = append(changes, FieldChange{Field: field, Old: old, New: new})
+		}
+	}
+
+	currentParams := current.Parameters
+	if currentParams == nil {
+		currentParams = models.JSONMap{}
+	}
+
+	add("cron_expression", current.CronExpression, want.CronExpression)
+	add("timezone", current.Timezone, want.Timezone)
+	add("parameters", currentParams, want.Parameters)
+	add("execution_mode", current.ExecutionMode, want.ExecutionMode)
+	add("load_balance_strategy", current.LoadBalanceStrategy, want.LoadBalanceStrategy)
+	add("max_retry", current.MaxRetry, want.MaxRetry)
+	add("retry_policy", current.RetryPolicy, want.RetryPolicy)
+	add("timeout_seconds", current.TimeoutSeconds, want.TimeoutSeconds)
+	add("misfire_policy", current.MisfirePolicy, want.MisfirePolicy)
+	add("misfire_limit", current.MisfireLimit, want.MisfireLimit)
+	add("status", current.Status, want.Status)
+	return changes
+}
+
+// resolveDependencies 将上游名称解析为任务ID，上游必须在清单中或是未被删除的现有任务
+func resolveDependencies(spec TaskSpec, taskID string, ids map[string]string, unmanaged map[string]*models.Task) ([]DependencySpec, []models.TaskDependency, error) {
+	specs := make([]DependencySpec, 0, len(spec.Dependencies))
+	rows := make([]models.TaskDependency, 0, len(spec.Dependencies))
+	for _, dep := range spec.Dependencies {
+		upstreamID, ok := ids[dep.Task]
+		if !ok {
+			task, exists := unmanaged[dep.Task]
+			if !exists {
+				return nil, nil, fmt.Errorf("%w: task %q depends on unknown task %q", ErrInvalidManifest, spec.Name, dep.Task)
+			}
+			upstreamID = task.ID
+		}
+
+		policy := dep.FailurePolicy
+		if policy == "" {
+			policy = models.DependencyPolicySkip
+		}
+		specs = append(specs, DependencySpec{Task: dep.Task, FailurePolicy: policy})
+		rows = append(rows, models.TaskDependency{
+			ID:              uuid.New().String(),
+			TaskID:          taskID,
+			DependsOnTaskID: upstreamID,
+			FailurePolicy:   policy,
+		})
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Task < specs[j].Task })
+	return specs, rows, nil
+}
+
+func currentDependencies(st *state, taskID string) []DependencySpec {
+	specs := make([]DependencySpec, 0, len(st.upstream[taskID]))
+	for _, dep := range st.upstream[taskID] {
+		name, ok := st.names[dep.DependsOnTaskID]
+		if !ok {
+			name = dep.DependsOnTaskID
+		}
+		specs = append(specs, DependencySpec{Task: name, FailurePolicy: dep.FailurePolicy})
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Task < specs[j].Task })
+	return specs
+}
+
+// normalizeExecutors 填充与分配执行器接口一致的默认优先级和权重
+func normalizeExecutors(specs []ExecutorSpec, executors map[string]bool) ([]ExecutorSpec, error) {
+	normalized := make([]ExecutorSpec, 0, len(specs))
+	for _, e := range specs {
+		if !executors[e.ID] {
+			return nil, fmt.Errorf("executor %q not found", e.ID)
+		}
+		if e.Priority == 0 {
+			e.Priority = 1
+		}
+		if e.Weight == 0 {
+			e.Weight = 1
+		}
+		normalized = append(normalized, e)
+	}
+	sort.Slice(normalized, func(i, j int) bool { return normalized[i].ID < normalized[j].ID })
+	return normalized, nil
+}
+
+func currentAssignments(st *state, taskID string) []ExecutorSpec {
+	specs := make([]ExecutorSpec, 0, len(st.assignments[taskID]))
+	for _, assignment := range st.assignments[taskID] {
+		specs = append(specs, ExecutorSpec{ID: assignment.ExecutorID, Priority: assignment.Priority, Weight: assignment.Weight})
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].ID < specs[j].ID })
+	return specs
+}
+
+// assignExecutors 删除清单之外的分配，更新或创建清单中的分配
+func assignExecutors(taskID string, current []models.TaskExecutor, want []ExecutorSpec) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		existing := make(map[string]models.TaskExecutor, len(current))
+		for _, assignment := range current {
+			existing[assignment.ExecutorID] = assignment
+		}
+
+		keep := make(map[string]bool, len(want))
+		for _, e := range want {
+			keep[e.ID] = true
+			assignment, ok := existing[e.ID]
+			if !ok {
+				assignment = models.TaskExecutor{
+					ID:         uuid.New().String(),
+					TaskID:     taskID,
+					ExecutorID: e.ID,
+					Priority:   e.Priority,
+					Weight:     e.Weight,
+				}
+				if err := tx.Create(&assignment).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if assignment.Priority == e.Priority && assignment.Weight == e.Weight {
+				continue
+			}
+			assignment.Priority = e.Priority
+			assignment.Weight = e.Weight
+			if err := tx.Save(&assignment).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, assignment := range current {
+			if keep[assignment.ExecutorID] {
+				continue
+			}
+			if err := tx.Delete(&models.TaskExecutor{}, "id = ?", assignment.ID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,243 @@
+package manifest
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/internal/scheduler"
+	"github.com/jobs/scheduler/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestApplier(t *testing.T) (*Applier, *storage.Storage, *[]string) {
+	st, err := storage.New(storage.Config{
+		Driver:   storage.DriverSQLite,
+		Database: filepath.Join(t.TempDir(), "manifest.db"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { st.Close() })
+
+	var synced []string
+	applier := NewApplier(st, func(taskID string) error {
+		synced = append(synced, taskID)
+		return nil
+	}, zap.NewNop())
+	return applier, st, &synced
+}
+
+func mustParse(t *testing.T, data string) *Manifest {
+	m, err := Parse([]byte(data))
+	require.NoError(t, err)
+	return m
+}
+
+func TestApply(t *testing.T) {
+	applier, st, synced := newTestApplier(t)
+	ctx := context.Background()
+	require.NoError(t, st.DB().Create(&models.Executor{ID: "executor-1", Name: "executor-1", BaseURL: "http://e1"}).Error)
+	require.NoError(t, st.DB().Create(&models.Task{ID: "legacy", Name: "legacy", CronExpression: "0 * * * * *", Status: models.TaskStatusActive}).Error)
+
+	m := mustParse(t, `
+tasks:
+  - name: load
+    cron_expression: "0 0 3 * * *"
+    max_retry: 0
+    dependencies:
+      - task: extract
+        failure_policy: fail
+  - name: extract
+    cron_expression: "0 0 2 * * *"
+    timezone: Asia/Shanghai
+    parameters: {date: today}
+    retry_policy: {backoff: fixed, initial_delay_seconds: 10, retry_on: [failed]}
+    executors:
+      - id: executor-1
+        weight: 5
+`)
+
+	// dry-run 只返回差异
+	plan, err := applier.Apply(ctx, m, Options{DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	assert.Equal(t, ActionCreate, plan.Changes[0].Action)
+	var count int64
+	st.DB().Model(&models.Task{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+	assert.Empty(t, *synced)
+
+	plan, err = applier.Apply(ctx, m, Options{})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	assert.Len(t, *synced, 2)
+
+	var load, extract models.Task
+	require.NoError(t, st.DB().Where("name = ?", "load").First(&load).Error)
+	require.NoError(t, st.DB().Where("name = ?", "extract").First(&extract).Error)
+	assert.Equal(t, 0, load.MaxRetry)
+	assert.Equal(t, models.ExecutionModeParallel, load.ExecutionMode)
+	assert.Equal(t, "Asia/Shanghai", extract.Timezone)
+	assert.Nil(t, load.RetryPolicy)
+	require.NotNil(t, extract.RetryPolicy)
+	assert.Equal(t, models.RetryBackoffFixed, extract.RetryPolicy.Backoff)
+	assert.Equal(t, []models.ExecutionStatus{models.ExecutionStatusFailed}, extract.RetryPolicy.RetryOn)
+
+	var deps []models.TaskDependency
+	require.NoError(t, st.DB().Find(&deps).Error)
+	require.Len(t, deps, 1)
+	assert.Equal(t, load.ID, deps[0].TaskID)
+	assert.Equal(t, extract.ID, deps[0].DependsOnTaskID)
+	assert.Equal(t, models.DependencyPolicyFail, deps[0].FailurePolicy)
+
+	var assignment models.TaskExecutor
+	require.NoError(t, st.DB().Where("task_id = ?", extract.ID).First(&assignment).Error)
+	assert.Equal(t, 1, assignment.Priority)
+	assert.Equal(t, 5, assignment.Weight)
+
+	// 再次应用没有变更
+	plan, err = applier.Apply(ctx, m, Options{})
+	require.NoError(t, err)
+	assert.Empty(t, plan.Changes)
+	assert.ElementsMatch(t, []string{"load", "extract"}, plan.Unchanged)
+
+	// 修改字段并 prune 清单之外的任务
+	m = mustParse(t, `
+tasks:
+  - name: load
+    cron_expression: "0 30 3 * * *"
+    max_retry: 0
+    status: paused
+  - name: extract
+    cron_expression: "0 0 2 * * *"
+    timezone: Asia/Shanghai
+    parameters: {date: today}
+    retry_policy: {backoff: fixed, initial_delay_seconds: 10, retry_on: [failed]}
+`)
+	plan, err = applier.Apply(ctx, m, Options{Prune: true})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	assert.Equal(t, ActionUpdate, plan.Changes[0].Action)
+	fields := map[string]FieldChange{}
+	for _, f := range plan.Changes[0].Fields {
+		fields[f.Field] = f
+	}
+	assert.Equal(t, "0 30 3 * * *", fields["cron_expression"].New)
+	assert.Equal(t, models.TaskStatusPaused, fields["status"].New)
+	assert.Contains(t, fields, "dependencies")
+	assert.Equal(t, Change{Action: ActionDelete, Task: "legacy", TaskID: "legacy"}, plan.Changes[1])
+	assert.Equal(t, []string{"extract"}, plan.Unchanged)
+
+	var legacy models.Task
+	require.NoError(t, st.DB().Where("id = ?", "legacy").First(&legacy).Error)
+	assert.Equal(t, models.TaskStatusDeleted, legacy.Status)
+	st.DB().Model(&models.TaskDependency{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+	// 未声明 executors 时保留现有分配
+	st.DB().Model(&models.TaskExecutor{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	// 更新不覆盖最近触发时间，从暂停恢复时从现在开始计算错过的触发
+	stale := time.Now().Add(-time.Hour)
+	require.NoError(t, st.DB().Model(&models.Task{}).
+		Where("name IN ?", []string{"load", "extract"}).
+		UpdateColumn("last_scheduled_time", stale).Error)
+	m = mustParse(t, `
+tasks:
+  - name: load
+    cron_expression: "0 30 3 * * *"
+    max_retry: 0
+  - name: extract
+    cron_expression: "0 30 2 * * *"
+    timezone: Asia/Shanghai
+    parameters: {date: today}
+    retry_policy: {backoff: fixed, initial_delay_seconds: 10, retry_on: [failed]}
+`)
+	_, err = applier.Apply(ctx, m, Options{})
+	require.NoError(t, err)
+	require.NoError(t, st.DB().Where("name = ?", "load").First(&load).Error)
+	require.NoError(t, st.DB().Where("name = ?", "extract").First(&extract).Error)
+	assert.Equal(t, models.TaskStatusActive, load.Status)
+	require.NotNil(t, load.LastScheduledTime)
+	assert.WithinDuration(t, time.Now(), *load.LastScheduledTime, time.Minute)
+	require.NotNil(t, extract.LastScheduledTime)
+	assert.WithinDuration(t, stale, *extract.LastScheduledTime, time.Second)
+	assert.Equal(t, "0 30 2 * * *", extract.CronExpression)
+}
+
+func TestApplyRejectsInvalidManifest(t *testing.T) {
+	applier, st, _ := newTestApplier(t)
+	ctx := context.Background()
+
+	_, err := Parse([]byte("tasks:\n  - name: a\n    cron: \"* * * * * *\"\n"))
+	assert.ErrorIs(t, err, ErrInvalidManifest)
+
+	_, err = applier.Apply(ctx, mustParse(t, `
+tasks:
+  - name: a
+    cron_expression: "0 * * * * *"
+    dependencies: [{task: missing}]
+`), Options{})
+	assert.ErrorIs(t, err, ErrInvalidManifest)
+
+	_, err = applier.Apply(ctx, mustParse(t, `
+tasks:
+  - name: a
+    cron_expression: "0 * * * * *"
+    executors: [{id: missing}]
+`), Options{})
+	assert.ErrorIs(t, err, ErrInvalidManifest)
+
+	_, err = applier.Apply(ctx, mustParse(t, `
+tasks:
+  - name: a
+    cron_expression: "0 * * * * *"
+    dependencies: [{task: b}]
+  - name: b
+    cron_expression: "0 * * * * *"
+    dependencies: [{task: a}]
+`), Options{})
+	assert.ErrorIs(t, err, scheduler.ErrDependencyCycle)
+
+	_, err = applier.Apply(ctx, mustParse(t, `
+tasks:
+  - name: a
+    cron_expression: "0 * * * * *"
+    retry_policy: {retry_on: [cancelled]}
+`), Options{})
+	assert.ErrorIs(t, err, ErrInvalidManifest)
+
+	// fire_all 只能用于并行模式
+	_, err = applier.Apply(ctx, mustParse(t, `
+tasks:
+  - name: a
+    cron_expression: "0 * * * * *"
+    execution_mode: skip
+    misfire_policy: fire_all
+`), Options{})
+	assert.ErrorIs(t, err, ErrInvalidManifest)
+	assert.ErrorContains(t, err, "requires execution_mode parallel")
+
+	var count int64
+	st.DB().Model(&models.Task{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	// 参数需要满足执行器声明的 schema
+	require.NoError(t, st.DB().Create(&models.Task{
+		ID:               "report",
+		Name:             "report",
+		CronExpression:   "0 * * * * *",
+		ParametersSchema: models.JSONMap{"type": "object", "properties": map[string]interface{}{"limit": map[string]interface{}{"type": "integer"}}},
+	}).Error)
+	_, err = applier.Apply(ctx, mustParse(t, `
+tasks:
+  - name: report
+    cron_expression: "0 * * * * *"
+    parameters: {limit: many}
+`), Options{})
+	assert.ErrorIs(t, err, ErrInvalidManifest)
+	assert.ErrorContains(t, err, "limit: must be integer")
+}
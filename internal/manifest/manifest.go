@@ -0,0 +1,219 @@
+// Package manifest 以声明式清单管理任务定义：解析 YAML/JSON 清单，计算与数据库的差异并应用。
+//
+// 清单中的任务按名称匹配，依赖以上游任务名称引用：
+//
+//	tasks:
+//	  - name: extract
+//	    cron_expression: "0 0 2 * * *"
+//	    executors:
+//	      - id: executor-001
+//	  - name: load
+//	    cron_expression: "0 0 3 * * *"
+//	    dependencies:
+//	      - task: extract
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/pkg/cronexpr"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidManifest 清单格式或内容不合法
+var ErrInvalidManifest = errors.New("invalid manifest")
+
+// Manifest 任务清单
+type Manifest struct {
+	Tasks []TaskSpec `json:"tasks"`
+}
+
+// TaskSpec 任务的期望状态，零值字段使用与创建任务接口相同的默认值
+type TaskSpec struct {
+	Name                string                     `json:"name"`
+	CronExpression      string                     `json:"cron_expression"`
+	Timezone            string                     `json:"timezone,omitempty"`
+	Parameters          models.JSONMap             `json:"parameters,omitempty"`
+	ExecutionMode       models.ExecutionMode       `json:"execution_mode,omitempty"`
+	LoadBalanceStrategy models.LoadBalanceStrategy `json:"load_balance_strategy,omitempty"`
+	MaxRetry            *int                       `json:"max_retry,omitempty"` // nil表示默认3次
+	RetryPolicy         *models.RetryPolicy        `json:"retry_policy,omitempty"`
+	TimeoutSeconds      int                        `json:"timeout_seconds,omitempty"`
+	MisfirePolicy       models.MisfirePolicy       `json:"misfire_policy,omitempty"`
+	MisfireLimit        int                        `json:"misfire_limit,omitempty"`
+	Status              models.TaskStatus          `json:"status,omitempty"` // active 或 paused，默认 active
+	Dependencies        []DependencySpec           `json:"dependencies,omitempty"`
+	// Executors nil表示不管理执行器分配（保留执行器注册时创建的分配），空数组表示取消全部分配
+	Executors []ExecutorSpec `json:"executors"`
+}
+
+// DependencySpec 按名称引用的上游依赖
+type DependencySpec struct {
+	Task          string                         `json:"task"`
+	FailurePolicy models.DependencyFailurePolicy `json:"failure_policy,omitempty"`
+}
+
+// ExecutorSpec 任务的执行器分配
+type ExecutorSpec struct {
+	ID       string `json:"id"`
+	Priority int    `json:"priority,omitempty"`
+	Weight   int    `json:"weight,omitempty"`
+}
+
+// Parse 解析 YAML 或 JSON 清单，未知字段视为错误以便发现拼写错误
+func Parse(data []byte) (*Manifest, error) {
+	var generic interface{}
+	if err := yaml.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	converted, err := json.Marshal(generic)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+
+	var m Manifest
+	decoder := json.NewDecoder(bytes.NewReader(converted))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&m); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidManifest, err)
+	}
+	return &m, nil
+}
+
+// Validate 校验清单内容，不访问数据库
+func (m *Manifest) Validate() error {
+	names := make(map[string]bool, len(m.Tasks))
+	for i := range m.Tasks {
+		spec := &m.Tasks[i]
+		if spec.Name == "" {
+			return fmt.Errorf("%w: tasks[%d]: name is required", ErrInvalidManifest, i)
+		}
+		if names[spec.Name] {
+			return fmt.Errorf("%w: task %q is declared more than once", ErrInvalidManifest, spec.Name)
+		}
+		names[spec.Name] = true
+
+		if err := spec.validate(); err != nil {
+			return fmt.Errorf("%w: task %q: %v", ErrInvalidManifest, spec.Name, err)
+		}
+	}
+	return nil
+}
+
+func (s *TaskSpec) validate() error {
+	if s.CronExpression == "" {
+		return errors.New("cron_expression is required")
+	}
+	if _, err := cronexpr.Parse(s.CronExpression, s.Timezone); err != nil {
+		return err
+	}
+
+	switch s.ExecutionMode {
+	case "", models.ExecutionModeSequential, models.ExecutionModeParallel, models.ExecutionModeSkip:
+	default:
+		return fmt.Errorf("invalid execution_mode %q", s.ExecutionMode)
+	}
+	switch s.LoadBalanceStrategy {
+	case "", models.LoadBalanceRoundRobin, models.LoadBalanceWeightedRoundRobin, models.LoadBalanceRandom,
+		models.LoadBalanceSticky, models.LoadBalanceLeastLoaded:
+	default:
+		return fmt.Errorf("invalid load_balance_strategy %q", s.LoadBalanceStrategy)
+	}
+	switch s.MisfirePolicy {
+	case "", models.MisfirePolicyIgnore, models.MisfirePolicyFireOnce, models.MisfirePolicyFireAll:
+	default:
+		return fmt.Errorf("invalid misfire_policy %q", s.MisfirePolicy)
+	}
+	switch s.Status {
+	case "", models.TaskStatusActive, models.TaskStatusPaused:
+	default:
+		return fmt.Errorf("invalid status %q, expected active or paused", s.Status)
+	}
+	if s.MaxRetry != nil && *s.MaxRetry < 0 {
+		return errors.New("max_retry must not be negative")
+	}
+	if s.RetryPolicy != nil {
+		if err := s.RetryPolicy.Validate(); err != nil {
+			return err
+		}
+	}
+	if s.TimeoutSeconds < 0 || s.MisfireLimit < 0 {
+		return errors.New("timeout_seconds and misfire_limit must not be negative")
+	}
+	desired := s.desired()
+	if err := desired.ValidateMisfire(); err != nil {
+		return err
+	}
+
+	upstream := make(map[string]bool, len(s.Dependencies))
+	for _, dep := range s.Dependencies {
+		if dep.Task == "" {
+			return errors.New("dependency task is required")
+		}
+		if upstream[dep.Task] {
+			return fmt.Errorf("duplicate dependency on %q", dep.Task)
+		}
+		upstream[dep.Task] = true
+		switch dep.FailurePolicy {
+		case "", models.DependencyPolicySkip, models.DependencyPolicyFail, models.DependencyPolicyRun:
+		default:
+			return fmt.Errorf("invalid failure_policy %q", dep.FailurePolicy)
+		}
+	}
+
+	executors := make(map[string]bool, len(s.Executors))
+	for _, e := range s.Executors {
+		if e.ID == "" {
+			return errors.New("executor id is required")
+		}
+		if executors[e.ID] {
+			return fmt.Errorf("executor %q is assigned more than once", e.ID)
+		}
+		executors[e.ID] = true
+	}
+	return nil
+}
+
+// desired 按清单生成任务的期望字段，默认值与创建任务接口一致
+func (s *TaskSpec) desired() models.Task {
+	task := models.Task{
+		Name:                s.Name,
+		CronExpression:      s.CronExpression,
+		Timezone:            s.Timezone,
+		Parameters:          s.Parameters,
+		ExecutionMode:       s.ExecutionMode,
+		LoadBalanceStrategy: s.LoadBalanceStrategy,
+		MaxRetry:            3,
+		RetryPolicy:         s.RetryPolicy,
+		TimeoutSeconds:      s.TimeoutSeconds,
+		MisfirePolicy:       s.MisfirePolicy,
+		MisfireLimit:        s.MisfireLimit,
+		Status:              s.Status,
+	}
+	if s.MaxRetry != nil {
+		task.MaxRetry = *s.MaxRetry
+	}
+	if task.Parameters == nil {
+		task.Parameters = models.JSONMap{}
+	}
+	if task.ExecutionMode == "" {
+		task.ExecutionMode = models.ExecutionModeParallel
+	}
+	if task.LoadBalanceStrategy == "" {
+		task.LoadBalanceStrategy = models.LoadBalanceRoundRobin
+	}
+	if task.TimeoutSeconds == 0 {
+		task.TimeoutSeconds = 300
+	}
+	if task.MisfirePolicy == "" {
+		task.MisfirePolicy = models.MisfirePolicyIgnore
+	}
+	if task.Status == "" {
+		task.Status = models.TaskStatusActive
+	}
+	return task
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+type BackfillStatus string
+
+const (
+	BackfillStatusRunning   BackfillStatus = "running"
+	BackfillStatusSucceeded BackfillStatus = "succeeded"
+	BackfillStatusFailed    BackfillStatus = "failed"
+	BackfillStatusCancelled BackfillStatus = "cancelled"
+)
+
+// Backfill 历史区间补跑，为 [StartTime, EndTime] 内的每个 cron 触发时间创建一次执行
+// 执行按触发时间顺序逐步创建，同时未结束的执行不超过 MaxConcurrency；串行和跳过模式的任务一次只运行一个
+type Backfill struct {
+	ID        string    `gorm:"primaryKey;size:64" json:"id"`
+	TaskID    string    `gorm:"size:64;not null;index" json:"task_id"`
+	StartTime time.Time `gorm:"not null" json:"start_time"`
+	EndTime   time.Time `gorm:"not null" json:"end_time"`
+
+	// 创建时的表达式和时区，之后修改任务不影响补跑的触发时间
+	CronExpression string `gorm:"size:255;not null" json:"cron_expression"`
+	Timezone       string `gorm:"size:64" json:"timezone"`
+
+	// 每个执行的参数：创建补跑时任务默认参数合并覆盖的结果
+	Parameters     JSONMap        `gorm:"type:json" json:"parameters,omitempty"`
+	MaxConcurrency int            `gorm:"not null;default:1" json:"max_concurrency"`
+	Status         BackfillStatus `gorm:"size:32;default:'running';index" json:"status"`
+
+	// Total 区间内的触发次数；Created 已创建执行的次数，NextTime 为下一个待创建的触发时间，全部创建后为空
+	Total    int        `gorm:"not null" json:"total"`
+	Created  int        `gorm:"not null;default:0" json:"created"`
+	NextTime *time.Time `gorm:"" json:"next_time"`
+
+	FinishedAt *time.Time `gorm:"" json:"finished_at"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Task       *Task           `gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE" json:"task,omitempty"`
+	Executions []TaskExecution `gorm:"foreignKey:BackfillID;constraint:OnDelete:SET NULL" json:"executions,omitempty"`
+}
+
+func (Backfill) TableName() string {
+	return "backfills"
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// DependencyFailurePolicy 上游未成功时对下游的处理策略
+type DependencyFailurePolicy string
+
+const (
+	DependencyPolicySkip DependencyFailurePolicy = "skip" // 下游记为skipped并继续向下传播
+	DependencyPolicyFail DependencyFailurePolicy = "fail" // 下游记为failed并继续向下传播
+	DependencyPolicyRun  DependencyFailurePolicy = "run"  // 忽略上游结果，照常运行下游
+)
+
+// TaskDependency 任务依赖关系（TaskID 依赖 DependsOnTaskID）
+type TaskDependency struct {
+	ID              string                  `gorm:"primaryKey;size:64" json:"id"`
+	TaskID          string                  `gorm:"size:64;not null;uniqueIndex:uk_task_dependency;index" json:"task_id"`
+	DependsOnTaskID string                  `gorm:"size:64;not null;uniqueIndex:uk_task_dependency;index" json:"depends_on_task_id"`
+	FailurePolicy   DependencyFailurePolicy `gorm:"size:32;default:'skip'" json:"failure_policy"`
+	CreatedAt       time.Time               `gorm:"autoCreateTime" json:"created_at"`
+
+	Task          *Task `gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE" json:"task,omitempty"`
+	DependsOnTask *Task `gorm:"foreignKey:DependsOnTaskID;constraint:OnDelete:CASCADE" json:"depends_on_task,omitempty"`
+}
+
+func (TaskDependency) TableName() string {
+	return "task_dependencies"
+}
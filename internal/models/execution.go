@@ -16,6 +16,43 @@ const (
 	ExecutionStatusCancelled ExecutionStatus = "cancelled"
 )
 
+// IsTerminal 执行状态是否为终态
+func (s ExecutionStatus) IsTerminal() bool {
+	switch s {
+	case ExecutionStatusSuccess, ExecutionStatusFailed, ExecutionStatusTimeout,
+		ExecutionStatusSkipped, ExecutionStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// executionTransitions 执行状态迁移表：pending → running → 终态
+// running → pending 用于丢失的执行重新排队；pending 可直接取消，或在分发失败时直接失败；终态不再迁移
+var executionTransitions = map[ExecutionStatus][]ExecutionStatus{
+	ExecutionStatusPending: {
+		ExecutionStatusRunning,
+		ExecutionStatusFailed,
+		ExecutionStatusCancelled,
+	},
+	ExecutionStatusRunning: {
+		ExecutionStatusSuccess,
+		ExecutionStatusFailed,
+		ExecutionStatusTimeout,
+		ExecutionStatusCancelled,
+		ExecutionStatusPending,
+	},
+}
+
+// CanTransitionTo 是否允许从当前状态迁移到 to
+func (s ExecutionStatus) CanTransitionTo(to ExecutionStatus) bool {
+	for _, next := range executionTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
 type TaskExecution struct {
 	ID            string          `gorm:"primaryKey;size:64" json:"id"`
 	TaskID        string          `gorm:"size:64;not null;index:idx_task_status" json:"task_id"`
@@ -23,12 +60,44 @@ type TaskExecution struct {
 	ScheduledTime time.Time       `gorm:"not null;index" json:"scheduled_time"`
 	StartTime     *time.Time      `gorm:"" json:"start_time"`
 	EndTime       *time.Time      `gorm:"" json:"end_time"`
-	Status        ExecutionStatus `gorm:"type:enum('pending','running','success','failed','timeout','skipped','cancelled');default:'pending';index:idx_task_status;index" json:"status"`
+	Status        ExecutionStatus `gorm:"size:32;default:'pending';index:idx_task_status;index" json:"status"`
 	Result        JSONMap         `gorm:"type:json" json:"result"`
 	Logs          string          `gorm:"type:text" json:"logs"`
 	RetryCount    int             `gorm:"default:0" json:"retry_count"`
 	CreatedAt     time.Time       `gorm:"autoCreateTime" json:"created_at"`
 
+	// 乐观锁版本号，每次状态迁移或认领变更时递增
+	Version int64 `gorm:"not null;default:0" json:"version"`
+
+	// 创建该执行时领导者持有的租约令牌，0表示非调度器主动创建（手动/依赖触发等）
+	FencingToken int64 `gorm:"default:0;index" json:"fencing_token"`
+
+	// 工作流关联：所属运行及触发本次执行的上游执行
+	WorkflowRunID     *string `gorm:"size:64;index" json:"workflow_run_id"`
+	ParentExecutionID *string `gorm:"size:64" json:"parent_execution_id"`
+
+	// 本次执行的参数（任务默认参数合并手动覆盖），创建时确定，之后修改任务不影响本次执行；可以包含参数模板
+	Parameters JSONMap `gorm:"type:json" json:"parameters"`
+	// 最近一次分发时展开模板后实际下发给执行器的参数
+	RenderedParameters JSONMap `gorm:"type:json" json:"rendered_parameters,omitempty"`
+	// 重跑时指向被重跑的执行
+	RerunOfExecutionID *string `gorm:"size:64;index" json:"rerun_of_execution_id,omitempty"`
+	// 补跑创建的执行指向所属补跑，ScheduledTime 为补跑的逻辑触发时间
+	BackfillID *string `gorm:"size:64;index" json:"backfill_id,omitempty"`
+
+	// 待分发队列的认领信息：认领该执行的调度器实例及认领有效期，过期仍为pending的执行可被重新认领
+	ClaimedBy    *string    `gorm:"size:255" json:"claimed_by,omitempty"`
+	ClaimedUntil *time.Time `gorm:"index" json:"claimed_until,omitempty"`
+
+	// 执行器上报的进度：完成百分比、当前阶段和计数器；最近一次上报时间同时作为存活心跳，超时从该时间起重新计算
+	ProgressPercent  *float64   `json:"progress_percent,omitempty"`
+	ProgressStage    string     `gorm:"size:255" json:"progress_stage,omitempty"`
+	ProgressCounters JSONMap    `gorm:"type:json" json:"progress_counters,omitempty"`
+	HeartbeatAt      *time.Time `json:"heartbeat_at,omitempty"`
+
+	// 执行期间追加的日志超过上限后，最早的日志块已被轮转删除
+	LogsRotated bool `gorm:"default:false" json:"logs_rotated,omitempty"`
+
 	Task     *Task     `gorm:"foreignKey:TaskID;constraint:OnDelete:CASCADE" json:"task,omitempty"`
 	Executor *Executor `gorm:"foreignKey:ExecutorID;constraint:OnDelete:SET NULL" json:"executor,omitempty"`
 }
@@ -37,6 +106,34 @@ func (TaskExecution) TableName() string {
 	return "task_executions"
 }
 
+// ExecutionLogChunk 执行器在运行期间追加的日志块，按自增ID排序即为写入顺序
+// Attempt 为写入时执行的重试次数，与执行器提供的 Seq 一起去重，执行器重试上报同一块时不会重复写入
+type ExecutionLogChunk struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	ExecutionID string    `gorm:"size:64;not null;uniqueIndex:uk_execution_log_seq" json:"execution_id"`
+	Attempt     int       `gorm:"not null;uniqueIndex:uk_execution_log_seq" json:"attempt"`
+	Seq         int64     `gorm:"not null;uniqueIndex:uk_execution_log_seq" json:"seq"`
+	Content     string    `gorm:"type:text" json:"content"`
+	Size        int       `gorm:"not null" json:"size"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (ExecutionLogChunk) TableName() string {
+	return "execution_log_chunks"
+}
+
+// Deadline 按超时时长计算执行的截止时间，从最近一次心跳（没有时为开始时间）起算
+func (e *TaskExecution) Deadline(timeout time.Duration) time.Time {
+	if e.StartTime == nil {
+		return time.Time{}
+	}
+	since := *e.StartTime
+	if e.HeartbeatAt != nil && e.HeartbeatAt.After(since) {
+		since = *e.HeartbeatAt
+	}
+	return since.Add(timeout)
+}
+
 type LoadBalanceState struct {
 	TaskID           string    `gorm:"primaryKey;size:64" json:"task_id"`
 	LastExecutorID   *string   `gorm:"size:64" json:"last_executor_id"`
@@ -66,3 +163,28 @@ type SchedulerInstance struct {
 func (SchedulerInstance) TableName() string {
 	return "scheduler_instances"
 }
+
+// SchedulerLock 基于行的领导者锁（选主方式为 lock 且数据库不支持会话锁时使用，如SQLite）
+type SchedulerLock struct {
+	Name      string    `gorm:"primaryKey;size:255" json:"name"`
+	Holder    string    `gorm:"size:255;not null" json:"holder"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (SchedulerLock) TableName() string {
+	return "scheduler_locks"
+}
+
+// SchedulerLease 领导者租约，FencingToken 每次易主时递增
+type SchedulerLease struct {
+	Name         string    `gorm:"primaryKey;size:255" json:"name"`
+	Holder       string    `gorm:"size:255;not null" json:"holder"`
+	FencingToken int64     `gorm:"not null;default:0" json:"fencing_token"`
+	ExpiresAt    time.Time `gorm:"not null" json:"expires_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (SchedulerLease) TableName() string {
+	return "scheduler_leases"
+}
@@ -30,7 +30,7 @@ type Executor struct {
 	InstanceID          string         `gorm:"size:255;not null;uniqueIndex;index:idx_name_instance" json:"instance_id"`
 	BaseURL             string         `gorm:"size:500;not null" json:"base_url"`
 	HealthCheckURL      string         `gorm:"size:500" json:"health_check_url"`
-	Status              ExecutorStatus `gorm:"type:enum('online','offline','maintenance');default:'online';index:idx_status_healthy" json:"status"`
+	Status              ExecutorStatus `gorm:"size:32;default:'online';index:idx_status_healthy" json:"status"`
 	IsHealthy           bool           `gorm:"default:true;index:idx_status_healthy" json:"is_healthy"`
 	LastHealthCheck     *time.Time     `gorm:"" json:"last_health_check"`
 	HealthCheckFailures int            `gorm:"default:0" json:"health_check_failures"`
@@ -38,6 +38,9 @@ type Executor struct {
 	CreatedAt           time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt           time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 
+	// 回调签名密钥，每次注册时重新签发，只在注册响应中返回
+	CallbackSecret string `gorm:"size:128" json:"-"`
+
 	TaskExecutors []TaskExecutor `gorm:"foreignKey:ExecutorID" json:"task_executors,omitempty"`
 }
 
@@ -45,6 +48,17 @@ func (Executor) TableName() string {
 	return "executors"
 }
 
+// CallbackNonce 已使用的回调 nonce，过期前重复使用视为重放
+type CallbackNonce struct {
+	ExecutorID string    `gorm:"primaryKey;size:64" json:"executor_id"`
+	Nonce      string    `gorm:"primaryKey;size:128" json:"nonce"`
+	ExpiresAt  time.Time `gorm:"not null;index" json:"expires_at"`
+}
+
+func (CallbackNonce) TableName() string {
+	return "callback_nonces"
+}
+
 type TaskExecutor struct {
 	ID         string    `gorm:"primaryKey;size:64" json:"id"`
 	TaskID     string    `gorm:"size:64;not null;uniqueIndex:uk_task_executor;index" json:"task_id"`
@@ -3,7 +3,12 @@ package models
 import (
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
+	"math/rand"
 	"time"
+
+	"github.com/jobs/scheduler/pkg/jsonschema"
+	"github.com/jobs/scheduler/pkg/paramtemplate"
 )
 
 type ExecutionMode string
@@ -32,6 +37,125 @@ const (
 	TaskStatusDeleted TaskStatus = "deleted"
 )
 
+// MisfirePolicy 调度器停机或切换领导者期间错过的触发如何补偿
+type MisfirePolicy string
+
+const (
+	MisfirePolicyIgnore   MisfirePolicy = "ignore"    // 不补偿
+	MisfirePolicyFireOnce MisfirePolicy = "fire_once" // 合并为一次执行
+	MisfirePolicyFireAll  MisfirePolicy = "fire_all"  // 逐次补偿，最多 MisfireLimit 次
+)
+
+// RetryBackoff 重试间隔的增长方式
+type RetryBackoff string
+
+const (
+	RetryBackoffFixed       RetryBackoff = "fixed"       // 每次等待 InitialDelaySeconds
+	RetryBackoffExponential RetryBackoff = "exponential" // 从 InitialDelaySeconds 起每次翻倍
+	RetryBackoffJittered    RetryBackoff = "jittered"    // 指数间隔的一半加上随机的另一半，避免大量执行同时重试
+)
+
+// 未配置重试策略时的默认值：指数退避 1s 起翻倍、最长 30s，只重试分发失败
+const (
+	defaultRetryInitialDelay = time.Second
+	defaultRetryMaxDelay     = 30 * time.Second
+)
+
+// RetryPolicy 任务的重试策略，重试次数仍由 Task.MaxRetry 限制，分发失败和执行器上报的失败共用
+type RetryPolicy struct {
+	Backoff             RetryBackoff `json:"backoff,omitempty"`               // 为空表示 exponential
+	InitialDelaySeconds int          `json:"initial_delay_seconds,omitempty"` // 首次重试前的等待，0表示1秒
+	MaxDelaySeconds     int          `json:"max_delay_seconds,omitempty"`     // 等待上限，0表示30秒
+	// 执行器上报（回调或状态轮询）以及超时的哪些终态需要重试，可选 failed、timeout；为空时只重试分发失败
+	RetryOn []ExecutionStatus `json:"retry_on,omitempty"`
+	// 重试时优先选择未失败过的执行器，没有其他健康执行器时仍使用原执行器
+	SwitchExecutor bool `json:"switch_executor,omitempty"`
+}
+
+func (p RetryPolicy) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *RetryPolicy) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	}
+	return nil
+}
+
+// Validate 校验退避方式、等待时长和可重试的状态
+func (p *RetryPolicy) Validate() error {
+	switch p.Backoff {
+	case "", RetryBackoffFixed, RetryBackoffExponential, RetryBackoffJittered:
+	default:
+		return fmt.Errorf("invalid retry backoff %q", p.Backoff)
+	}
+	if p.InitialDelaySeconds < 0 || p.MaxDelaySeconds < 0 {
+		return fmt.Errorf("retry delays must not be negative")
+	}
+	if p.InitialDelaySeconds > 0 && p.MaxDelaySeconds > 0 && p.MaxDelaySeconds < p.InitialDelaySeconds {
+		return fmt.Errorf("retry max_delay_seconds must not be less than initial_delay_seconds")
+	}
+	for _, status := range p.RetryOn {
+		if status != ExecutionStatusFailed && status != ExecutionStatusTimeout {
+			return fmt.Errorf("invalid retry_on status %q, expected failed or timeout", status)
+		}
+	}
+	return nil
+}
+
+// Delay 第 retry 次重试（从1开始）前的等待时长
+func (p *RetryPolicy) Delay(retry int) time.Duration {
+	initial, max := defaultRetryInitialDelay, defaultRetryMaxDelay
+	backoff := RetryBackoffExponential
+	if p != nil {
+		if p.InitialDelaySeconds > 0 {
+			initial = time.Duration(p.InitialDelaySeconds) * time.Second
+		}
+		if p.MaxDelaySeconds > 0 {
+			max = time.Duration(p.MaxDelaySeconds) * time.Second
+		}
+		if p.Backoff != "" {
+			backoff = p.Backoff
+		}
+	}
+	if max < initial {
+		max = initial
+	}
+	if backoff == RetryBackoffFixed {
+		return initial
+	}
+
+	delay := initial
+	for i := 1; i < retry && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	if backoff == RetryBackoffJittered {
+		half := delay / 2
+		delay = half + time.Duration(rand.Int63n(int64(delay-half)+1))
+	}
+	return delay
+}
+
+// RetriesOn 执行器上报或超时的终态是否需要重试
+func (p *RetryPolicy) RetriesOn(status ExecutionStatus) bool {
+	if p == nil {
+		return false
+	}
+	for _, s := range p.RetryOn {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 type JSONMap map[string]interface{}
 
 func (j JSONMap) Value() (driver.Value, error) {
@@ -46,8 +170,13 @@ func (j *JSONMap) Scan(value interface{}) error {
 		*j = nil
 		return nil
 	}
-	bytes, ok := value.([]byte)
-	if !ok {
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
 		return nil
 	}
 	return json.Unmarshal(bytes, j)
@@ -57,15 +186,24 @@ type Task struct {
 	ID                  string              `gorm:"primaryKey;size:64" json:"id"`
 	Name                string              `gorm:"uniqueIndex;size:255;not null" json:"name"`
 	CronExpression      string              `gorm:"size:100;not null" json:"cron_expression"`
+	Timezone            string              `gorm:"size:64" json:"timezone"` // IANA时区名称，为空表示服务器本地时区
 	Parameters          JSONMap             `gorm:"type:json" json:"parameters"`
-	ExecutionMode       ExecutionMode       `gorm:"type:enum('sequential','parallel','skip');default:'parallel'" json:"execution_mode"`
-	LoadBalanceStrategy LoadBalanceStrategy `gorm:"type:enum('round_robin','weighted_round_robin','random','sticky','least_loaded');default:'round_robin'" json:"load_balance_strategy"`
+	ParametersSchema    JSONMap             `gorm:"type:json" json:"parameters_schema,omitempty"` // 参数的 JSON Schema，为空表示不校验
+	ExecutionMode       ExecutionMode       `gorm:"size:32;default:'parallel'" json:"execution_mode"`
+	LoadBalanceStrategy LoadBalanceStrategy `gorm:"size:32;default:'round_robin'" json:"load_balance_strategy"`
 	MaxRetry            int                 `gorm:"default:3" json:"max_retry"`
+	RetryPolicy         *RetryPolicy        `gorm:"type:json" json:"retry_policy,omitempty"` // 为空时使用默认策略
 	TimeoutSeconds      int                 `gorm:"default:300" json:"timeout_seconds"`
-	Status              TaskStatus          `gorm:"type:enum('active','paused','deleted');default:'active';index" json:"status"`
+	Status              TaskStatus          `gorm:"size:32;default:'active';index" json:"status"`
+	MisfirePolicy       MisfirePolicy       `gorm:"size:32;default:'ignore'" json:"misfire_policy"`
+	MisfireLimit        int                 `gorm:"default:0" json:"misfire_limit"` // fire_all 最多补偿次数，0表示使用默认值
+	LastScheduledTime   *time.Time          `json:"last_scheduled_time"`            // 最近一次cron计划触发时间
 	CreatedAt           time.Time           `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt           time.Time           `gorm:"autoUpdateTime" json:"updated_at"`
 
+	// NextRunTime 下次触发时间，仅用于接口展示
+	NextRunTime *time.Time `gorm:"-" json:"next_run_time,omitempty"`
+
 	// 关联关系
 	TaskExecutors []TaskExecutor  `gorm:"foreignKey:TaskID" json:"task_executors,omitempty"`
 	Executions    []TaskExecution `gorm:"foreignKey:TaskID" json:"executions,omitempty"`
@@ -74,3 +212,46 @@ type Task struct {
 func (Task) TableName() string {
 	return "tasks"
 }
+
+// ValidateMisfire 校验补偿策略与执行模式的组合
+// fire_all 的各次补偿同时触发，串行模式会丢弃第一次之后的补偿、跳过模式会把它们记为跳过，因此只能用于并行模式
+func (t *Task) ValidateMisfire() error {
+	if t.MisfirePolicy == MisfirePolicyFireAll && t.ExecutionMode != ExecutionModeParallel {
+		return fmt.Errorf("misfire_policy %s requires execution_mode %s, got %s: catch-up runs fire together and would be dropped or skipped",
+			MisfirePolicyFireAll, ExecutionModeParallel, t.ExecutionMode)
+	}
+	return nil
+}
+
+// EffectiveParameters 返回任务默认参数与 overrides 合并后的副本（同名参数以 overrides 为准），不修改任务本身
+func (t *Task) EffectiveParameters(overrides map[string]interface{}) JSONMap {
+	params := make(JSONMap, len(t.Parameters)+len(overrides))
+	for k, v := range t.Parameters {
+		params[k] = v
+	}
+	for k, v := range overrides {
+		params[k] = v
+	}
+	return params
+}
+
+// ValidateParameters 校验参数模板，并按任务的参数 schema 校验以示例上下文展开后的参数，未声明 schema 时只校验模板
+// 模板不合法时返回 *paramtemplate.Error，参数不满足 schema 时返回 *jsonschema.ValidationError，
+// schema 本身不合法时返回 jsonschema.ErrInvalidSchema
+func (t *Task) ValidateParameters(params map[string]interface{}) error {
+	rendered, err := paramtemplate.Render(params, paramtemplate.SampleContext())
+	if err != nil {
+		return err
+	}
+	if len(t.ParametersSchema) == 0 {
+		return nil
+	}
+	schema, err := jsonschema.Compile(t.ParametersSchema)
+	if err != nil {
+		return err
+	}
+	if rendered == nil {
+		rendered = map[string]interface{}{}
+	}
+	return schema.Validate(rendered)
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+type WorkflowRunStatus string
+
+const (
+	WorkflowRunStatusRunning         WorkflowRunStatus = "running"
+	WorkflowRunStatusSucceeded       WorkflowRunStatus = "succeeded"
+	WorkflowRunStatusFailed          WorkflowRunStatus = "failed"
+	WorkflowRunStatusPartiallyFailed WorkflowRunStatus = "partially_failed"
+	WorkflowRunStatusCancelled       WorkflowRunStatus = "cancelled"
+)
+
+// WorkflowRun 工作流运行，聚合一次根触发沿依赖链产生的所有执行
+type WorkflowRun struct {
+	ID              string            `gorm:"primaryKey;size:64" json:"id"`
+	RootTaskID      string            `gorm:"size:64;not null;index" json:"root_task_id"`
+	RootExecutionID string            `gorm:"size:64;not null" json:"root_execution_id"`
+	Status          WorkflowRunStatus `gorm:"size:32;default:'running';index" json:"status"`
+	StartTime       time.Time         `gorm:"not null;index" json:"start_time"`
+	EndTime         *time.Time        `gorm:"" json:"end_time"`
+	CreatedAt       time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+
+	RootTask   *Task           `gorm:"foreignKey:RootTaskID;constraint:OnDelete:CASCADE" json:"root_task,omitempty"`
+	Executions []TaskExecution `gorm:"foreignKey:WorkflowRunID;constraint:OnDelete:SET NULL" json:"executions,omitempty"`
+}
+
+func (WorkflowRun) TableName() string {
+	return "workflow_runs"
+}
@@ -0,0 +1,419 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/internal/storage"
+	"github.com/jobs/scheduler/pkg/cronexpr"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidBackfill 补跑区间或并发上限无效
+	ErrInvalidBackfill = errors.New("invalid backfill")
+	// ErrBackfillState 补跑当前状态不允许该操作
+	ErrBackfillState = errors.New("invalid backfill state")
+)
+
+const (
+	// maxBackfillExecutions 单次补跑最多包含的触发次数
+	maxBackfillExecutions = 1000
+	// backfillInterval 领导者推进补跑的间隔，补偿未收到的执行结束通知
+	backfillInterval = 30 * time.Second
+)
+
+// BackfillOptions 补跑参数
+type BackfillOptions struct {
+	StartTime      time.Time
+	EndTime        time.Time
+	MaxConcurrency int                    // 同时未结束的执行数上限，0 表示 1
+	Parameters     map[string]interface{} // 覆盖任务默认参数
+}
+
+// BackfillEngine 历史区间补跑：按触发时间顺序逐步创建执行，已创建的执行结束后继续创建
+type BackfillEngine struct {
+	storage    *storage.Storage
+	taskRunner *TaskRunner
+	dag        *DAGEngine
+	locker     LeaderLock
+	logger     *zap.Logger
+}
+
+// NewBackfillEngine 创建补跑引擎
+func NewBackfillEngine(storage *storage.Storage, taskRunner *TaskRunner, dag *DAGEngine, locker LeaderLock, logger *zap.Logger) *BackfillEngine {
+	return &BackfillEngine{
+		storage:    storage,
+		taskRunner: taskRunner,
+		dag:        dag,
+		locker:     locker,
+		logger:     logger,
+	}
+}
+
+// Create 为任务创建补跑并立即创建并发上限内的执行
+// 区间为闭区间，触发时间按任务当前的表达式和时区计算；参数不满足任务参数 schema 时返回校验错误
+func (b *BackfillEngine) Create(ctx context.Context, taskID string, opts BackfillOptions) (*models.Backfill, error) {
+	var task models.Task
+	if err := b.storage.DB().Where("id = ?", taskID).First(&task).Error; err != nil {
+		return nil, fmt.Errorf("task not found: %w", err)
+	}
+	if task.Status == models.TaskStatusDeleted {
+		return nil, fmt.Errorf("task not found: %w", gorm.ErrRecordNotFound)
+	}
+
+	if opts.EndTime.Before(opts.StartTime) {
+		return nil, fmt.Errorf("%w: end_time is before start_time", ErrInvalidBackfill)
+	}
+	if opts.MaxConcurrency < 0 {
+		return nil, fmt.Errorf("%w: max_concurrency must not be negative", ErrInvalidBackfill)
+	}
+	if opts.MaxConcurrency == 0 {
+		opts.MaxConcurrency = 1
+	}
+
+	params := task.EffectiveParameters(opts.Parameters)
+	if err := task.ValidateParameters(params); err != nil {
+		return nil, err
+	}
+
+	schedule, err := cronexpr.Parse(task.CronExpression, task.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackfill, err)
+	}
+	first, total := backfillTimes(schedule, opts.StartTime, opts.EndTime, maxBackfillExecutions)
+	if total == 0 {
+		return nil, fmt.Errorf("%w: no fire times between %s and %s", ErrInvalidBackfill,
+			opts.StartTime.Format(time.RFC3339), opts.EndTime.Format(time.RFC3339))
+	}
+	if total > maxBackfillExecutions {
+		return nil, fmt.Errorf("%w: more than %d fire times in range", ErrInvalidBackfill, maxBackfillExecutions)
+	}
+
+	backfill := &models.Backfill{
+		ID:             uuid.New().String(),
+		TaskID:         task.ID,
+		StartTime:      opts.StartTime,
+		EndTime:        opts.EndTime,
+		CronExpression: task.CronExpression,
+		Timezone:       task.Timezone,
+		Parameters:     params,
+		MaxConcurrency: opts.MaxConcurrency,
+		Status:         models.BackfillStatusRunning,
+		Total:          total,
+		NextTime:       &first,
+	}
+	if err := b.storage.DB().Create(backfill).Error; err != nil {
+		return nil, fmt.Errorf("failed to create backfill: %w", err)
+	}
+
+	b.logger.Info("backfill created",
+		zap.String("backfill_id", backfill.ID),
+		zap.String("task_id", task.ID),
+		zap.Time("start_time", opts.StartTime),
+		zap.Time("end_time", opts.EndTime),
+		zap.Int("total", total),
+		zap.Int("max_concurrency", opts.MaxConcurrency))
+
+	b.advance(ctx, backfill.ID)
+
+	if err := b.storage.DB().Where("id = ?", backfill.ID).First(backfill).Error; err != nil {
+		return nil, fmt.Errorf("failed to load backfill: %w", err)
+	}
+	return backfill, nil
+}
+
+// backfillTimes 返回 [start, end] 内的第一个触发时间及触发次数，次数超过 limit 时返回 limit+1
+func backfillTimes(schedule cron.Schedule, start, end time.Time, limit int) (time.Time, int) {
+	var first time.Time
+	total := 0
+	// Next 返回严格晚于参数的时间，从 start 前一纳秒开始使 start 本身也被包含
+	for t := schedule.Next(start.Add(-time.Nanosecond)); !t.IsZero() && !t.After(end); t = schedule.Next(t) {
+		if total == 0 {
+			first = t
+		}
+		total++
+		if total > limit {
+			break
+		}
+	}
+	return first, total
+}
+
+// OnExecutionFinished 执行结束后推进该任务上运行中的补跑
+// 不只推进执行所属的补跑：串行任务的其他执行结束后，等待中的补跑才能继续
+func (b *BackfillEngine) OnExecutionFinished(ctx context.Context, execution *models.TaskExecution) {
+	var ids []string
+	if err := b.storage.DB().Model(&models.Backfill{}).
+		Where("task_id = ? AND status = ?", execution.TaskID, models.BackfillStatusRunning).
+		Order("created_at").
+		Pluck("id", &ids).Error; err != nil {
+		b.logger.Error("failed to load running backfills",
+			zap.String("task_id", execution.TaskID),
+			zap.Error(err))
+		return
+	}
+	for _, id := range ids {
+		b.advance(ctx, id)
+	}
+}
+
+// advanceAll 推进所有运行中的补跑
+func (b *BackfillEngine) advanceAll(ctx context.Context) error {
+	var ids []string
+	if err := b.storage.DB().Model(&models.Backfill{}).
+		Where("status = ?", models.BackfillStatusRunning).
+		Order("created_at").
+		Pluck("id", &ids).Error; err != nil {
+		return fmt.Errorf("failed to load running backfills: %w", err)
+	}
+	for _, id := range ids {
+		b.advance(ctx, id)
+	}
+	return nil
+}
+
+// advance 在并发上限内逐个创建执行，没有待创建的触发时间且执行都已结束时汇总补跑状态
+func (b *BackfillEngine) advance(ctx context.Context, backfillID string) {
+	for {
+		created, err := b.createNext(ctx, backfillID)
+		if err != nil {
+			b.logger.Error("failed to advance backfill",
+				zap.String("backfill_id", backfillID),
+				zap.Error(err))
+			return
+		}
+		if !created {
+			return
+		}
+	}
+}
+
+// createNext 为下一个触发时间创建执行，返回是否需要继续尝试
+// 多个实例可能同时推进同一补跑，以 created 作为版本号更新游标，只有一个实例能创建该触发时间的执行
+func (b *BackfillEngine) createNext(ctx context.Context, backfillID string) (bool, error) {
+	var backfill models.Backfill
+	if err := b.storage.DB().Where("id = ?", backfillID).First(&backfill).Error; err != nil {
+		return false, fmt.Errorf("failed to load backfill: %w", err)
+	}
+	if backfill.Status != models.BackfillStatusRunning {
+		return false, nil
+	}
+
+	var task models.Task
+	if err := b.storage.DB().Where("id = ?", backfill.TaskID).First(&task).Error; err != nil {
+		return false, fmt.Errorf("failed to load task: %w", err)
+	}
+	if task.Status == models.TaskStatusDeleted {
+		_, err := b.Cancel(ctx, backfillID)
+		return false, err
+	}
+
+	active, err := b.countActive("backfill_id = ?", backfillID)
+	if err != nil {
+		return false, err
+	}
+	if backfill.NextTime == nil {
+		if active == 0 {
+			return false, b.finish(&backfill)
+		}
+		return false, nil
+	}
+
+	if task.ExecutionMode == models.ExecutionModeParallel {
+		if active >= int64(backfill.MaxConcurrency) {
+			return false, nil
+		}
+	} else {
+		// 串行和跳过模式一次只运行一个执行，等待任务的其他执行结束，不跳过任何触发时间
+		running, err := b.countActive("task_id = ?", task.ID)
+		if err != nil {
+			return false, err
+		}
+		if running > 0 {
+			return false, nil
+		}
+	}
+
+	schedule, err := cronexpr.Parse(backfill.CronExpression, backfill.Timezone)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse cron expression: %w", err)
+	}
+	var following *time.Time
+	if t := schedule.Next(*backfill.NextTime); !t.IsZero() && !t.After(backfill.EndTime) {
+		following = &t
+	}
+
+	execution := &models.TaskExecution{
+		ID:            uuid.New().String(),
+		TaskID:        task.ID,
+		ScheduledTime: *backfill.NextTime,
+		Status:        models.ExecutionStatusPending,
+		Parameters:    backfill.Parameters,
+		BackfillID:    &backfill.ID,
+		FencingToken:  b.locker.FencingToken(),
+	}
+
+	advanced := false
+	err = b.storage.DB().Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Backfill{}).
+			Where("id = ? AND status = ? AND created = ?", backfill.ID, models.BackfillStatusRunning, backfill.Created).
+			Updates(map[string]interface{}{
+				"created":   backfill.Created + 1,
+				"next_time": following,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		advanced = true
+
+		if err := b.dag.BeginRun(ctx, tx, &task, execution); err != nil {
+			return err
+		}
+		return tx.Create(execution).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create backfill execution: %w", err)
+	}
+	if !advanced {
+		// 其他实例已推进游标，重新读取后再判断
+		return true, nil
+	}
+
+	b.taskRunner.Submit(&task, execution)
+
+	b.logger.Debug("backfill execution created",
+		zap.String("backfill_id", backfill.ID),
+		zap.String("execution_id", execution.ID),
+		zap.Time("scheduled_time", execution.ScheduledTime))
+
+	return true, nil
+}
+
+// countActive 统计满足条件的待分发和运行中的执行
+func (b *BackfillEngine) countActive(query string, args ...interface{}) (int64, error) {
+	var count int64
+	if err := b.storage.DB().Model(&models.TaskExecution{}).
+		Where(query, args...).
+		Where("status IN ?", []models.ExecutionStatus{models.ExecutionStatusPending, models.ExecutionStatusRunning}).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count active executions: %w", err)
+	}
+	return count, nil
+}
+
+// finish 所有执行结束后汇总补跑状态：全部成功为 succeeded，否则为 failed
+func (b *BackfillEngine) finish(backfill *models.Backfill) error {
+	var unsucceeded int64
+	if err := b.storage.DB().Model(&models.TaskExecution{}).
+		Where("backfill_id = ? AND status <> ?", backfill.ID, models.ExecutionStatusSuccess).
+		Count(&unsucceeded).Error; err != nil {
+		return fmt.Errorf("failed to count backfill executions: %w", err)
+	}
+
+	status := models.BackfillStatusSucceeded
+	if unsucceeded > 0 {
+		status = models.BackfillStatusFailed
+	}
+
+	result := b.storage.DB().Model(&models.Backfill{}).
+		Where("id = ? AND status = ?", backfill.ID, models.BackfillStatusRunning).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"finished_at": time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update backfill status: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		b.logger.Info("backfill finished",
+			zap.String("backfill_id", backfill.ID),
+			zap.String("status", string(status)),
+			zap.Int("total", backfill.Total))
+	}
+	return nil
+}
+
+// Cancel 取消补跑：不再创建新的执行，待执行的直接取消，运行中的通知执行器停止
+func (b *BackfillEngine) Cancel(ctx context.Context, backfillID string) (*models.Backfill, error) {
+	var backfill models.Backfill
+	if err := b.storage.DB().Where("id = ?", backfillID).First(&backfill).Error; err != nil {
+		return nil, err
+	}
+	if backfill.Status != models.BackfillStatusRunning {
+		return nil, fmt.Errorf("%w: backfill is %s", ErrBackfillState, backfill.Status)
+	}
+
+	now := time.Now()
+	result := b.storage.DB().Model(&models.Backfill{}).
+		Where("id = ? AND status = ?", backfillID, models.BackfillStatusRunning).
+		Updates(map[string]interface{}{
+			"status":      models.BackfillStatusCancelled,
+			"finished_at": now,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update backfill: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: backfill finished concurrently", ErrBackfillState)
+	}
+	backfill.Status = models.BackfillStatusCancelled
+	backfill.FinishedAt = &now
+
+	var active []models.TaskExecution
+	if err := b.storage.DB().
+		Where("backfill_id = ? AND status IN ?", backfillID,
+			[]models.ExecutionStatus{models.ExecutionStatusPending, models.ExecutionStatusRunning}).
+		Find(&active).Error; err != nil {
+		return nil, fmt.Errorf("failed to load active executions: %w", err)
+	}
+
+	// 执行器拒绝停止的执行保持运行，由回调或超时结束；补跑已取消，不会再创建新的执行
+	for i := range active {
+		if _, err := b.taskRunner.CancelExecution(ctx, active[i].ID); err != nil {
+			b.logger.Error("failed to cancel execution",
+				zap.String("backfill_id", backfillID),
+				zap.String("execution_id", active[i].ID),
+				zap.Error(err))
+		}
+	}
+
+	b.logger.Info("backfill cancelled",
+		zap.String("backfill_id", backfillID),
+		zap.Int("created", backfill.Created),
+		zap.Int("total", backfill.Total),
+		zap.Int("active_executions", len(active)))
+
+	return &backfill, nil
+}
+
+// backfillLoop 领导者定期推进运行中的补跑，补偿在其他实例上丢失的执行结束通知
+func (s *Scheduler) backfillLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(backfillInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !s.isLeader.Load() {
+				continue
+			}
+			if err := s.backfills.advanceAll(context.Background()); err != nil {
+				s.logger.Error("failed to advance backfills", zap.Error(err))
+			}
+		case <-s.stopCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// finishExecution 将执行置为终态并通知补跑引擎
+func finishExecution(t *testing.T, s *Scheduler, id string, status models.ExecutionStatus) {
+	t.Helper()
+	var execution models.TaskExecution
+	require.NoError(t, s.storage.DB().Where("id = ?", id).First(&execution).Error)
+	require.NoError(t, s.storage.DB().Model(&execution).Update("status", status).Error)
+	execution.Status = status
+	s.backfills.OnExecutionFinished(context.Background(), &execution)
+}
+
+func backfillExecutions(t *testing.T, s *Scheduler, backfillID string) []models.TaskExecution {
+	t.Helper()
+	var executions []models.TaskExecution
+	require.NoError(t, s.storage.DB().
+		Where("backfill_id = ?", backfillID).
+		Order("scheduled_time").
+		Find(&executions).Error)
+	return executions
+}
+
+func TestBackfillRespectsConcurrencyAndCancels(t *testing.T) {
+	s, st := newManualScheduler(t)
+	ctx := context.Background()
+
+	task := models.Task{
+		ID:             "task-1",
+		Name:           "hourly",
+		CronExpression: "0 0 * * * *",
+		Timezone:       "UTC",
+		Parameters:     models.JSONMap{"date": `{{ .ScheduledTime | date "2006-01-02T15" }}`},
+	}
+	require.NoError(t, st.DB().Create(&task).Error)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	backfill, err := s.backfills.Create(ctx, task.ID, BackfillOptions{
+		StartTime:      start,
+		EndTime:        start.Add(4 * time.Hour),
+		MaxConcurrency: 2,
+	})
+	require.NoError(t, err)
+	// 区间两端都包含在内
+	assert.Equal(t, 5, backfill.Total)
+	assert.Equal(t, 2, backfill.Created)
+
+	executions := backfillExecutions(t, s, backfill.ID)
+	require.Len(t, executions, 2)
+	assert.True(t, start.Equal(executions[0].ScheduledTime))
+	assert.True(t, start.Add(time.Hour).Equal(executions[1].ScheduledTime))
+	assert.Equal(t, task.Parameters, executions[0].Parameters)
+
+	// 一个执行结束后补上下一个触发时间
+	finishExecution(t, s, executions[0].ID, models.ExecutionStatusSuccess)
+	executions = backfillExecutions(t, s, backfill.ID)
+	require.Len(t, executions, 3)
+	assert.True(t, start.Add(2*time.Hour).Equal(executions[2].ScheduledTime))
+
+	cancelled, err := s.backfills.Cancel(ctx, backfill.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.BackfillStatusCancelled, cancelled.Status)
+
+	executions = backfillExecutions(t, s, backfill.ID)
+	require.Len(t, executions, 3)
+	assert.Equal(t, models.ExecutionStatusSuccess, executions[0].Status)
+	assert.Equal(t, models.ExecutionStatusCancelled, executions[1].Status)
+	assert.Equal(t, models.ExecutionStatusCancelled, executions[2].Status)
+
+	_, err = s.backfills.Cancel(ctx, backfill.ID)
+	assert.ErrorIs(t, err, ErrBackfillState)
+}
+
+func TestBackfillSequentialWaitsForTask(t *testing.T) {
+	s, st := newManualScheduler(t)
+	ctx := context.Background()
+
+	task := models.Task{
+		ID:             "task-1",
+		Name:           "daily",
+		CronExpression: "0 0 2 * * *",
+		Timezone:       "Asia/Shanghai",
+		ExecutionMode:  models.ExecutionModeSequential,
+	}
+	require.NoError(t, st.DB().Create(&task).Error)
+
+	// 任务已有运行中的执行时，补跑等待其结束
+	running := models.TaskExecution{ID: "cron-1", TaskID: task.ID, ScheduledTime: time.Now(), Status: models.ExecutionStatusRunning}
+	require.NoError(t, st.DB().Create(&running).Error)
+
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	backfill, err := s.backfills.Create(ctx, task.ID, BackfillOptions{
+		StartTime:      time.Date(2024, 3, 1, 0, 0, 0, 0, shanghai),
+		EndTime:        time.Date(2024, 3, 3, 23, 59, 59, 0, shanghai),
+		MaxConcurrency: 3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, backfill.Total)
+	assert.Equal(t, 0, backfill.Created)
+
+	finishExecution(t, s, running.ID, models.ExecutionStatusSuccess)
+	executions := backfillExecutions(t, s, backfill.ID)
+	require.Len(t, executions, 1)
+	assert.True(t, time.Date(2024, 3, 1, 2, 0, 0, 0, shanghai).Equal(executions[0].ScheduledTime))
+
+	finishExecution(t, s, executions[0].ID, models.ExecutionStatusFailed)
+	executions = backfillExecutions(t, s, backfill.ID)
+	require.Len(t, executions, 2)
+	finishExecution(t, s, executions[1].ID, models.ExecutionStatusSuccess)
+	executions = backfillExecutions(t, s, backfill.ID)
+	require.Len(t, executions, 3)
+	finishExecution(t, s, executions[2].ID, models.ExecutionStatusSuccess)
+
+	var finished models.Backfill
+	require.NoError(t, st.DB().Where("id = ?", backfill.ID).First(&finished).Error)
+	assert.Equal(t, models.BackfillStatusFailed, finished.Status)
+	assert.Equal(t, 3, finished.Created)
+	assert.Nil(t, finished.NextTime)
+	assert.NotNil(t, finished.FinishedAt)
+}
+
+func TestBackfillRejectsInvalidRange(t *testing.T) {
+	s, st := newManualScheduler(t)
+	ctx := context.Background()
+
+	task := models.Task{ID: "task-1", Name: "task-1", CronExpression: "0 0 2 * * *", Timezone: "UTC"}
+	require.NoError(t, st.DB().Create(&task).Error)
+
+	start := time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)
+	for name, opts := range map[string]BackfillOptions{
+		"reversed": {StartTime: start, EndTime: start.Add(-time.Hour)},
+		"empty":    {StartTime: start, EndTime: start.Add(time.Hour)},
+		"too many": {StartTime: start, EndTime: start.AddDate(3, 0, 0)},
+		"negative": {StartTime: start, EndTime: start.AddDate(0, 0, 1), MaxConcurrency: -1},
+	} {
+		_, err := s.backfills.Create(ctx, task.ID, opts)
+		assert.ErrorIs(t, err, ErrInvalidBackfill, name)
+	}
+
+	var count int64
+	st.DB().Model(&models.Backfill{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
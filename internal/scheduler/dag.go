@@ -0,0 +1,537 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/internal/storage"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrDependencyCycle 依赖关系成环
+	ErrDependencyCycle = errors.New("dependency cycle detected")
+	// ErrInvalidDependency 依赖关系不合法（重复或上游不存在）
+	ErrInvalidDependency = errors.New("invalid dependency")
+)
+
+// DAGEngine 任务依赖引擎，上游执行进入终态后触发下游任务
+type DAGEngine struct {
+	mu         sync.Mutex
+	storage    *storage.Storage
+	taskRunner *TaskRunner
+	logger     *zap.Logger
+}
+
+// NewDAGEngine 创建任务依赖引擎
+func NewDAGEngine(storage *storage.Storage, taskRunner *TaskRunner, logger *zap.Logger) *DAGEngine {
+	return &DAGEngine{
+		storage:    storage,
+		taskRunner: taskRunner,
+		logger:     logger,
+	}
+}
+
+// GetUpstream 获取任务的上游依赖
+func (d *DAGEngine) GetUpstream(ctx context.Context, taskID string) ([]models.TaskDependency, error) {
+	var deps []models.TaskDependency
+	if err := d.storage.DB().
+		Preload("DependsOnTask").
+		Where("task_id = ?", taskID).
+		Find(&deps).Error; err != nil {
+		return nil, fmt.Errorf("failed to load upstream dependencies: %w", err)
+	}
+	return deps, nil
+}
+
+// GetDownstream 获取依赖该任务的下游
+func (d *DAGEngine) GetDownstream(ctx context.Context, taskID string) ([]models.TaskDependency, error) {
+	var deps []models.TaskDependency
+	if err := d.storage.DB().
+		Preload("Task").
+		Where("depends_on_task_id = ?", taskID).
+		Find(&deps).Error; err != nil {
+		return nil, fmt.Errorf("failed to load downstream dependencies: %w", err)
+	}
+	return deps, nil
+}
+
+// ValidateDependencies 校验把 taskID 的上游替换为 upstreamIDs 后依赖图是否合法
+func (d *DAGEngine) ValidateDependencies(ctx context.Context, taskID string, upstreamIDs []string) error {
+	return validateDependencies(d.storage.DB(), taskID, upstreamIDs)
+}
+
+// validateDependencies 在 db（可以是事务）中校验依赖，使校验能看到同一事务内尚未提交的任务
+func validateDependencies(db *gorm.DB, taskID string, upstreamIDs []string) error {
+	seen := make(map[string]bool, len(upstreamIDs))
+	for _, id := range upstreamIDs {
+		if id == taskID {
+			return fmt.Errorf("%w: task cannot depend on itself", ErrDependencyCycle)
+		}
+		if seen[id] {
+			return fmt.Errorf("%w: duplicate dependency on task %s", ErrInvalidDependency, id)
+		}
+		seen[id] = true
+	}
+
+	if len(upstreamIDs) > 0 {
+		var count int64
+		if err := db.
+			Model(&models.Task{}).
+			Where("id IN ? AND status <> ?", upstreamIDs, models.TaskStatusDeleted).
+			Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check upstream tasks: %w", err)
+		}
+		if int(count) != len(upstreamIDs) {
+			return fmt.Errorf("%w: upstream task not found", ErrInvalidDependency)
+		}
+	}
+
+	var edges []models.TaskDependency
+	if err := db.Find(&edges).Error; err != nil {
+		return fmt.Errorf("failed to load dependencies: %w", err)
+	}
+
+	graph := make(map[string][]string)
+	for _, e := range edges {
+		if e.TaskID == taskID {
+			continue
+		}
+		graph[e.TaskID] = append(graph[e.TaskID], e.DependsOnTaskID)
+	}
+	graph[taskID] = upstreamIDs
+
+	if path := findCycle(graph, taskID); path != nil {
+		return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(path, " -> "))
+	}
+	return nil
+}
+
+// ValidateGraph 校验完整的依赖图（节点 -> 上游节点）无环，用于一次性替换多个任务的依赖
+func ValidateGraph(graph map[string][]string) error {
+	nodes := make([]string, 0, len(graph))
+	for node := range graph {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	for _, node := range nodes {
+		if path := findCycle(graph, node); path != nil {
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(path, " -> "))
+		}
+	}
+	return nil
+}
+
+// findCycle 从 start 沿上游方向深度优先搜索，找到回到 start 的路径则返回该环
+func findCycle(graph map[string][]string, start string) []string {
+	visited := make(map[string]bool)
+	var path []string
+
+	var dfs func(node string) bool
+	dfs = func(node string) bool {
+		path = append(path, node)
+		for _, next := range graph[node] {
+			if next == start {
+				path = append(path, start)
+				return true
+			}
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+			if dfs(next) {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+
+	if dfs(start) {
+		return path
+	}
+	return nil
+}
+
+// SetDependencies 替换任务的全部上游依赖
+func (d *DAGEngine) SetDependencies(ctx context.Context, taskID string, deps []models.TaskDependency) ([]models.TaskDependency, error) {
+	var saved []models.TaskDependency
+	err := d.storage.DB().Transaction(func(tx *gorm.DB) error {
+		var err error
+		saved, err = d.ReplaceDependencies(tx, taskID, deps)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// ReplaceDependencies 在调用方的事务内校验并替换任务的全部上游依赖，用于与任务本身的创建或修改一起提交
+// 依赖不合法时返回 ErrDependencyCycle 或 ErrInvalidDependency，调用方回滚事务即可撤销任务的修改
+func (d *DAGEngine) ReplaceDependencies(tx *gorm.DB, taskID string, deps []models.TaskDependency) ([]models.TaskDependency, error) {
+	upstreamIDs := make([]string, 0, len(deps))
+	for i := range deps {
+		upstreamIDs = append(upstreamIDs, deps[i].DependsOnTaskID)
+		deps[i].ID = uuid.New().String()
+		deps[i].TaskID = taskID
+		if deps[i].FailurePolicy == "" {
+			deps[i].FailurePolicy = models.DependencyPolicySkip
+		}
+	}
+
+	if err := validateDependencies(tx, taskID, upstreamIDs); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("task_id = ?", taskID).Delete(&models.TaskDependency{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to save dependencies: %w", err)
+	}
+	if len(deps) > 0 {
+		if err := tx.Create(&deps).Error; err != nil {
+			return nil, fmt.Errorf("failed to save dependencies: %w", err)
+		}
+	}
+	if err := TouchTask(tx, taskID); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+// TouchTask 依赖变化后更新任务的 updated_at，使领导者的对账轮询重新判定是否为任务注册cron条目
+func TouchTask(db *gorm.DB, taskID string) error {
+	if err := db.Model(&models.Task{}).
+		Where("id = ?", taskID).
+		Update("updated_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to touch task: %w", err)
+	}
+	return nil
+}
+
+// AddDependency 为任务新增一个上游依赖
+func (d *DAGEngine) AddDependency(ctx context.Context, taskID, upstreamID string, policy models.DependencyFailurePolicy) (*models.TaskDependency, error) {
+	existing, err := d.GetUpstream(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	upstreamIDs := make([]string, 0, len(existing)+1)
+	for _, e := range existing {
+		upstreamIDs = append(upstreamIDs, e.DependsOnTaskID)
+	}
+	upstreamIDs = append(upstreamIDs, upstreamID)
+
+	if err := d.ValidateDependencies(ctx, taskID, upstreamIDs); err != nil {
+		return nil, err
+	}
+
+	if policy == "" {
+		policy = models.DependencyPolicySkip
+	}
+	dep := &models.TaskDependency{
+		ID:              uuid.New().String(),
+		TaskID:          taskID,
+		DependsOnTaskID: upstreamID,
+		FailurePolicy:   policy,
+	}
+	err = d.storage.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(dep).Error; err != nil {
+			return fmt.Errorf("failed to create dependency: %w", err)
+		}
+		return TouchTask(tx, taskID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dep, nil
+}
+
+// RemoveDependency 删除任务的一个上游依赖
+func (d *DAGEngine) RemoveDependency(ctx context.Context, taskID, upstreamID string) error {
+	return d.storage.DB().Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Where("task_id = ? AND depends_on_task_id = ?", taskID, upstreamID).
+			Delete(&models.TaskDependency{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete dependency: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return TouchTask(tx, taskID)
+	})
+}
+
+// OnExecutionFinished 上游执行进入终态后评估其下游任务
+func (d *DAGEngine) OnExecutionFinished(ctx context.Context, execution *models.TaskExecution) {
+	if !execution.Status.IsTerminal() {
+		return
+	}
+
+	var run *models.WorkflowRun
+	if execution.WorkflowRunID != nil {
+		var r models.WorkflowRun
+		if err := d.storage.DB().Where("id = ?", *execution.WorkflowRunID).First(&r).Error; err != nil {
+			d.logger.Error("failed to load workflow run",
+				zap.String("workflow_run_id", *execution.WorkflowRunID),
+				zap.Error(err))
+		} else {
+			run = &r
+		}
+	}
+
+	// 已取消的运行不再派生新的执行
+	if run != nil && run.Status == models.WorkflowRunStatusCancelled {
+		return
+	}
+
+	var edges []models.TaskDependency
+	if err := d.storage.DB().
+		Where("depends_on_task_id = ?", execution.TaskID).
+		Find(&edges).Error; err != nil {
+		d.logger.Error("failed to load downstream dependencies",
+			zap.String("task_id", execution.TaskID),
+			zap.Error(err))
+		return
+	}
+
+	for _, edge := range edges {
+		if err := d.evaluate(ctx, edge.TaskID, execution, run); err != nil {
+			d.logger.Error("failed to evaluate downstream task",
+				zap.String("upstream_task_id", execution.TaskID),
+				zap.String("task_id", edge.TaskID),
+				zap.Error(err))
+		}
+	}
+
+	if run != nil {
+		d.refreshRunStatus(run.ID)
+	}
+}
+
+// evaluate 检查下游任务的所有上游是否都已完成，并按策略决定运行、跳过或失败
+func (d *DAGEngine) evaluate(ctx context.Context, taskID string, trigger *models.TaskExecution, run *models.WorkflowRun) error {
+	task, execution, err := d.resolve(taskID, trigger, run)
+	if err != nil || execution == nil {
+		return err
+	}
+
+	if execution.Status == models.ExecutionStatusPending {
+		d.taskRunner.Submit(task, execution)
+		return nil
+	}
+
+	// 跳过/失败继续向后代传播
+	d.OnExecutionFinished(ctx, execution)
+	return nil
+}
+
+// resolve 在事务内判定下游是否就绪并创建执行记录，未就绪或已被触发时返回nil
+// 事务先锁住下游任务行，多个调度实例同时评估同一下游时依次判定，后到者能看到先到者创建的执行
+func (d *DAGEngine) resolve(taskID string, trigger *models.TaskExecution, run *models.WorkflowRun) (*models.Task, *models.TaskExecution, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var task models.Task
+	var execution *models.TaskExecution
+	err := d.storage.DB().Transaction(func(tx *gorm.DB) error {
+		// SQLite 没有行锁，写事务本身互斥
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", taskID).First(&task).Error; err != nil {
+			return fmt.Errorf("task not found: %w", err)
+		}
+		if task.Status != models.TaskStatusActive {
+			d.logger.Info("downstream task is not active, not triggering",
+				zap.String("task_id", task.ID),
+				zap.String("status", string(task.Status)))
+			return nil
+		}
+
+		var err error
+		execution, err = d.decide(tx, &task, trigger, run)
+		if err != nil || execution == nil {
+			return err
+		}
+		if err := tx.Create(execution).Error; err != nil {
+			return fmt.Errorf("failed to create execution record: %w", err)
+		}
+		return nil
+	})
+	if err != nil || execution == nil {
+		return nil, nil, err
+	}
+
+	d.logger.Info("dependency resolved for downstream task",
+		zap.String("task_id", task.ID),
+		zap.String("execution_id", execution.ID),
+		zap.String("status", string(execution.Status)))
+
+	return &task, execution, nil
+}
+
+// decide 按上游状态、失败策略和执行模式构造下游执行，未就绪或已被触发时返回nil
+func (d *DAGEngine) decide(tx *gorm.DB, task *models.Task, trigger *models.TaskExecution, run *models.WorkflowRun) (*models.TaskExecution, error) {
+	var upstream []models.TaskDependency
+	if err := tx.Where("task_id = ?", task.ID).Find(&upstream).Error; err != nil {
+		return nil, fmt.Errorf("failed to load upstream dependencies: %w", err)
+	}
+
+	triggeredAt := time.Now()
+	if trigger.EndTime != nil {
+		triggeredAt = *trigger.EndTime
+	}
+
+	// 同一运行内：本次上游结束后下游已有执行则说明已被触发过
+	// 无运行关联：下游上一次执行之后，每个上游都必须有一次新的终态执行
+	var since time.Time
+	last, err := latestExecution(tx, task.ID, run)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil {
+		if run != nil && !last.CreatedAt.Before(triggeredAt) {
+			return nil, nil
+		}
+		if run == nil {
+			since = last.CreatedAt
+		}
+	}
+
+	var reachable map[string]bool
+	if run != nil {
+		if reachable, err = descendants(tx, run.RootTaskID); err != nil {
+			return nil, err
+		}
+	}
+
+	decision := models.DependencyPolicyRun
+	var reasons []string
+	for _, dep := range upstream {
+		latest, err := latestExecution(tx, dep.DependsOnTaskID, run)
+		if err != nil {
+			return nil, err
+		}
+		if latest == nil && run != nil {
+			// 上游属于本次运行但尚未执行，继续等待
+			if reachable[dep.DependsOnTaskID] {
+				return nil, nil
+			}
+			// 上游不在本次运行的依赖链上，退回到其最近一次执行
+			if latest, err = latestExecution(tx, dep.DependsOnTaskID, nil); err != nil {
+				return nil, err
+			}
+		}
+		if latest == nil || !latest.Status.IsTerminal() || latest.EndTime == nil || latest.EndTime.Before(since) {
+			return nil, nil
+		}
+		if latest.Status == models.ExecutionStatusSuccess {
+			continue
+		}
+
+		reasons = append(reasons, fmt.Sprintf("upstream task %s ended with %s", dep.DependsOnTaskID, latest.Status))
+		switch dep.FailurePolicy {
+		case models.DependencyPolicyFail:
+			decision = models.DependencyPolicyFail
+		case models.DependencyPolicySkip:
+			if decision != models.DependencyPolicyFail {
+				decision = models.DependencyPolicySkip
+			}
+		}
+	}
+
+	now := time.Now()
+	execution := &models.TaskExecution{
+		ID:                uuid.New().String(),
+		TaskID:            task.ID,
+		ScheduledTime:     now,
+		Status:            models.ExecutionStatusPending,
+		WorkflowRunID:     trigger.WorkflowRunID,
+		ParentExecutionID: &trigger.ID,
+		FencingToken:      trigger.FencingToken, // 下游执行沿用触发它的上游执行的令牌
+		Parameters:        task.EffectiveParameters(nil),
+	}
+
+	switch decision {
+	case models.DependencyPolicySkip:
+		execution.Status = models.ExecutionStatusSkipped
+	case models.DependencyPolicyFail:
+		execution.Status = models.ExecutionStatusFailed
+	}
+
+	// 与定时触发一致：串行/跳过模式下任务仍有未结束的执行时不再运行，
+	// 记录为跳过以便运行状态收敛并继续向后代传播
+	if execution.Status == models.ExecutionStatusPending && task.ExecutionMode != models.ExecutionModeParallel {
+		var active int64
+		if err := tx.Model(&models.TaskExecution{}).
+			Where("task_id = ? AND status IN ?", task.ID,
+				[]models.ExecutionStatus{models.ExecutionStatusPending, models.ExecutionStatusRunning}).
+			Count(&active).Error; err != nil {
+			return nil, err
+		}
+		if active > 0 {
+			execution.Status = models.ExecutionStatusSkipped
+			reasons = append(reasons, "Skipped due to execution mode")
+		}
+	}
+
+	if execution.Status != models.ExecutionStatusPending {
+		execution.EndTime = &now
+		execution.Logs = strings.Join(reasons, "; ")
+	}
+	return execution, nil
+}
+
+// latestExecution 获取任务最近一次执行，run不为空时限定在该运行内
+func latestExecution(db *gorm.DB, taskID string, run *models.WorkflowRun) (*models.TaskExecution, error) {
+	query := db.Where("task_id = ?", taskID)
+	if run != nil {
+		query = query.Where("workflow_run_id = ?", run.ID)
+	}
+
+	var execution models.TaskExecution
+	err := query.Order("created_at DESC").First(&execution).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load latest execution: %w", err)
+	}
+	return &execution, nil
+}
+
+// descendants 获取从 rootTaskID 出发沿依赖可达的全部下游任务
+func descendants(db *gorm.DB, rootTaskID string) (map[string]bool, error) {
+	var edges []models.TaskDependency
+	if err := db.Find(&edges).Error; err != nil {
+		return nil, fmt.Errorf("failed to load dependencies: %w", err)
+	}
+
+	children := make(map[string][]string)
+	for _, e := range edges {
+		children[e.DependsOnTaskID] = append(children[e.DependsOnTaskID], e.TaskID)
+	}
+
+	result := make(map[string]bool)
+	queue := []string{rootTaskID}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, child := range children[node] {
+			if !result[child] {
+				result[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,284 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestFindCycle(t *testing.T) {
+	// 键为下游任务，值为其上游任务
+	graph := map[string][]string{
+		"b": {"a"},
+		"c": {"b"},
+		"d": {"b", "c"},
+	}
+
+	assert.Nil(t, findCycle(graph, "d"))
+
+	// a 依赖 d 将形成 a -> d -> b -> a
+	graph["a"] = []string{"d"}
+	path := findCycle(graph, "a")
+	assert.Equal(t, "a", path[0])
+	assert.Equal(t, "a", path[len(path)-1])
+	assert.Contains(t, path, "d")
+
+	// 自依赖
+	assert.NotNil(t, findCycle(map[string][]string{"x": {"x"}}, "x"))
+}
+
+// dependOn 为 taskID 添加上游依赖
+func dependOn(t *testing.T, s *Scheduler, taskID, upstreamID string, policy models.DependencyFailurePolicy) {
+	t.Helper()
+	_, err := s.dag.AddDependency(context.Background(), taskID, upstreamID, policy)
+	require.NoError(t, err)
+}
+
+// latestOf 获取任务最近一次执行，没有执行时返回nil
+func latestOf(t *testing.T, s *Scheduler, taskID string) *models.TaskExecution {
+	t.Helper()
+	execution, err := latestExecution(s.storage.DB(), taskID, nil)
+	require.NoError(t, err)
+	return execution
+}
+
+func TestDAGPropagatesUpstreamFailure(t *testing.T) {
+	s, st := newManualScheduler(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		require.NoError(t, st.DB().Create(&models.Task{ID: id, Name: id, CronExpression: "0 0 * * * *"}).Error)
+	}
+	// a -> b(skip) -> c(fail)，a -> d(run)，e 同时依赖 a 和从未执行过的 f
+	dependOn(t, s, "b", "a", models.DependencyPolicySkip)
+	dependOn(t, s, "c", "b", models.DependencyPolicyFail)
+	dependOn(t, s, "d", "a", models.DependencyPolicyRun)
+	dependOn(t, s, "e", "a", models.DependencyPolicyRun)
+	dependOn(t, s, "e", "f", models.DependencyPolicyRun)
+
+	now := time.Now()
+	upstream := models.TaskExecution{ID: "exec-a", TaskID: "a", ScheduledTime: now, Status: models.ExecutionStatusFailed, EndTime: &now}
+	require.NoError(t, st.DB().Create(&upstream).Error)
+	s.dag.OnExecutionFinished(ctx, &upstream)
+
+	b := latestOf(t, s, "b")
+	require.NotNil(t, b)
+	assert.Equal(t, models.ExecutionStatusSkipped, b.Status)
+	assert.Contains(t, b.Logs, "upstream task a ended with failed")
+	require.NotNil(t, b.ParentExecutionID)
+	assert.Equal(t, upstream.ID, *b.ParentExecutionID)
+
+	// 跳过的 b 继续向 c 传播，c 的策略为 fail
+	c := latestOf(t, s, "c")
+	require.NotNil(t, c)
+	assert.Equal(t, models.ExecutionStatusFailed, c.Status)
+	require.NotNil(t, c.ParentExecutionID)
+	assert.Equal(t, b.ID, *c.ParentExecutionID)
+
+	// run 策略忽略上游失败，照常排队
+	d := latestOf(t, s, "d")
+	require.NotNil(t, d)
+	assert.Equal(t, models.ExecutionStatusPending, d.Status)
+
+	// 还有上游未执行时继续等待
+	assert.Nil(t, latestOf(t, s, "e"))
+
+	// 重复通知同一个上游执行不会再次触发下游
+	s.dag.OnExecutionFinished(ctx, &upstream)
+	var count int64
+	st.DB().Model(&models.TaskExecution{}).Where("task_id IN ?", []string{"b", "c", "d"}).Count(&count)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestDAGRunsDownstreamAfterAllUpstreamSucceed(t *testing.T) {
+	s, st := newManualScheduler(t)
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, st.DB().Create(&models.Task{ID: id, Name: id, CronExpression: "0 0 * * * *"}).Error)
+	}
+	dependOn(t, s, "c", "a", "")
+	dependOn(t, s, "c", "b", "")
+
+	finish := func(id, taskID string) *models.TaskExecution {
+		end := time.Now()
+		execution := &models.TaskExecution{ID: id, TaskID: taskID, ScheduledTime: end, Status: models.ExecutionStatusSuccess, EndTime: &end}
+		require.NoError(t, st.DB().Create(execution).Error)
+		s.dag.OnExecutionFinished(ctx, execution)
+		return execution
+	}
+
+	finish("exec-a", "a")
+	assert.Nil(t, latestOf(t, s, "c"))
+
+	b := finish("exec-b", "b")
+	c := latestOf(t, s, "c")
+	require.NotNil(t, c)
+	assert.Equal(t, models.ExecutionStatusPending, c.Status)
+	assert.Equal(t, b.ID, *c.ParentExecutionID)
+}
+
+func TestReplaceDependenciesRollsBackWithTask(t *testing.T) {
+	s, st := newManualScheduler(t)
+
+	// 与创建任务接口相同：任务和依赖在同一事务中写入
+	err := st.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.Task{ID: "new", Name: "new", CronExpression: "0 0 * * * *"}).Error; err != nil {
+			return err
+		}
+		_, err := s.dag.ReplaceDependencies(tx, "new", []models.TaskDependency{{DependsOnTaskID: "missing"}})
+		return err
+	})
+	assert.ErrorIs(t, err, ErrInvalidDependency)
+
+	var count int64
+	st.DB().Model(&models.Task{}).Where("id = ?", "new").Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	require.NoError(t, st.DB().Create(&models.Task{ID: "up", Name: "up", CronExpression: "0 0 * * * *"}).Error)
+	deps, err := s.dag.SetDependencies(context.Background(), "up", []models.TaskDependency{{DependsOnTaskID: "up"}})
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+	assert.Nil(t, deps)
+}
+
+func TestDependentTaskNotScheduledByCron(t *testing.T) {
+	s, st := newManualScheduler(t)
+	s.cron = cron.New()
+	s.entries = make(map[string]cronEntry)
+	s.isLeader.Store(true)
+	locked, err := s.locker.TryLock(context.Background())
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	for _, id := range []string{"a", "b"} {
+		require.NoError(t, st.DB().Create(&models.Task{ID: id, Name: id, CronExpression: "0 * * * * *"}).Error)
+	}
+	require.NoError(t, s.reconcileTasks())
+	assert.Contains(t, s.entries, "b")
+
+	// 新增依赖后条目由对账轮询移除
+	dependOn(t, s, "b", "a", "")
+	require.NoError(t, s.syncChangedTasks())
+	assert.Contains(t, s.entries, "a")
+	assert.NotContains(t, s.entries, "b")
+
+	// 变更同步前已注册的条目到点触发时只对齐条目，不创建执行
+	s.entries["b"] = cronEntry{id: s.cron.Schedule(cron.Every(time.Hour), cron.FuncJob(func() {})), spec: "0 * * * * *"}
+	s.fireTask("b")
+	assert.NotContains(t, s.entries, "b")
+	assert.Nil(t, latestOf(t, s, "b"))
+
+	// 有依赖的任务不做错过触发的补偿
+	last := time.Now().Add(-time.Hour)
+	require.NoError(t, st.DB().Model(&models.Task{}).Where("id = ?", "b").Updates(map[string]interface{}{
+		"misfire_policy":      models.MisfirePolicyFireOnce,
+		"last_scheduled_time": last,
+	}).Error)
+	require.NoError(t, s.recoverMisfires())
+	assert.Nil(t, latestOf(t, s, "b"))
+
+	// 上游执行成功后才触发
+	s.fireTask("a")
+	a := latestOf(t, s, "a")
+	require.NotNil(t, a)
+	assert.Nil(t, latestOf(t, s, "b"))
+	endExecution(t, s, a, models.ExecutionStatusSuccess)
+	b := latestOf(t, s, "b")
+	require.NotNil(t, b)
+	assert.Equal(t, a.ID, *b.ParentExecutionID)
+
+	// 移除依赖后重新按cron调度
+	require.NoError(t, s.dag.RemoveDependency(context.Background(), "b", "a"))
+	require.NoError(t, s.SyncTask("b"))
+	assert.Contains(t, s.entries, "b")
+}
+
+func TestDownstreamTriggeredOnceAcrossInstances(t *testing.T) {
+	s, st := newManualScheduler(t)
+	for _, id := range []string{"a", "b"} {
+		require.NoError(t, st.DB().Create(&models.Task{ID: id, Name: id, CronExpression: "0 0 * * * *"}).Error)
+	}
+	dependOn(t, s, "b", "a", "")
+
+	root := startRun(t, s, "a")
+	now := time.Now()
+	require.NoError(t, st.DB().Model(&models.TaskExecution{}).Where("id = ?", root.ID).
+		Updates(map[string]interface{}{"status": models.ExecutionStatusSuccess, "end_time": now}).Error)
+	root.Status = models.ExecutionStatusSuccess
+	root.EndTime = &now
+	run := loadRun(t, s, *root.WorkflowRunID)
+
+	// 模拟多个调度实例，各自的进程内锁互不可见
+	engines := make([]*DAGEngine, 4)
+	for i := range engines {
+		engines[i] = NewDAGEngine(st, s.taskRunner, zap.NewNop())
+	}
+
+	var wg sync.WaitGroup
+	var created atomic.Int32
+	for _, engine := range engines {
+		wg.Add(1)
+		go func(engine *DAGEngine) {
+			defer wg.Done()
+			_, execution, err := engine.resolve("b", root, &run)
+			assert.NoError(t, err)
+			if execution != nil {
+				created.Add(1)
+			}
+		}(engine)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), created.Load())
+	var count int64
+	st.DB().Model(&models.TaskExecution{}).Where("task_id = ?", "b").Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestDownstreamHonorsExecutionMode(t *testing.T) {
+	s, st := newManualScheduler(t)
+	ctx := context.Background()
+
+	require.NoError(t, st.DB().Create(&models.Task{ID: "a", Name: "a", CronExpression: "0 0 * * * *"}).Error)
+	for _, task := range []models.Task{
+		{ID: "skip", Name: "skip", CronExpression: "0 0 * * * *", ExecutionMode: models.ExecutionModeSkip},
+		{ID: "seq", Name: "seq", CronExpression: "0 0 * * * *", ExecutionMode: models.ExecutionModeSequential},
+		{ID: "par", Name: "par", CronExpression: "0 0 * * * *", ExecutionMode: models.ExecutionModeParallel},
+	} {
+		require.NoError(t, st.DB().Create(&task).Error)
+		dependOn(t, s, task.ID, "a", "")
+		// 每个下游都还有一次未结束的执行
+		require.NoError(t, st.DB().Create(&models.TaskExecution{
+			ID: "running-" + task.ID, TaskID: task.ID, ScheduledTime: time.Now(), Status: models.ExecutionStatusRunning,
+		}).Error)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	now := time.Now()
+	upstream := models.TaskExecution{ID: "exec-a", TaskID: "a", ScheduledTime: now, Status: models.ExecutionStatusSuccess, EndTime: &now}
+	require.NoError(t, st.DB().Create(&upstream).Error)
+	s.dag.OnExecutionFinished(ctx, &upstream)
+
+	for _, id := range []string{"skip", "seq"} {
+		execution := latestOf(t, s, id)
+		require.NotNil(t, execution)
+		assert.Equal(t, models.ExecutionStatusSkipped, execution.Status, id)
+		assert.Contains(t, execution.Logs, "Skipped due to execution mode", id)
+		require.NotNil(t, execution.ParentExecutionID)
+		assert.Equal(t, upstream.ID, *execution.ParentExecutionID)
+	}
+
+	// 并行模式不受未结束执行影响
+	execution := latestOf(t, s, "par")
+	require.NotNil(t, execution)
+	assert.Equal(t, models.ExecutionStatusPending, execution.Status)
+}
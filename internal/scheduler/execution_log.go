@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jobs/scheduler/internal/executor"
+	"github.com/jobs/scheduler/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrLogChunkTooLarge 单次追加的日志超过 log_chunk_max_bytes
+var ErrLogChunkTooLarge = errors.New("log chunk too large")
+
+// AppendLog 追加执行器上报的日志块
+// 执行结束后短时间内仍可能收到执行器缓冲的日志，因此除待分发外的状态都接受追加；
+// 同一次尝试内重复的序号视为执行器重试，其他尝试（attempt 不符）的日志块视为重复，都直接返回成功
+func (r *TaskRunner) AppendLog(ctx context.Context, executionID string, req executor.ExecutionLogRequest) error {
+	if len(req.Content) > r.logChunkMaxBytes {
+		return fmt.Errorf("%w: %d bytes exceeds the limit of %d bytes", ErrLogChunkTooLarge, len(req.Content), r.logChunkMaxBytes)
+	}
+
+	var execution models.TaskExecution
+	if err := r.storage.DB().Select("id", "status", "retry_count").Where("id = ?", executionID).First(&execution).Error; err != nil {
+		return fmt.Errorf("execution not found: %w", err)
+	}
+	if !currentAttempt(&execution, req.Attempt) {
+		r.logger.Info("log chunk from another attempt ignored",
+			zap.String("execution_id", executionID),
+			zap.Int64("seq", req.Seq),
+			zap.Int("retry_count", execution.RetryCount))
+		return nil
+	}
+	if execution.Status == models.ExecutionStatusPending {
+		return fmt.Errorf("%w: execution is %s", ErrExecutionNotRunning, execution.Status)
+	}
+
+	chunk := models.ExecutionLogChunk{
+		ExecutionID: executionID,
+		Attempt:     execution.RetryCount,
+		Seq:         req.Seq,
+		Content:     req.Content,
+		Size:        len(req.Content),
+	}
+	result := r.storage.DB().Clauses(clause.OnConflict{DoNothing: true}).Create(&chunk)
+	if result.Error != nil {
+		return fmt.Errorf("failed to append execution log: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	if err := r.rotateLogs(executionID); err != nil {
+		r.logger.Error("failed to rotate execution logs",
+			zap.String("execution_id", executionID),
+			zap.Error(err))
+	}
+	return nil
+}
+
+// rotateLogs 执行的日志总量超过 log_max_bytes 时删除最早的日志块，并标记执行的日志已轮转
+func (r *TaskRunner) rotateLogs(executionID string) error {
+	var total int64
+	if err := r.storage.DB().Model(&models.ExecutionLogChunk{}).
+		Where("execution_id = ?", executionID).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&total).Error; err != nil {
+		return err
+	}
+	if total <= r.logMaxBytes {
+		return nil
+	}
+
+	var chunks []models.ExecutionLogChunk
+	if err := r.storage.DB().Select("id", "size").
+		Where("execution_id = ?", executionID).
+		Order("id").
+		Find(&chunks).Error; err != nil {
+		return err
+	}
+
+	var ids []uint64
+	for _, chunk := range chunks {
+		if total <= r.logMaxBytes {
+			break
+		}
+		ids = append(ids, chunk.ID)
+		total -= int64(chunk.Size)
+	}
+
+	return r.storage.DB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id IN ?", ids).Delete(&models.ExecutionLogChunk{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.TaskExecution{}).
+			Where("id = ?", executionID).
+			UpdateColumn("logs_rotated", true).Error
+	})
+}
@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/executor"
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAppendLogRotation(t *testing.T) {
+	st := newTestStorage(t)
+	runner := NewTaskRunner(st, nil, nil, zap.NewNop(), config.SchedulerConfig{
+		InstanceID:       "a",
+		MaxWorkers:       1,
+		LogChunkMaxBytes: 10,
+		LogMaxBytes:      25,
+	})
+	ctx := context.Background()
+
+	require.NoError(t, st.DB().Create(&models.Task{ID: "task-1", Name: "task-1", CronExpression: "0 * * * * *"}).Error)
+	for _, execution := range []models.TaskExecution{
+		{ID: "exec-1", Status: models.ExecutionStatusRunning},
+		{ID: "exec-pending", Status: models.ExecutionStatusPending},
+	} {
+		execution.TaskID = "task-1"
+		execution.ScheduledTime = time.Now()
+		require.NoError(t, st.DB().Create(&execution).Error)
+	}
+
+	appendLog := func(seq int64, content string) error {
+		return runner.AppendLog(ctx, "exec-1", executor.ExecutionLogRequest{Attempt: intPtr(0), ExecutionID: "exec-1", Seq: seq, Content: content})
+	}
+	require.NoError(t, appendLog(1, "line 1\n"))
+	require.NoError(t, appendLog(2, "line 2\n"))
+	// 执行器重试上报同一块
+	require.NoError(t, appendLog(2, "line 2\n"))
+	assert.ErrorIs(t, appendLog(3, "a line longer than ten bytes\n"), ErrLogChunkTooLarge)
+	assert.ErrorIs(t, runner.AppendLog(ctx, "exec-pending", executor.ExecutionLogRequest{Attempt: intPtr(0), ExecutionID: "exec-pending", Seq: 1, Content: "x"}), ErrExecutionNotRunning)
+
+	var chunks []models.ExecutionLogChunk
+	require.NoError(t, st.DB().Where("execution_id = ?", "exec-1").Order("id").Find(&chunks).Error)
+	require.Len(t, chunks, 2)
+
+	// 超过总量上限后删除最早的日志块
+	require.NoError(t, appendLog(3, "line 3\n"))
+	require.NoError(t, appendLog(4, "line 4\n"))
+	require.NoError(t, st.DB().Where("execution_id = ?", "exec-1").Order("id").Find(&chunks).Error)
+	require.Len(t, chunks, 3)
+	assert.Equal(t, int64(2), chunks[0].Seq)
+
+	var execution models.TaskExecution
+	require.NoError(t, st.DB().Where("id = ?", "exec-1").First(&execution).Error)
+	assert.True(t, execution.LogsRotated)
+}
+
+func TestPurgeExecutions(t *testing.T) {
+	st := newTestStorage(t)
+	s := &Scheduler{storage: st, logger: zap.NewNop()}
+
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	runID := "run-1"
+	require.NoError(t, st.DB().Create(&models.Task{ID: "task-1", Name: "task-1", CronExpression: "0 * * * * *"}).Error)
+	require.NoError(t, st.DB().Create(&models.WorkflowRun{ID: runID, RootTaskID: "task-1", Status: models.WorkflowRunStatusRunning}).Error)
+	for _, execution := range []models.TaskExecution{
+		{ID: "expired", Status: models.ExecutionStatusSuccess, EndTime: &old},
+		{ID: "recent", Status: models.ExecutionStatusFailed, EndTime: &now},
+		{ID: "running", Status: models.ExecutionStatusRunning},
+		{ID: "in-active-run", Status: models.ExecutionStatusSuccess, EndTime: &old, WorkflowRunID: &runID},
+	} {
+		execution.TaskID = "task-1"
+		execution.ScheduledTime = old
+		require.NoError(t, st.DB().Create(&execution).Error)
+		require.NoError(t, st.DB().Create(&models.ExecutionLogChunk{ExecutionID: execution.ID, Seq: 1, Content: "x", Size: 1}).Error)
+	}
+
+	purged, err := s.purgeExecutions(now.Add(-24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	var ids []string
+	require.NoError(t, st.DB().Model(&models.TaskExecution{}).Order("id").Pluck("id", &ids).Error)
+	assert.Equal(t, []string{"in-active-run", "recent", "running"}, ids)
+
+	var chunks int64
+	require.NoError(t, st.DB().Model(&models.ExecutionLogChunk{}).Where("execution_id = ?", "expired").Count(&chunks).Error)
+	assert.Zero(t, chunks)
+}
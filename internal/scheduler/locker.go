@@ -4,18 +4,75 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/jobs/scheduler/internal/storage"
+	"github.com/jobs/scheduler/pkg/config"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
+// 领导者选举方式
+const (
+	LeaderElectionLease = "lease" // 租约表 + fencing token（默认）
+	LeaderElectionLock  = "lock"  // 数据库锁（MySQL GET_LOCK / PostgreSQL advisory lock / SQLite scheduler_locks 行锁）
+)
+
+// LeaderLock 领导者选举使用的分布式锁
+type LeaderLock interface {
+	// TryLock 尝试获取锁
+	TryLock(ctx context.Context) (bool, error)
+	// Unlock 释放锁
+	Unlock(ctx context.Context) error
+	// Renew 续约锁，锁已丢失时返回错误
+	Renew(ctx context.Context) error
+	// IsLocked 是否持有锁
+	IsLocked() bool
+	// FencingToken 当前持有的令牌，不支持令牌的实现返回0
+	FencingToken() int64
+	// Fence 在事务内确认仍持有锁，返回写入时应携带的令牌
+	Fence(tx *gorm.DB) (int64, error)
+}
+
+// NewLeaderLock 根据配置和数据库方言创建领导者锁
+func NewLeaderLock(store *storage.Storage, cfg config.SchedulerConfig, logger *zap.Logger) (LeaderLock, error) {
+	ttl := cfg.LeaseTTL
+	if ttl <= 0 {
+		ttl = cfg.LockTimeout
+	}
+
+	if cfg.LeaderElection != LeaderElectionLock {
+		return NewLeaseLocker(store, cfg.LockKey, cfg.InstanceID, ttl, logger), nil
+	}
+
+	sqlDB, err := store.DB().DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sql.DB: %w", err)
+	}
+
+	switch store.Dialect().Name() {
+	case storage.DriverPostgres:
+		return NewPostgresLocker(sqlDB, cfg.LockKey, logger), nil
+	case storage.DriverSQLite:
+		// SQLite没有会话级锁，使用带过期时间的锁行
+		return NewRowLocker(store, cfg.LockKey, cfg.InstanceID, cfg.LockTimeout, logger), nil
+	default:
+		return NewLocker(sqlDB, cfg.LockKey, cfg.LockTimeout, logger), nil
+	}
+}
+
 // Locker MySQL分布式锁
+// GET_LOCK 是会话级的，锁只属于获取它的那个连接；因此获取锁后固定持有该连接直到释放，
+// 续约检查和释放都必须在同一连接上执行，不能经由连接池
+// conn 只由选举协程替换，mu 保护其他协程通过 IsLocked/Fence 的读取
 type Locker struct {
 	db       *sql.DB
+	mu       sync.Mutex
+	conn     *sql.Conn
 	lockName string
 	timeout  time.Duration
 	logger   *zap.Logger
-	locked   bool
 }
 
 // NewLocker 创建分布式锁
@@ -25,54 +82,62 @@ func NewLocker(db *sql.DB, lockName string, timeout time.Duration, logger *zap.L
 		lockName: lockName,
 		timeout:  timeout,
 		logger:   logger,
-		locked:   false,
 	}
 }
 
 // TryLock 尝试获取锁
 func (l *Locker) TryLock(ctx context.Context) (bool, error) {
-	if l.locked {
+	if l.IsLocked() {
 		return true, nil
 	}
 
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get connection: %w", err)
+	}
+
 	// MySQL GET_LOCK 函数
 	// 返回值: 1-成功获取锁, 0-超时, NULL-错误
 	query := "SELECT GET_LOCK(?, ?)"
 	timeoutSeconds := int(l.timeout.Seconds())
 
 	var result sql.NullInt64
-	err := l.db.QueryRowContext(ctx, query, l.lockName, timeoutSeconds).Scan(&result)
-	if err != nil {
+	if err := conn.QueryRowContext(ctx, query, l.lockName, timeoutSeconds).Scan(&result); err != nil {
+		conn.Close()
 		return false, fmt.Errorf("failed to acquire lock: %w", err)
 	}
 
 	if !result.Valid {
+		conn.Close()
 		return false, fmt.Errorf("lock query returned NULL")
 	}
 
-	if result.Int64 == 1 {
-		l.locked = true
-		l.logger.Info("acquired distributed lock",
-			zap.String("lock_name", l.lockName))
-		return true, nil
+	if result.Int64 != 1 {
+		conn.Close()
+		return false, nil
 	}
 
-	return false, nil
+	l.setConn(conn)
+	l.logger.Info("acquired distributed lock",
+		zap.String("lock_name", l.lockName))
+	return true, nil
 }
 
 // Unlock 释放锁
 func (l *Locker) Unlock(ctx context.Context) error {
-	if !l.locked {
+	conn := l.current()
+	if conn == nil {
 		return nil
 	}
 
+	defer l.release()
+
 	// MySQL RELEASE_LOCK 函数
 	// 返回值: 1-成功释放锁, 0-锁不存在或不是持有者, NULL-错误
 	query := "SELECT RELEASE_LOCK(?)"
 
 	var result sql.NullInt64
-	err := l.db.QueryRowContext(ctx, query, l.lockName).Scan(&result)
-	if err != nil {
+	if err := conn.QueryRowContext(ctx, query, l.lockName).Scan(&result); err != nil {
 		return fmt.Errorf("failed to release lock: %w", err)
 	}
 
@@ -80,45 +145,73 @@ func (l *Locker) Unlock(ctx context.Context) error {
 		return fmt.Errorf("release lock query returned NULL")
 	}
 
-	if result.Int64 == 1 {
-		l.locked = false
-		l.logger.Info("released distributed lock",
-			zap.String("lock_name", l.lockName))
-		return nil
+	if result.Int64 != 1 {
+		return fmt.Errorf("failed to release lock: not owner or lock does not exist")
 	}
 
-	return fmt.Errorf("failed to release lock: not owner or lock does not exist")
+	l.logger.Info("released distributed lock",
+		zap.String("lock_name", l.lockName))
+	return nil
+}
+
+// current 返回持锁连接，未持有时为nil
+func (l *Locker) current() *sql.Conn {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.conn
+}
+
+func (l *Locker) setConn(conn *sql.Conn) {
+	l.mu.Lock()
+	l.conn = conn
+	l.mu.Unlock()
+}
+
+// release 关闭持锁连接，会话结束时锁随之释放
+func (l *Locker) release() {
+	l.mu.Lock()
+	conn := l.conn
+	l.conn = nil
+	l.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
 }
 
 // IsLocked 检查是否持有锁
 func (l *Locker) IsLocked() bool {
-	return l.locked
+	return l.current() != nil
 }
 
-// Renew 续约锁（MySQL GET_LOCK会自动续约，这里主要用于心跳检测）
+// FencingToken 会话锁不提供令牌
+func (l *Locker) FencingToken() int64 {
+	return 0
+}
+
+// Fence 会话锁无法在事务内校验，仅检查本地持锁状态
+func (l *Locker) Fence(tx *gorm.DB) (int64, error) {
+	if !l.IsLocked() {
+		return 0, ErrLeaseLost
+	}
+	return 0, nil
+}
+
+// Renew 在持锁连接上确认锁仍由本会话持有
+// IS_USED_LOCK 只说明锁被某个会话占用，必须与本连接的 CONNECTION_ID() 比较
 func (l *Locker) Renew(ctx context.Context) error {
-	if !l.locked {
+	conn := l.current()
+	if conn == nil {
 		return fmt.Errorf("not holding lock")
 	}
 
-	// 检查连接是否有效
-	if err := l.db.PingContext(ctx); err != nil {
-		l.locked = false
+	var held sql.NullBool
+	if err := conn.QueryRowContext(ctx, "SELECT IS_USED_LOCK(?) = CONNECTION_ID()", l.lockName).Scan(&held); err != nil {
+		l.release()
 		return fmt.Errorf("database connection lost: %w", err)
 	}
 
-	// MySQL的GET_LOCK是会话级别的，只要连接不断开，锁就会一直持有
-	// 这里我们可以通过IS_USED_LOCK检查锁的状态
-	query := "SELECT IS_USED_LOCK(?)"
-
-	var result sql.NullString
-	err := l.db.QueryRowContext(ctx, query, l.lockName).Scan(&result)
-	if err != nil {
-		return fmt.Errorf("failed to check lock status: %w", err)
-	}
-
-	if !result.Valid || result.String == "" {
-		l.locked = false
+	if !held.Valid || !held.Bool {
+		l.release()
 		return fmt.Errorf("lock is not held")
 	}
 
@@ -0,0 +1,207 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/internal/storage"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrLeaseLost 租约已丢失（过期或被其他实例接管）
+var ErrLeaseLost = errors.New("leader lease lost")
+
+// LeaseLocker 基于 scheduler_leases 表的租约锁
+// 获取和续约都是对租约行的比较并交换，不依赖任何数据库会话；
+// 每次易主时 fencing token 递增，被取代的领导者无法再以旧令牌写入执行记录
+// 持有状态由选举协程修改，并被定时触发、接口请求等其他协程读取，因此由 mu 保护
+type LeaseLocker struct {
+	storage *storage.Storage
+	name    string
+	holder  string
+	ttl     time.Duration
+	logger  *zap.Logger
+
+	mu     sync.Mutex
+	locked bool
+	token  int64
+}
+
+// NewLeaseLocker 创建租约锁
+func NewLeaseLocker(storage *storage.Storage, name, holder string, ttl time.Duration, logger *zap.Logger) *LeaseLocker {
+	return &LeaseLocker{
+		storage: storage,
+		name:    name,
+		holder:  holder,
+		ttl:     ttl,
+		logger:  logger,
+	}
+}
+
+// TryLock 尝试获取租约：行不存在则以令牌1创建，租约过期（或为本实例遗留）则CAS接管并递增令牌
+// 不信任本地持有状态，每次都以 scheduler_leases 行为准，重新获取时令牌同样递增
+func (l *LeaseLocker) TryLock(ctx context.Context) (bool, error) {
+	l.lost()
+
+	now := time.Now()
+	lease := models.SchedulerLease{
+		Name:         l.name,
+		Holder:       l.holder,
+		FencingToken: 1,
+		ExpiresAt:    now.Add(l.ttl),
+	}
+	result := l.storage.DB().
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&lease)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to acquire lease: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		l.acquired(lease.FencingToken)
+		return true, nil
+	}
+
+	var current models.SchedulerLease
+	if err := l.storage.DB().Where("name = ?", l.name).First(&current).Error; err != nil {
+		return false, fmt.Errorf("failed to load lease: %w", err)
+	}
+
+	if current.Holder != l.holder && current.ExpiresAt.After(now) {
+		return false, nil
+	}
+
+	next := current.FencingToken + 1
+	result = l.storage.DB().
+		Model(&models.SchedulerLease{}).
+		Where("name = ? AND fencing_token = ?", l.name, current.FencingToken).
+		Updates(map[string]interface{}{
+			"holder":        l.holder,
+			"fencing_token": next,
+			"expires_at":    now.Add(l.ttl),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to acquire lease: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// 其他实例抢先完成了接管
+		return false, nil
+	}
+
+	l.acquired(next)
+	return true, nil
+}
+
+func (l *LeaseLocker) acquired(token int64) {
+	l.mu.Lock()
+	l.locked = true
+	l.token = token
+	l.mu.Unlock()
+	l.logger.Info("acquired leader lease",
+		zap.String("lease", l.name),
+		zap.Int64("fencing_token", token),
+		zap.Duration("ttl", l.ttl))
+}
+
+// Unlock 主动让出租约：保留行和令牌，仅将其置为过期，令牌单调性不受影响
+func (l *LeaseLocker) Unlock(ctx context.Context) error {
+	locked, token := l.state()
+	if !locked {
+		return nil
+	}
+	l.lost()
+
+	result := l.storage.DB().
+		Model(&models.SchedulerLease{}).
+		Where("name = ? AND holder = ? AND fencing_token = ?", l.name, l.holder, token).
+		Update("expires_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to release lease: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrLeaseLost
+	}
+
+	l.logger.Info("released leader lease",
+		zap.String("lease", l.name),
+		zap.Int64("fencing_token", token))
+	return nil
+}
+
+// state 返回持有状态的一致快照
+func (l *LeaseLocker) state() (locked bool, token int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.locked, l.token
+}
+
+// lost 标记租约已不再持有
+func (l *LeaseLocker) lost() {
+	l.mu.Lock()
+	l.locked = false
+	l.mu.Unlock()
+}
+
+// IsLocked 检查是否持有租约
+func (l *LeaseLocker) IsLocked() bool {
+	locked, _ := l.state()
+	return locked
+}
+
+// FencingToken 当前持有的租约令牌，未持有时为0
+func (l *LeaseLocker) FencingToken() int64 {
+	locked, token := l.state()
+	if !locked {
+		return 0
+	}
+	return token
+}
+
+// Renew 续约：仅当租约仍由本实例以相同令牌持有时延长过期时间
+// 任何失败都清除本地持有状态：调度器会随之放弃领导权，之后只能经 TryLock 重新CAS获取
+func (l *LeaseLocker) Renew(ctx context.Context) error {
+	locked, token := l.state()
+	if !locked {
+		return fmt.Errorf("not holding lease")
+	}
+
+	now := time.Now()
+	result := l.storage.DB().
+		Model(&models.SchedulerLease{}).
+		Where("name = ? AND holder = ? AND fencing_token = ?", l.name, l.holder, token).
+		Update("expires_at", now.Add(l.ttl))
+	if result.Error != nil {
+		l.lost()
+		return fmt.Errorf("%w: %v", ErrLeaseLost, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		l.lost()
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// Fence 在事务内校验租约仍由本实例以当前令牌持有且未过期，用于保护领导者写入
+func (l *LeaseLocker) Fence(tx *gorm.DB) (int64, error) {
+	locked, token := l.state()
+	if !locked {
+		return 0, ErrLeaseLost
+	}
+
+	var current models.SchedulerLease
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("name = ?", l.name).
+		First(&current).Error; err != nil {
+		return 0, fmt.Errorf("failed to load lease: %w", err)
+	}
+
+	if current.Holder != l.holder || current.FencingToken != token || !current.ExpiresAt.After(time.Now()) {
+		return 0, ErrLeaseLost
+	}
+	return token, nil
+}
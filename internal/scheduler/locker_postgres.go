@@ -0,0 +1,156 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PostgresLocker PostgreSQL advisory lock
+// 会话级advisory lock绑定在连接上，因此获取锁后固定持有一个连接直到释放
+// conn 只由选举协程替换，mu 保护其他协程通过 IsLocked/Fence 的读取
+type PostgresLocker struct {
+	db       *sql.DB
+	mu       sync.Mutex
+	conn     *sql.Conn
+	lockName string
+	lockKey  int64
+	logger   *zap.Logger
+}
+
+// NewPostgresLocker 创建PostgreSQL advisory lock
+func NewPostgresLocker(db *sql.DB, lockName string, logger *zap.Logger) *PostgresLocker {
+	h := fnv.New64a()
+	h.Write([]byte(lockName))
+
+	return &PostgresLocker{
+		db:       db,
+		lockName: lockName,
+		lockKey:  int64(h.Sum64()),
+		logger:   logger,
+	}
+}
+
+// TryLock 尝试获取锁
+func (l *PostgresLocker) TryLock(ctx context.Context) (bool, error) {
+	if l.IsLocked() {
+		return true, nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.lockKey).Scan(&locked); err != nil {
+		conn.Close()
+		return false, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+
+	if !locked {
+		conn.Close()
+		return false, nil
+	}
+
+	l.setConn(conn)
+	l.logger.Info("acquired distributed lock",
+		zap.String("lock_name", l.lockName))
+	return true, nil
+}
+
+// Unlock 释放锁
+func (l *PostgresLocker) Unlock(ctx context.Context) error {
+	conn := l.current()
+	if conn == nil {
+		return nil
+	}
+
+	defer l.release()
+
+	var released bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1)", l.lockKey).Scan(&released); err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+
+	if !released {
+		return fmt.Errorf("failed to release lock: not owner or lock does not exist")
+	}
+
+	l.logger.Info("released distributed lock",
+		zap.String("lock_name", l.lockName))
+	return nil
+}
+
+// current 返回持锁连接，未持有时为nil
+func (l *PostgresLocker) current() *sql.Conn {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.conn
+}
+
+func (l *PostgresLocker) setConn(conn *sql.Conn) {
+	l.mu.Lock()
+	l.conn = conn
+	l.mu.Unlock()
+}
+
+// release 关闭持锁连接，会话结束时锁随之释放
+func (l *PostgresLocker) release() {
+	l.mu.Lock()
+	conn := l.conn
+	l.conn = nil
+	l.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// IsLocked 检查是否持有锁
+func (l *PostgresLocker) IsLocked() bool {
+	return l.current() != nil
+}
+
+// FencingToken 会话锁不提供令牌
+func (l *PostgresLocker) FencingToken() int64 {
+	return 0
+}
+
+// Fence 会话锁无法在事务内校验，仅检查本地持锁状态
+func (l *PostgresLocker) Fence(tx *gorm.DB) (int64, error) {
+	if !l.IsLocked() {
+		return 0, ErrLeaseLost
+	}
+	return 0, nil
+}
+
+// Renew 在持锁连接上确认锁仍由本会话持有
+func (l *PostgresLocker) Renew(ctx context.Context) error {
+	conn := l.current()
+	if conn == nil {
+		return fmt.Errorf("not holding lock")
+	}
+
+	query := `SELECT EXISTS (
+		SELECT 1 FROM pg_locks
+		WHERE locktype = 'advisory' AND granted AND pid = pg_backend_pid()
+		AND ((classid::bigint << 32) | objid::bigint) = $1)`
+
+	var held bool
+	if err := conn.QueryRowContext(ctx, query, l.lockKey).Scan(&held); err != nil {
+		l.release()
+		return fmt.Errorf("database connection lost: %w", err)
+	}
+
+	if !held {
+		l.release()
+		return fmt.Errorf("lock is not held")
+	}
+
+	return nil
+}
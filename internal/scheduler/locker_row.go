@@ -0,0 +1,147 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/internal/storage"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RowLocker 基于 scheduler_locks 表行的锁，选主方式为 lock 时用于SQLite等没有会话级锁的数据库
+// 持有者需要在 ttl 内续约，过期后其他实例可以抢占；与会话锁一样不提供 fencing token
+type RowLocker struct {
+	storage  *storage.Storage
+	lockName string
+	holder   string
+	ttl      time.Duration
+	logger   *zap.Logger
+	locked   atomic.Bool // 由选举协程修改，其他协程通过 IsLocked/Fence 读取
+}
+
+// NewRowLocker 创建基于行的锁
+func NewRowLocker(storage *storage.Storage, lockName, holder string, ttl time.Duration, logger *zap.Logger) *RowLocker {
+	return &RowLocker{
+		storage:  storage,
+		lockName: lockName,
+		holder:   holder,
+		ttl:      ttl,
+		logger:   logger,
+	}
+}
+
+// TryLock 尝试获取锁：行不存在则插入，已过期或本实例持有则接管
+// 不信任本地持有状态，每次都以 scheduler_locks 行为准
+func (l *RowLocker) TryLock(ctx context.Context) (bool, error) {
+	l.locked.Store(false)
+
+	now := time.Now()
+	lock := models.SchedulerLock{
+		Name:      l.lockName,
+		Holder:    l.holder,
+		ExpiresAt: now.Add(l.ttl),
+	}
+	if err := l.storage.DB().
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&lock).Error; err != nil {
+		return false, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+
+	result := l.storage.DB().
+		Model(&models.SchedulerLock{}).
+		Where("name = ? AND (holder = ? OR expires_at < ?)", l.lockName, l.holder, now).
+		Updates(map[string]interface{}{
+			"holder":     l.holder,
+			"expires_at": now.Add(l.ttl),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to acquire lock: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	l.locked.Store(true)
+	l.logger.Info("acquired distributed lock",
+		zap.String("lock_name", l.lockName))
+	return true, nil
+}
+
+// Unlock 释放锁
+func (l *RowLocker) Unlock(ctx context.Context) error {
+	if !l.locked.Load() {
+		return nil
+	}
+	l.locked.Store(false)
+
+	result := l.storage.DB().
+		Where("name = ? AND holder = ?", l.lockName, l.holder).
+		Delete(&models.SchedulerLock{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to release lock: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("failed to release lock: not owner or lock does not exist")
+	}
+
+	l.logger.Info("released distributed lock",
+		zap.String("lock_name", l.lockName))
+	return nil
+}
+
+// IsLocked 检查是否持有锁
+func (l *RowLocker) IsLocked() bool {
+	return l.locked.Load()
+}
+
+// FencingToken 行锁不提供令牌
+func (l *RowLocker) FencingToken() int64 {
+	return 0
+}
+
+// Fence 在事务内确认本实例仍持有未过期的锁行
+func (l *RowLocker) Fence(tx *gorm.DB) (int64, error) {
+	if !l.locked.Load() {
+		return 0, ErrLeaseLost
+	}
+	var count int64
+	if err := tx.Model(&models.SchedulerLock{}).
+		Where("name = ? AND holder = ? AND expires_at > ?", l.lockName, l.holder, time.Now()).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to check lock: %w", err)
+	}
+	if count == 0 {
+		return 0, ErrLeaseLost
+	}
+	return 0, nil
+}
+
+// Renew 续约锁，仅当本实例仍是持有者时才延长过期时间
+func (l *RowLocker) Renew(ctx context.Context) error {
+	if !l.locked.Load() {
+		return fmt.Errorf("not holding lock")
+	}
+
+	result := l.storage.DB().
+		Model(&models.SchedulerLock{}).
+		Where("name = ? AND holder = ?", l.lockName, l.holder).
+		Update("expires_at", time.Now().Add(l.ttl))
+	if result.Error != nil {
+		l.locked.Store(false)
+		return fmt.Errorf("failed to renew lock: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		l.locked.Store(false)
+		return fmt.Errorf("lock is not held")
+	}
+
+	return nil
+}
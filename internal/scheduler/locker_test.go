@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRowLocker(t *testing.T) {
+	st := newTestStorage(t)
+	ctx := context.Background()
+	a := NewRowLocker(st, "leader", "a", time.Minute, zap.NewNop())
+	b := NewRowLocker(st, "leader", "b", time.Minute, zap.NewNop())
+
+	locked, err := a.TryLock(ctx)
+	require.NoError(t, err)
+	assert.True(t, locked)
+	_, err = a.Fence(st.DB())
+	assert.NoError(t, err)
+
+	locked, err = b.TryLock(ctx)
+	require.NoError(t, err)
+	assert.False(t, locked)
+	_, err = b.Fence(st.DB())
+	assert.ErrorIs(t, err, ErrLeaseLost)
+
+	require.NoError(t, a.Renew(ctx))
+	require.NoError(t, a.Unlock(ctx))
+	assert.False(t, a.IsLocked())
+
+	locked, err = b.TryLock(ctx)
+	require.NoError(t, err)
+	assert.True(t, locked)
+	assert.Error(t, a.Renew(ctx))
+}
+
+func TestLeaseLockerConcurrentReaders(t *testing.T) {
+	st := newTestStorage(t)
+	ctx := context.Background()
+	l := NewLeaseLocker(st, "leader", "a", time.Minute, zap.NewNop())
+
+	// 选举协程修改持有状态的同时，其他协程读取令牌
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if token := l.FencingToken(); token != 0 {
+					assert.Positive(t, token)
+				}
+				l.IsLocked()
+			}
+		}()
+	}
+
+	var last int64
+	for i := 0; i < 5; i++ {
+		locked, err := l.TryLock(ctx)
+		require.NoError(t, err)
+		require.True(t, locked)
+		require.NoError(t, l.Renew(ctx))
+		token := l.FencingToken()
+		assert.Greater(t, token, last)
+		last = token
+		require.NoError(t, l.Unlock(ctx))
+		assert.Zero(t, l.FencingToken())
+	}
+	close(done)
+	wg.Wait()
+}
+
+func TestLeaseLockerReacquiresThroughDatabaseAfterRenewFailure(t *testing.T) {
+	st := newTestStorage(t)
+	ctx := context.Background()
+	a := NewLeaseLocker(st, "leader", "a", time.Minute, zap.NewNop())
+
+	locked, err := a.TryLock(ctx)
+	require.NoError(t, err)
+	require.True(t, locked)
+	token := a.FencingToken()
+
+	// 续约时数据库不可用：本地持有状态必须随之清除
+	require.NoError(t, st.DB().Exec("ALTER TABLE scheduler_leases RENAME TO scheduler_leases_gone").Error)
+	assert.ErrorIs(t, a.Renew(ctx), ErrLeaseLost)
+	assert.False(t, a.IsLocked())
+	assert.Zero(t, a.FencingToken())
+	require.NoError(t, st.DB().Exec("ALTER TABLE scheduler_leases_gone RENAME TO scheduler_leases").Error)
+
+	// 期间租约已被其他实例接管，重新获取必须以租约行为准
+	require.NoError(t, st.DB().Model(&models.SchedulerLease{}).
+		Where("name = ?", "leader").
+		Updates(map[string]interface{}{"holder": "b", "fencing_token": token + 1}).Error)
+	locked, err = a.TryLock(ctx)
+	require.NoError(t, err)
+	assert.False(t, locked)
+	_, err = a.Fence(st.DB())
+	assert.ErrorIs(t, err, ErrLeaseLost)
+
+	// 租约过期后才能再次接管，且令牌继续递增
+	require.NoError(t, st.DB().Model(&models.SchedulerLease{}).
+		Where("name = ?", "leader").
+		Update("expires_at", time.Now().Add(-time.Second)).Error)
+	locked, err = a.TryLock(ctx)
+	require.NoError(t, err)
+	assert.True(t, locked)
+	assert.Equal(t, token+2, a.FencingToken())
+}
@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/pkg/cronexpr"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// defaultMisfireLimit fire_all 未设置上限时最多补偿的次数
+const defaultMisfireLimit = 10
+
+// markScheduled 持久化任务最近一次计划触发时间，只前进不后退
+// 使用 UpdateColumn 避免修改 updated_at 而触发对账
+func (s *Scheduler) markScheduled(taskID string, scheduledTime time.Time) {
+	if err := s.storage.DB().
+		Model(&models.Task{}).
+		Where("id = ? AND (last_scheduled_time IS NULL OR last_scheduled_time < ?)", taskID, scheduledTime).
+		UpdateColumn("last_scheduled_time", scheduledTime).Error; err != nil {
+		s.logger.Error("failed to update last scheduled time",
+			zap.String("task_id", taskID),
+			zap.Error(err))
+	}
+}
+
+// recoverMisfires 成为领导者后，按任务的补偿策略处理 last_scheduled_time 之后错过的触发
+// 从未被调度过的任务（last_scheduled_time 为空）没有基准时间，不做补偿
+func (s *Scheduler) recoverMisfires() error {
+	now := time.Now()
+
+	var tasks []models.Task
+	if err := s.storage.DB().
+		Where("status = ? AND misfire_policy IN ? AND last_scheduled_time IS NOT NULL",
+			models.TaskStatusActive,
+			[]models.MisfirePolicy{models.MisfirePolicyFireOnce, models.MisfirePolicyFireAll}).
+		Find(&tasks).Error; err != nil {
+		return fmt.Errorf("failed to load tasks: %w", err)
+	}
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	// 有上游依赖的任务不由cron触发，也就没有错过的触发
+	dependent, err := s.dependentTasks()
+	if err != nil {
+		return err
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		if dependent[task.ID] {
+			continue
+		}
+
+		schedule, err := cronexpr.Parse(task.CronExpression, task.Timezone)
+		if err != nil {
+			s.logger.Error("failed to parse cron expression",
+				zap.String("task_id", task.ID),
+				zap.String("cron", task.CronExpression),
+				zap.Error(err))
+			continue
+		}
+
+		limit := 1
+		if task.MisfirePolicy == models.MisfirePolicyFireAll {
+			if err := task.ValidateMisfire(); err != nil {
+				// 校验加入前保存的组合：逐次补偿会被执行模式丢弃或跳过，退化为只补最近一次
+				s.logger.Warn("fire_all misfire policy degraded to fire_once",
+					zap.String("task_id", task.ID),
+					zap.Error(err))
+			} else {
+				limit = task.MisfireLimit
+				if limit <= 0 {
+					limit = defaultMisfireLimit
+				}
+			}
+		}
+
+		missed, total := missedTimes(schedule, *task.LastScheduledTime, now, limit)
+		if total == 0 {
+			continue
+		}
+
+		s.logger.Info("recovering misfired task",
+			zap.String("task_id", task.ID),
+			zap.String("task_name", task.Name),
+			zap.String("policy", string(task.MisfirePolicy)),
+			zap.Int("missed", total),
+			zap.Int("firing", len(missed)))
+
+		s.markScheduled(task.ID, missed[len(missed)-1])
+		for _, scheduledTime := range missed {
+			s.scheduleTask(task, scheduledTime)
+		}
+	}
+
+	return nil
+}
+
+// missedTimes 返回 (last, now) 区间内最近的至多 limit 个计划时间（按时间升序）及错过的总次数
+func missedTimes(schedule cron.Schedule, last, now time.Time, limit int) ([]time.Time, int) {
+	if limit <= 0 {
+		return nil, 0
+	}
+
+	ring := make([]time.Time, limit)
+	total := 0
+	for t := schedule.Next(last); !t.IsZero() && t.Before(now); t = schedule.Next(t) {
+		ring[total%limit] = t
+		total++
+	}
+
+	n := total
+	if n > limit {
+		n = limit
+	}
+	missed := make([]time.Time, 0, n)
+	for i := total - n; i < total; i++ {
+		missed = append(missed, ring[i%limit])
+	}
+	return missed, total
+}
@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/pkg/cronexpr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissedTimes(t *testing.T) {
+	// 每天 09:00
+	schedule, err := cronexpr.Parse("0 0 9 * * *", "UTC")
+	require.NoError(t, err)
+
+	last := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC)
+
+	// 错过 1-2、1-3、1-4、1-5 四次，只保留最近两次
+	missed, total := missedTimes(schedule, last, now, 2)
+	assert.Equal(t, 4, total)
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 1, 4, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC),
+	}, missed)
+
+	missed, total = missedTimes(schedule, last, now, 10)
+	assert.Equal(t, 4, total)
+	assert.Len(t, missed, 4)
+	assert.Equal(t, time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC), missed[0])
+
+	// 未错过
+	missed, total = missedTimes(schedule, last, last.Add(time.Hour), 1)
+	assert.Equal(t, 0, total)
+	assert.Empty(t, missed)
+}
+
+func TestRecoverMisfires(t *testing.T) {
+	s, st := newManualScheduler(t)
+	locked, err := s.locker.TryLock(context.Background())
+	require.NoError(t, err)
+	require.True(t, locked)
+
+	// 每分钟触发，上次触发在 30 分钟前，共错过 30 次
+	now := time.Now().Truncate(time.Minute)
+	last := now.Add(-30 * time.Minute)
+	tasks := []models.Task{
+		{ID: "once", MisfirePolicy: models.MisfirePolicyFireOnce},
+		{ID: "all", MisfirePolicy: models.MisfirePolicyFireAll, MisfireLimit: 3},
+		{ID: "all-default", MisfirePolicy: models.MisfirePolicyFireAll},
+		// 校验加入前保存的 fire_all 与跳过模式的组合，只补最近一次
+		{ID: "all-skip", MisfirePolicy: models.MisfirePolicyFireAll, MisfireLimit: 3, ExecutionMode: models.ExecutionModeSkip},
+		{ID: "ignore", MisfirePolicy: models.MisfirePolicyIgnore},
+		{ID: "never", MisfirePolicy: models.MisfirePolicyFireOnce},
+		{ID: "paused", MisfirePolicy: models.MisfirePolicyFireOnce, Status: models.TaskStatusPaused},
+	}
+	for i := range tasks {
+		task := &tasks[i]
+		task.Name = task.ID
+		task.CronExpression = "0 * * * * *"
+		if task.ID != "never" {
+			task.LastScheduledTime = &last
+		}
+		require.NoError(t, st.DB().Create(task).Error)
+	}
+	require.NoError(t, st.DB().Model(&models.Task{}).Where("id = ?", "paused").
+		Update("status", models.TaskStatusPaused).Error)
+
+	require.NoError(t, s.recoverMisfires())
+
+	scheduled := func(taskID string) []time.Time {
+		var executions []models.TaskExecution
+		require.NoError(t, st.DB().Where("task_id = ?", taskID).Order("scheduled_time").Find(&executions).Error)
+		times := make([]time.Time, 0, len(executions))
+		for _, execution := range executions {
+			assert.Equal(t, models.ExecutionStatusPending, execution.Status)
+			times = append(times, execution.ScheduledTime)
+		}
+		return times
+	}
+	lastScheduled := func(taskID string) time.Time {
+		var task models.Task
+		require.NoError(t, st.DB().Where("id = ?", taskID).First(&task).Error)
+		require.NotNil(t, task.LastScheduledTime)
+		return *task.LastScheduledTime
+	}
+	latest := now
+
+	// fire_once 只补最近一次
+	once := scheduled("once")
+	require.Len(t, once, 1)
+	assert.True(t, once[0].Equal(latest))
+	assert.True(t, lastScheduled("once").Equal(latest))
+
+	// fire_all 按上限补最近几次，未设置上限时最多 10 次
+	all := scheduled("all")
+	require.Len(t, all, 3)
+	for i, scheduledTime := range all {
+		assert.True(t, scheduledTime.Equal(latest.Add(time.Duration(i-2)*time.Minute)))
+	}
+	assert.True(t, lastScheduled("all").Equal(latest))
+	assert.Len(t, scheduled("all-default"), defaultMisfireLimit)
+	allSkip := scheduled("all-skip")
+	require.Len(t, allSkip, 1)
+	assert.True(t, allSkip[0].Equal(latest))
+
+	// ignore、从未调度过和暂停的任务不补偿
+	assert.Empty(t, scheduled("ignore"))
+	assert.Empty(t, scheduled("never"))
+	assert.Empty(t, scheduled("paused"))
+	assert.True(t, lastScheduled("ignore").Equal(last))
+
+	// 再次成为领导者时已补偿的触发不会重复
+	require.NoError(t, s.recoverMisfires())
+	assert.Len(t, scheduled("once"), 1)
+	assert.Len(t, scheduled("all"), 3)
+}
@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/pkg/paramtemplate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallExecutorRendersParameterTemplates(t *testing.T) {
+	s, st := newManualScheduler(t)
+
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Parameters map[string]interface{} `json:"parameters"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		received = payload.Parameters
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	task := models.Task{
+		ID:             "task-1",
+		Name:           "daily_etl",
+		CronExpression: "0 0 2 * * *",
+		Timezone:       "Asia/Shanghai",
+		Parameters: models.JSONMap{
+			"date":  `{{ .ScheduledTime | addDays -1 | date "2006-01-02" }}`,
+			"batch": "{{ .ExecutionID }}/{{ .RetryCount }}",
+		},
+	}
+	require.NoError(t, st.DB().Create(&task).Error)
+	executor := models.Executor{ID: "executor-1", Name: "e1", InstanceID: "e1", BaseURL: server.URL}
+	require.NoError(t, st.DB().Create(&executor).Error)
+
+	// 上海时间 2024-03-10 02:00 触发，逻辑日期为前一天
+	execution := models.TaskExecution{
+		ID:            "exec-1",
+		TaskID:        task.ID,
+		ScheduledTime: time.Date(2024, 3, 9, 18, 0, 0, 0, time.UTC),
+		Status:        models.ExecutionStatusFailed,
+		RetryCount:    1,
+		Parameters:    task.EffectiveParameters(nil),
+	}
+	require.NoError(t, st.DB().Create(&execution).Error)
+
+	require.NoError(t, s.taskRunner.callExecutor(context.Background(), &task, &execution, &executor))
+	want := map[string]interface{}{"date": "2024-03-09", "batch": "exec-1/1"}
+	assert.Equal(t, want, received)
+
+	var stored models.TaskExecution
+	require.NoError(t, st.DB().Where("id = ?", execution.ID).First(&stored).Error)
+	assert.Equal(t, models.JSONMap(want), stored.RenderedParameters)
+	assert.Equal(t, task.Parameters, stored.Parameters)
+
+	// 原样重跑沿用实际下发的参数
+	rerun, err := s.RerunExecution(context.Background(), execution.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.JSONMap(want), rerun.Parameters)
+
+	// 手动触发时校验模板
+	_, err = s.TriggerTask(context.Background(), task.ID, map[string]interface{}{"date": "{{ .LogicalDate }}"})
+	assert.ErrorIs(t, err, paramtemplate.ErrInvalidTemplate)
+}
@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/executor"
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportProgressExtendsTimeout(t *testing.T) {
+	st := newTestStorage(t)
+	runner := newQueueRunner(t, st, "a", time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, st.DB().Create(&models.Task{ID: "task-1", Name: "task-1", CronExpression: "0 * * * * *", TimeoutSeconds: 1}).Error)
+	require.NoError(t, st.DB().Create(&models.TaskExecution{
+		ID:            "exec-1",
+		TaskID:        "task-1",
+		ScheduledTime: time.Now(),
+		StartTime:     timePtr(time.Now().Add(-2 * time.Second)),
+		Status:        models.ExecutionStatusRunning,
+	}).Error)
+
+	percent := 50.0
+	require.NoError(t, runner.ReportProgress(ctx, "exec-1", executor.ExecutionProgressRequest{
+		ExecutionID: "exec-1",
+		Attempt:     intPtr(0),
+		Percent:     &percent,
+		Stage:       "load",
+		Counters:    map[string]int64{"rows": 10},
+	}))
+	// 只上报心跳时保留已有进度
+	require.NoError(t, runner.ReportProgress(ctx, "exec-1", executor.ExecutionProgressRequest{Attempt: intPtr(0), ExecutionID: "exec-1"}))
+
+	// 开始时间已超过超时时长，但心跳之后尚未超时，定时器按心跳顺延
+	runner.handleTimeout("exec-1")
+	defer runner.cancelTimeout("exec-1")
+
+	var execution models.TaskExecution
+	require.NoError(t, st.DB().Where("id = ?", "exec-1").First(&execution).Error)
+	assert.Equal(t, models.ExecutionStatusRunning, execution.Status)
+	require.NotNil(t, execution.ProgressPercent)
+	assert.Equal(t, 50.0, *execution.ProgressPercent)
+	assert.Equal(t, "load", execution.ProgressStage)
+	assert.EqualValues(t, 10, execution.ProgressCounters["rows"])
+	require.NotNil(t, execution.HeartbeatAt)
+
+	runner.timeoutMu.Lock()
+	_, scheduled := runner.timeouts["exec-1"]
+	runner.timeoutMu.Unlock()
+	assert.True(t, scheduled)
+
+	// 结束后不再接受进度上报
+	require.NoError(t, runner.HandleCallback(ctx, "exec-1", executor.ExecutionCallbackRequest{Attempt: intPtr(0), Status: models.ExecutionStatusSuccess}))
+	err := runner.ReportProgress(ctx, "exec-1", executor.ExecutionProgressRequest{Attempt: intPtr(0), ExecutionID: "exec-1"})
+	assert.ErrorIs(t, err, ErrExecutionNotRunning)
+}
@@ -0,0 +1,245 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dispatch 分发协程：被唤醒或定期轮询时，按空闲工作协程数从数据库认领待分发的执行
+// 所有实例都运行分发协程，领导者宕机前创建的执行由存活的实例继续分发
+func (r *TaskRunner) dispatch() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.dispatchPending()
+
+		select {
+		case <-r.notifyCh:
+		case <-ticker.C:
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+// dispatchPending 认领并派发执行，直到没有空闲工作协程或没有可认领的执行
+func (r *TaskRunner) dispatchPending() {
+	for {
+		idle := r.maxWorkers - int(atomic.LoadInt32(&r.busy))
+		if idle <= 0 {
+			return
+		}
+
+		claimed, err := r.claimPending(idle)
+		if err != nil {
+			r.logger.Error("failed to claim pending executions", zap.Error(err))
+			return
+		}
+
+		for i := range claimed {
+			job, err := r.loadJob(&claimed[i])
+			if err != nil {
+				r.failExecution(&claimed[i], err.Error())
+				continue
+			}
+
+			atomic.AddInt32(&r.busy, 1)
+			select {
+			case r.jobCh <- job:
+			case <-r.stopCh:
+				atomic.AddInt32(&r.busy, -1)
+				r.releaseClaims(claimed[i:])
+				return
+			}
+		}
+
+		if len(claimed) < idle {
+			return
+		}
+	}
+}
+
+// claimPending 认领至多 limit 个待分发的执行，认领在 visibilityTimeout 后过期
+// 未被认领或认领已过期（认领实例宕机）的 pending 执行均可被认领；
+// 支持 SKIP LOCKED 的数据库上并发认领的实例互不阻塞，条件更新保证同一执行不会被重复认领；
+// 认领递增版本号，被重新认领后原认领实例持有的记录版本失效
+func (r *TaskRunner) claimPending(limit int) ([]models.TaskExecution, error) {
+	now := time.Now()
+	until := now.Add(r.visibilityTimeout)
+
+	var claimed []models.TaskExecution
+	err := r.storage.DB().Transaction(func(tx *gorm.DB) error {
+		query := tx.
+			Where("status = ? AND (claimed_until IS NULL OR claimed_until < ?)", models.ExecutionStatusPending, now).
+			Order("scheduled_time, created_at").
+			Limit(limit)
+		if r.storage.Dialect().SkipLocked() {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+
+		var candidates []models.TaskExecution
+		if err := query.Find(&candidates).Error; err != nil {
+			return err
+		}
+
+		for i := range candidates {
+			execution := &candidates[i]
+			result := tx.Model(&models.TaskExecution{}).
+				Where("id = ? AND status = ? AND (claimed_until IS NULL OR claimed_until < ?)",
+					execution.ID, models.ExecutionStatusPending, now).
+				Updates(map[string]interface{}{
+					"claimed_by":    r.instanceID,
+					"claimed_until": until,
+					"version":       gorm.Expr("version + 1"),
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+
+			if execution.ClaimedBy != nil {
+				r.logger.Warn("reclaiming execution from expired claim",
+					zap.String("execution_id", execution.ID),
+					zap.String("previous_claimer", *execution.ClaimedBy))
+			}
+
+			execution.ClaimedBy = &r.instanceID
+			execution.ClaimedUntil = &until
+			execution.Version++
+			claimed = append(claimed, *execution)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// startExecution 将本实例认领的执行转为运行中，执行已被取消或已被其他实例重新认领（版本号变化）时返回 false
+// 认领在调用执行器期间继续有效，领导者据此区分仍在分发中的执行和分发实例已宕机的执行
+func (r *TaskRunner) startExecution(execution *models.TaskExecution) (bool, error) {
+	now := time.Now()
+	until := now.Add(r.visibilityTimeout)
+	err := transitionExecution(r.storage.DB(), execution, models.ExecutionStatusRunning, map[string]interface{}{
+		"start_time":    now,
+		"claimed_until": until,
+	})
+	if errors.Is(err, ErrExecutionConflict) || errors.Is(err, ErrIllegalTransition) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	execution.StartTime = &now
+	execution.ClaimedUntil = &until
+	return true, nil
+}
+
+// extendClaim 重试退避前延长认领，避免等待期间被当作分发实例已宕机
+func (r *TaskRunner) extendClaim(execution *models.TaskExecution, backoff time.Duration) {
+	until := time.Now().Add(backoff + r.visibilityTimeout)
+	execution.ClaimedUntil = &until
+	if err := r.storage.DB().Model(&models.TaskExecution{}).
+		Where("id = ?", execution.ID).
+		UpdateColumn("claimed_until", until).Error; err != nil {
+		r.logger.Error("failed to extend execution claim",
+			zap.String("execution_id", execution.ID),
+			zap.Error(err))
+	}
+}
+
+// releaseDispatch 执行器已接收执行，清除认领有效期
+func (r *TaskRunner) releaseDispatch(execution *models.TaskExecution) {
+	execution.ClaimedUntil = nil
+	if err := r.storage.DB().Model(&models.TaskExecution{}).
+		Where("id = ?", execution.ID).
+		UpdateColumn("claimed_until", nil).Error; err != nil {
+		r.logger.Error("failed to release execution claim",
+			zap.String("execution_id", execution.ID),
+			zap.Error(err))
+	}
+}
+
+// requeueExecution 将丢失的运行中执行重新排队，重试次数用尽时标记失败
+// 判定丢失后执行记录又发生变化（如回调已到达）时放弃重新排队
+func (r *TaskRunner) requeueExecution(execution *models.TaskExecution, maxRetry int, reason string) {
+	if execution.RetryCount >= maxRetry {
+		r.failExecution(execution, reason)
+		return
+	}
+
+	err := transitionExecution(r.storage.DB(), execution, models.ExecutionStatusPending, map[string]interface{}{
+		"executor_id":   nil,
+		"start_time":    nil,
+		"claimed_by":    nil,
+		"claimed_until": nil,
+		"retry_count":   execution.RetryCount + 1,
+		"logs":          reason,
+		// 进度属于上一次尝试
+		"progress_percent":  nil,
+		"progress_stage":    "",
+		"progress_counters": nil,
+		"heartbeat_at":      nil,
+	})
+	if errors.Is(err, ErrExecutionConflict) || errors.Is(err, ErrIllegalTransition) {
+		r.logger.Info("execution changed since it was found lost, not requeued",
+			zap.String("execution_id", execution.ID))
+		return
+	}
+	if err != nil {
+		r.logger.Error("failed to requeue execution",
+			zap.String("execution_id", execution.ID),
+			zap.Error(err))
+		return
+	}
+
+	r.logger.Warn("execution requeued",
+		zap.String("execution_id", execution.ID),
+		zap.Int("retry_count", execution.RetryCount+1),
+		zap.String("reason", reason))
+
+	r.wake()
+}
+
+// releaseClaims 释放已认领但未派发的执行，使其他实例无需等待认领过期
+func (r *TaskRunner) releaseClaims(executions []models.TaskExecution) {
+	ids := make([]string, 0, len(executions))
+	for _, execution := range executions {
+		ids = append(ids, execution.ID)
+	}
+
+	if err := r.storage.DB().Model(&models.TaskExecution{}).
+		Where("id IN ? AND status = ? AND claimed_by = ?", ids, models.ExecutionStatusPending, r.instanceID).
+		Updates(map[string]interface{}{
+			"claimed_by":    nil,
+			"claimed_until": nil,
+			"version":       gorm.Expr("version + 1"),
+		}).Error; err != nil {
+		r.logger.Error("failed to release claimed executions",
+			zap.Strings("execution_ids", ids),
+			zap.Error(err))
+	}
+}
+
+// loadJob 从数据库加载执行对应的任务，执行参数已随执行记录保存
+func (r *TaskRunner) loadJob(execution *models.TaskExecution) (*taskJob, error) {
+	var task models.Task
+	if err := r.storage.DB().Where("id = ?", execution.TaskID).First(&task).Error; err != nil {
+		return nil, fmt.Errorf("failed to load task %s: %w", execution.TaskID, err)
+	}
+	return &taskJob{task: &task, execution: execution}, nil
+}
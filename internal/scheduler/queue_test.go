@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/internal/storage"
+	"github.com/jobs/scheduler/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newQueueRunner(t *testing.T, st *storage.Storage, instanceID string, visibility time.Duration) *TaskRunner {
+	t.Helper()
+	return NewTaskRunner(st, nil, nil, zap.NewNop(), config.SchedulerConfig{
+		InstanceID:        instanceID,
+		MaxWorkers:        2,
+		VisibilityTimeout: visibility,
+	})
+}
+
+func newTestStorage(t *testing.T) *storage.Storage {
+	t.Helper()
+	st, err := storage.New(storage.Config{
+		Driver:   storage.DriverSQLite,
+		Database: filepath.Join(t.TempDir(), "scheduler.db"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+func TestClaimPendingReclaimsExpiredClaims(t *testing.T) {
+	st := newTestStorage(t)
+
+	task := models.Task{ID: "task-1", Name: "task-1", CronExpression: "0 * * * * *"}
+	require.NoError(t, st.DB().Create(&task).Error)
+	for _, id := range []string{"exec-1", "exec-2", "exec-3"} {
+		require.NoError(t, st.DB().Create(&models.TaskExecution{
+			ID:            id,
+			TaskID:        task.ID,
+			ScheduledTime: time.Now(),
+			Status:        models.ExecutionStatusPending,
+		}).Error)
+	}
+
+	a := newQueueRunner(t, st, "a", 200*time.Millisecond)
+	b := newQueueRunner(t, st, "b", time.Minute)
+
+	// 已被认领的执行不会被重复认领
+	claimed, err := a.claimPending(2)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+
+	claimedByB, err := b.claimPending(10)
+	require.NoError(t, err)
+	require.Len(t, claimedByB, 1)
+	assert.Equal(t, "exec-3", claimedByB[0].ID)
+
+	// a 宕机，认领过期后由 b 接管，a 不能再开始执行
+	time.Sleep(300 * time.Millisecond)
+	claimedByB, err = b.claimPending(10)
+	require.NoError(t, err)
+	assert.Len(t, claimedByB, 2)
+
+	started, err := a.startExecution(&claimed[0])
+	require.NoError(t, err)
+	assert.False(t, started)
+
+	started, err = b.startExecution(&claimedByB[0])
+	require.NoError(t, err)
+	assert.True(t, started)
+
+	var execution models.TaskExecution
+	require.NoError(t, st.DB().Where("id = ?", claimedByB[0].ID).First(&execution).Error)
+	assert.Equal(t, models.ExecutionStatusRunning, execution.Status)
+	assert.Equal(t, "b", *execution.ClaimedBy)
+
+	// 释放后立即可被其他实例认领
+	b.releaseClaims(claimedByB[1:])
+	claimed, err = a.claimPending(10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, claimedByB[1].ID, claimed[0].ID)
+}
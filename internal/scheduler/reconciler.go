@@ -0,0 +1,298 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/pkg/cronexpr"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// reconcileLookback 轮询变更时向前回看的时间，容忍各实例间的时钟偏差
+// 重复处理同一任务是幂等的，因此回看窗口只影响查询量
+const reconcileLookback = time.Minute
+
+// cronEntry 任务在cron中的注册信息
+type cronEntry struct {
+	id   cron.EntryID
+	spec string
+}
+
+// reconcileTasks 全量对账：移除不再活跃任务的条目，新增或更新活跃任务的条目
+func (s *Scheduler) reconcileTasks() error {
+	startedAt := time.Now()
+
+	var tasks []models.Task
+	if err := s.storage.DB().
+		Where("status = ?", models.TaskStatusActive).
+		Find(&tasks).Error; err != nil {
+		return fmt.Errorf("failed to load tasks: %w", err)
+	}
+
+	dependent, err := s.dependentTasks()
+	if err != nil {
+		return err
+	}
+
+	active := make(map[string]struct{}, len(tasks))
+	for _, task := range tasks {
+		active[task.ID] = struct{}{}
+	}
+
+	s.entriesMu.Lock()
+	for taskID := range s.entries {
+		if _, ok := active[taskID]; !ok {
+			s.removeEntryLocked(taskID)
+		}
+	}
+	s.entriesMu.Unlock()
+
+	for i := range tasks {
+		s.applyTask(&tasks[i], dependent[tasks[i].ID])
+	}
+
+	s.entriesMu.Lock()
+	s.syncedAt = startedAt
+	s.entriesMu.Unlock()
+
+	s.logger.Info("reconciled scheduled tasks",
+		zap.Int("count", len(tasks)))
+
+	return nil
+}
+
+// reconcileLoop 领导者定期轮询 updated_at 变化的任务，感知其他实例上的修改
+func (s *Scheduler) reconcileLoop() {
+	defer s.wg.Done()
+
+	interval := s.config.ReconcileInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !s.isLeader.Load() {
+				continue
+			}
+			if err := s.syncChangedTasks(); err != nil {
+				s.logger.Error("failed to sync changed tasks", zap.Error(err))
+			}
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// syncChangedTasks 只处理上次同步以来有修改的任务
+func (s *Scheduler) syncChangedTasks() error {
+	startedAt := time.Now()
+
+	s.entriesMu.Lock()
+	since := s.syncedAt.Add(-reconcileLookback)
+	s.entriesMu.Unlock()
+
+	var tasks []models.Task
+	if err := s.storage.DB().
+		Where("updated_at >= ?", since).
+		Find(&tasks).Error; err != nil {
+		return fmt.Errorf("failed to load changed tasks: %w", err)
+	}
+	if len(tasks) == 0 {
+		s.entriesMu.Lock()
+		s.syncedAt = startedAt
+		s.entriesMu.Unlock()
+		return nil
+	}
+
+	dependent, err := s.dependentTasks()
+	if err != nil {
+		return err
+	}
+	for i := range tasks {
+		s.applyTask(&tasks[i], dependent[tasks[i].ID])
+	}
+
+	s.entriesMu.Lock()
+	s.syncedAt = startedAt
+	s.entriesMu.Unlock()
+
+	return nil
+}
+
+// SyncTask 任务变更后立即更新该任务的cron条目
+// 非领导者不持有cron条目，变更由领导者轮询获得
+func (s *Scheduler) SyncTask(taskID string) error {
+	if !s.isLeader.Load() {
+		return nil
+	}
+
+	var task models.Task
+	err := s.storage.DB().Where("id = ?", taskID).First(&task).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		s.entriesMu.Lock()
+		s.removeEntryLocked(taskID)
+		s.entriesMu.Unlock()
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load task: %w", err)
+	}
+
+	dependent, err := s.hasUpstream(taskID)
+	if err != nil {
+		return err
+	}
+	s.applyTask(&task, dependent)
+	return nil
+}
+
+// dependentTasks 有上游依赖的任务，这些任务只由上游触发，不注册cron条目
+func (s *Scheduler) dependentTasks() (map[string]bool, error) {
+	var ids []string
+	if err := s.storage.DB().
+		Model(&models.TaskDependency{}).
+		Distinct("task_id").
+		Pluck("task_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to load task dependencies: %w", err)
+	}
+
+	dependent := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		dependent[id] = true
+	}
+	return dependent, nil
+}
+
+// hasUpstream 任务是否有上游依赖
+func (s *Scheduler) hasUpstream(taskID string) (bool, error) {
+	var count int64
+	if err := s.storage.DB().
+		Model(&models.TaskDependency{}).
+		Where("task_id = ?", taskID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to load task dependencies: %w", err)
+	}
+	return count > 0, nil
+}
+
+// applyTask 按任务当前定义新增、更新或移除其cron条目，重复调用无副作用
+// 有上游依赖的任务（dependent）只在上游结束后由依赖引擎触发，其cron表达式不生效
+func (s *Scheduler) applyTask(task *models.Task, dependent bool) {
+	s.entriesMu.Lock()
+	defer s.entriesMu.Unlock()
+
+	if task.Status != models.TaskStatusActive || dependent {
+		s.removeEntryLocked(task.ID)
+		return
+	}
+
+	spec := entrySpec(task)
+	if entry, ok := s.entries[task.ID]; ok {
+		if entry.spec == spec {
+			return
+		}
+		s.removeEntryLocked(task.ID)
+	}
+
+	schedule, err := cronexpr.Parse(task.CronExpression, task.Timezone)
+	if err != nil {
+		s.logger.Error("failed to add cron job",
+			zap.String("task_id", task.ID),
+			zap.String("task_name", task.Name),
+			zap.Error(err))
+		return
+	}
+
+	// 闭包只捕获任务ID，触发时重新加载最新定义
+	taskID := task.ID
+	entryID := s.cron.Schedule(schedule, cron.FuncJob(func() {
+		s.fireTask(taskID)
+	}))
+
+	s.entries[task.ID] = cronEntry{id: entryID, spec: spec}
+
+	s.logger.Info("scheduled task",
+		zap.String("task_id", task.ID),
+		zap.String("task_name", task.Name),
+		zap.String("cron", spec),
+		zap.Int("entry_id", int(entryID)))
+}
+
+// entrySpec 条目对应的调度定义，表达式或时区变化都需要重新注册
+func entrySpec(task *models.Task) string {
+	if task.Timezone == "" {
+		return task.CronExpression
+	}
+	return "CRON_TZ=" + task.Timezone + " " + task.CronExpression
+}
+
+// removeEntryLocked 移除任务的cron条目，调用方需持有 entriesMu
+func (s *Scheduler) removeEntryLocked(taskID string) {
+	entry, ok := s.entries[taskID]
+	if !ok {
+		return
+	}
+
+	s.cron.Remove(entry.id)
+	delete(s.entries, taskID)
+
+	s.logger.Info("unscheduled task",
+		zap.String("task_id", taskID),
+		zap.Int("entry_id", int(entry.id)))
+}
+
+// fireTask cron触发入口：加载任务最新定义后再调度
+// 若任务已停用、已有上游依赖或表达式已变化（变更尚未同步），则先对齐条目，本次不执行
+func (s *Scheduler) fireTask(taskID string) {
+	var task models.Task
+	if err := s.storage.DB().Where("id = ?", taskID).First(&task).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.entriesMu.Lock()
+			s.removeEntryLocked(taskID)
+			s.entriesMu.Unlock()
+			return
+		}
+		s.logger.Error("failed to load task at fire time",
+			zap.String("task_id", taskID),
+			zap.Error(err))
+		return
+	}
+
+	s.entriesMu.Lock()
+	entry, ok := s.entries[taskID]
+	s.entriesMu.Unlock()
+	if !ok {
+		// 条目已在触发期间被移除
+		return
+	}
+
+	dependent, err := s.hasUpstream(taskID)
+	if err != nil {
+		s.logger.Error("failed to load task dependencies at fire time",
+			zap.String("task_id", taskID),
+			zap.Error(err))
+		return
+	}
+	if task.Status != models.TaskStatusActive || dependent || entry.spec != entrySpec(&task) {
+		s.applyTask(&task, dependent)
+		return
+	}
+
+	// cron在启动任务前已将 Prev 更新为本次计划时间
+	scheduledTime := s.cron.Entry(entry.id).Prev
+	if scheduledTime.IsZero() {
+		scheduledTime = time.Now().Truncate(time.Second)
+	}
+
+	s.markScheduled(task.ID, scheduledTime)
+	s.scheduleTask(&task, scheduledTime)
+}
@@ -0,0 +1,115 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"go.uber.org/zap"
+)
+
+// probeTimeout 接管时探测单个执行器的超时时间
+const probeTimeout = 5 * time.Second
+
+// recoverExecutions 成为领导者后接管未结束的执行
+// 超时定时器只存在于分发实例的内存中，实例宕机后由领导者按开始时间或最近一次心跳加 TimeoutSeconds 重建截止时间，
+// 并探测所属执行器、查询执行状态，将每个运行中的执行判定为超时、重新排队、按执行器报告结束或继续跟踪；
+// 执行器不可达的执行同样继续跟踪，宽限期内仍不可达时由状态查询重新排队
+func (s *Scheduler) recoverExecutions() error {
+	var executions []models.TaskExecution
+	if err := s.storage.DB().
+		Preload("Task").
+		Preload("Executor").
+		Where("status IN ?", []models.ExecutionStatus{models.ExecutionStatusPending, models.ExecutionStatusRunning}).
+		Find(&executions).Error; err != nil {
+		return fmt.Errorf("failed to load unfinished executions: %w", err)
+	}
+
+	now := time.Now()
+	stalePending := 0
+	for i := range executions {
+		execution := &executions[i]
+		if execution.Status == models.ExecutionStatusPending {
+			// 待分发的执行由分发队列认领，认领过期的会被重新认领
+			if execution.ClaimedUntil != nil && execution.ClaimedUntil.Before(now) {
+				stalePending++
+			}
+			continue
+		}
+		s.recoverRunning(execution, now)
+	}
+
+	if stalePending > 0 {
+		s.logger.Info("pending executions with expired claims will be reclaimed",
+			zap.Int("count", stalePending))
+	}
+	s.taskRunner.wake()
+
+	return nil
+}
+
+// recoverRunning 处理单个运行中的执行
+func (s *Scheduler) recoverRunning(execution *models.TaskExecution, now time.Time) {
+	task := execution.Task
+	executor := execution.Executor
+	// 关联对象只用于判定，避免随执行记录一起保存
+	execution.Task = nil
+	execution.Executor = nil
+
+	if task == nil {
+		return
+	}
+
+	// 仍在认领有效期内：分发实例正在调用执行器
+	if execution.ClaimedUntil != nil && !execution.ClaimedUntil.Before(now) {
+		return
+	}
+
+	// 认领过期：分发实例在执行器接收前宕机
+	if execution.ClaimedUntil != nil {
+		s.taskRunner.requeueExecution(execution, task.MaxRetry, "dispatching scheduler instance lost before executor accepted the execution")
+		return
+	}
+
+	var deadline time.Time
+	if task.TimeoutSeconds > 0 && execution.StartTime != nil {
+		deadline = execution.Deadline(time.Duration(task.TimeoutSeconds) * time.Second)
+		if !deadline.After(now) {
+			s.taskRunner.handleTimeout(execution.ID)
+			return
+		}
+	}
+
+	if executor == nil {
+		s.taskRunner.requeueExecution(execution, task.MaxRetry, "executor no longer exists")
+		return
+	}
+
+	// 按原截止时间重建超时，执行器不可达时截止时间一到同样按超时处理
+	if !deadline.IsZero() {
+		s.taskRunner.scheduleTimeout(execution.ID, deadline.Sub(now))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	if !s.healthChecker.Probe(ctx, executor) {
+		// 单次探测失败可能只是短暂的网络故障：继续跟踪，与状态查询共用丢失宽限期，
+		// 执行器持续不可达超过 lost_grace_period 才重新排队
+		s.markLost(execution, task, fmt.Sprintf("executor %s unreachable after leader takeover", executor.ID), now)
+		return
+	}
+
+	// 执行器仍在线：查询执行状态，期间已结束的按回调处理
+
+	status, err := s.taskRunner.QueryStatus(ctx, execution, executor)
+	s.applyExecutorStatus(execution, task, status, err, now)
+	if err == nil && status.Status.IsTerminal() {
+		return
+	}
+
+	s.logger.Info("tracking recovered execution",
+		zap.String("execution_id", execution.ID),
+		zap.String("executor_id", executor.ID),
+		zap.Time("deadline", deadline))
+}
@@ -0,0 +1,119 @@
+package scheduler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/executor"
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRecoverExecutions(t *testing.T) {
+	st := newTestStorage(t)
+
+	alive := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer alive.Close()
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	require.NoError(t, st.DB().Create(&models.Task{ID: "task-1", Name: "task-1", CronExpression: "0 * * * * *", MaxRetry: 1, TimeoutSeconds: 60}).Error)
+	require.NoError(t, st.DB().Create(&models.Task{ID: "task-2", Name: "task-2", CronExpression: "0 * * * * *"}).Error)
+	require.NoError(t, st.DB().Create(&models.Executor{ID: "alive", Name: "alive", InstanceID: "alive", BaseURL: alive.URL}).Error)
+	require.NoError(t, st.DB().Create(&models.Executor{ID: "dead", Name: "dead", InstanceID: "dead", BaseURL: dead.URL}).Error)
+
+	now := time.Now()
+	started := now.Add(-30 * time.Second)
+	expired := now.Add(-time.Second)
+	aliveID, deadID := "alive", "dead"
+	for _, execution := range []models.TaskExecution{
+		// 分发实例在执行器接收前宕机：重新排队
+		{ID: "dispatching", TaskID: "task-1", ClaimedUntil: &expired},
+		// 超过 StartTime + TimeoutSeconds：超时
+		{ID: "overdue", TaskID: "task-1", ExecutorID: &aliveID, StartTime: timePtr(now.Add(-2 * time.Minute))},
+		// 执行器在线：继续跟踪
+		{ID: "tracked", TaskID: "task-1", ExecutorID: &aliveID},
+		// 执行器不可达：宽限期内继续跟踪
+		{ID: "unreachable", TaskID: "task-1", ExecutorID: &deadID},
+		// 执行器持续不可达且重试次数已用尽：宽限期后失败
+		{ID: "lost", TaskID: "task-2", ExecutorID: &deadID, RetryCount: 3},
+	} {
+		execution.ScheduledTime = now
+		execution.Status = models.ExecutionStatusRunning
+		if execution.StartTime == nil {
+			execution.StartTime = &started
+		}
+		require.NoError(t, st.DB().Create(&execution).Error)
+	}
+
+	logger := zap.NewNop()
+	s := &Scheduler{
+		config:        config.SchedulerConfig{LostGracePeriod: time.Minute, StatusPollInterval: time.Second},
+		lostSince:     make(map[string]time.Time),
+		storage:       st,
+		logger:        logger,
+		taskRunner:    NewTaskRunner(st, nil, nil, logger, config.SchedulerConfig{InstanceID: "leader", MaxWorkers: 1}),
+		healthChecker: executor.NewHealthChecker(st, logger, config.HealthCheckConfig{Timeout: time.Second}),
+	}
+	require.NoError(t, s.recoverExecutions())
+
+	status := func(id string) models.TaskExecution {
+		var execution models.TaskExecution
+		require.NoError(t, st.DB().Where("id = ?", id).First(&execution).Error)
+		return execution
+	}
+
+	requeued := status("dispatching")
+	assert.Equal(t, models.ExecutionStatusPending, requeued.Status)
+	assert.Equal(t, 1, requeued.RetryCount)
+	assert.Nil(t, requeued.ClaimedUntil)
+
+	assert.Equal(t, models.ExecutionStatusTimeout, status("overdue").Status)
+	assert.Equal(t, models.ExecutionStatusRunning, status("tracked").Status)
+
+	// 单次探测失败不重新排队，也不清除执行器
+	for _, id := range []string{"unreachable", "lost"} {
+		execution := status(id)
+		assert.Equal(t, models.ExecutionStatusRunning, execution.Status, id)
+		require.NotNil(t, execution.ExecutorID, id)
+		assert.Equal(t, deadID, *execution.ExecutorID, id)
+	}
+
+	// 继续跟踪的执行按原截止时间重建了超时定时器，包括执行器不可达的执行
+	armed := func(id string) bool {
+		s.taskRunner.timeoutMu.RLock()
+		defer s.taskRunner.timeoutMu.RUnlock()
+		_, ok := s.taskRunner.timeouts[id]
+		return ok
+	}
+	assert.True(t, armed("tracked"))
+	assert.True(t, armed("unreachable"))
+
+	// 执行器在宽限期后仍不可达：状态查询判定丢失，重新排队或在重试用尽时失败
+	s.lostMu.Lock()
+	for id := range s.lostSince {
+		s.lostSince[id] = now.Add(-2 * time.Minute)
+	}
+	s.lostMu.Unlock()
+	require.NoError(t, s.pollRunningExecutions())
+
+	requeued = status("unreachable")
+	assert.Equal(t, models.ExecutionStatusPending, requeued.Status)
+	assert.Equal(t, 1, requeued.RetryCount)
+	assert.False(t, armed("unreachable"))
+	assert.Equal(t, models.ExecutionStatusFailed, status("lost").Status)
+	assert.Equal(t, models.ExecutionStatusRunning, status("tracked").Status)
+
+	s.taskRunner.cancelTimeout("tracked")
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/internal/storage"
+	"github.com/jobs/scheduler/pkg/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func newManualScheduler(t *testing.T) (*Scheduler, *storage.Storage) {
+	t.Helper()
+	st := newTestStorage(t)
+	logger := zap.NewNop()
+	runner := newQueueRunner(t, st, "a", time.Minute)
+	locker := NewLeaseLocker(st, "test", "a", time.Minute, logger)
+	dag := NewDAGEngine(st, runner, logger)
+	backfills := NewBackfillEngine(st, runner, dag, locker, logger)
+	runner.SetDAGEngine(dag)
+	runner.SetBackfillEngine(backfills)
+	return &Scheduler{
+		storage:    st,
+		logger:     logger,
+		locker:     locker,
+		taskRunner: runner,
+		dag:        dag,
+		backfills:  backfills,
+	}, st
+}
+
+func TestTriggerAndRerunKeepExecutionParameters(t *testing.T) {
+	s, st := newManualScheduler(t)
+	ctx := context.Background()
+
+	task := models.Task{
+		ID:             "task-1",
+		Name:           "task-1",
+		CronExpression: "0 * * * * *",
+		Parameters:     models.JSONMap{"date": "today", "limit": float64(10)},
+	}
+	require.NoError(t, st.DB().Create(&task).Error)
+
+	// 手动覆盖只记录在执行上，不修改任务默认参数
+	triggered, err := s.TriggerTask(ctx, task.ID, map[string]interface{}{"date": "2024-01-01"})
+	require.NoError(t, err)
+	assert.Equal(t, models.JSONMap{"date": "2024-01-01", "limit": float64(10)}, triggered.Parameters)
+
+	var stored models.Task
+	require.NoError(t, st.DB().Where("id = ?", task.ID).First(&stored).Error)
+	assert.Equal(t, task.Parameters, stored.Parameters)
+
+	// 未结束的执行不能重跑
+	_, err = s.RerunExecution(ctx, triggered.ID, nil)
+	assert.ErrorIs(t, err, ErrExecutionNotFinished)
+	_, err = s.RerunExecution(ctx, "missing", nil)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	require.NoError(t, st.DB().Model(&models.TaskExecution{}).
+		Where("id = ?", triggered.ID).
+		Update("status", models.ExecutionStatusFailed).Error)
+
+	// 修改任务默认参数后，原样重跑仍使用原执行的参数
+	require.NoError(t, st.DB().Model(&stored).Update("parameters", models.JSONMap{"date": "yesterday"}).Error)
+	same, err := s.RerunExecution(ctx, triggered.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, triggered.Parameters, same.Parameters)
+	require.NotNil(t, same.RerunOfExecutionID)
+	assert.Equal(t, triggered.ID, *same.RerunOfExecutionID)
+
+	edited, err := s.RerunExecution(ctx, triggered.ID, map[string]interface{}{"date": "2024-01-02"})
+	require.NoError(t, err)
+
+	var reloaded models.TaskExecution
+	require.NoError(t, st.DB().Where("id = ?", edited.ID).First(&reloaded).Error)
+	assert.Equal(t, models.JSONMap{"date": "2024-01-02"}, reloaded.Parameters)
+	assert.Equal(t, models.ExecutionStatusPending, reloaded.Status)
+	assert.Equal(t, models.JSONMap{"date": "2024-01-02"}, executionParameters(&stored, &reloaded))
+}
+
+func TestTriggerValidatesParametersSchema(t *testing.T) {
+	s, st := newManualScheduler(t)
+	ctx := context.Background()
+
+	task := models.Task{
+		ID:             "task-1",
+		Name:           "task-1",
+		CronExpression: "0 * * * * *",
+		Parameters:     models.JSONMap{"date": "2024-01-01"},
+		ParametersSchema: models.JSONMap{
+			"type":     "object",
+			"required": []interface{}{"date"},
+			"properties": map[string]interface{}{
+				"date":  map[string]interface{}{"type": "string", "format": "date"},
+				"limit": map[string]interface{}{"type": "integer", "minimum": 1},
+			},
+			"additionalProperties": false,
+		},
+	}
+	require.NoError(t, st.DB().Create(&task).Error)
+
+	_, err := s.TriggerTask(ctx, task.ID, map[string]interface{}{"limit": 0, "dryrun": true})
+	var verr *jsonschema.ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []jsonschema.FieldError{
+		{Field: "dryrun", Message: "is not allowed"},
+		{Field: "limit", Message: "must be >= 1"},
+	}, verr.Errors)
+
+	var count int64
+	st.DB().Model(&models.TaskExecution{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	execution, err := s.TriggerTask(ctx, task.ID, map[string]interface{}{"limit": 5})
+	require.NoError(t, err)
+	require.NoError(t, st.DB().Model(execution).Update("status", models.ExecutionStatusSuccess).Error)
+
+	// 编辑后的参数同样需要满足 schema
+	_, err = s.RerunExecution(ctx, execution.ID, map[string]interface{}{"limit": 5})
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, []jsonschema.FieldError{{Field: "date", Message: "is required"}}, verr.Errors)
+}
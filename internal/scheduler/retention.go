@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// retentionInterval 清理过期执行的间隔
+	retentionInterval = time.Hour
+	// retentionBatch 每个事务删除的执行数
+	retentionBatch = 500
+)
+
+// retentionLoop 领导者定期删除结束超过 execution_retention 的执行及其日志
+func (s *Scheduler) retentionLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(retentionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !s.isLeader.Load() || s.config.ExecutionRetention <= 0 {
+				continue
+			}
+			purged, err := s.purgeExecutions(time.Now().Add(-s.config.ExecutionRetention))
+			if err != nil {
+				s.logger.Error("failed to purge expired executions", zap.Error(err))
+			}
+			if purged > 0 {
+				s.logger.Info("expired executions purged",
+					zap.Int("count", purged),
+					zap.Duration("retention", s.config.ExecutionRetention))
+			}
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// purgeExecutions 删除在 cutoff 之前结束的执行及其日志块
+// 仍在运行的工作流中的执行需要参与依赖判定，待运行结束后再清理
+func (s *Scheduler) purgeExecutions(cutoff time.Time) (int, error) {
+	terminal := []models.ExecutionStatus{
+		models.ExecutionStatusSuccess,
+		models.ExecutionStatusFailed,
+		models.ExecutionStatusTimeout,
+		models.ExecutionStatusCancelled,
+		models.ExecutionStatusSkipped,
+	}
+	activeRuns := s.storage.DB().Model(&models.WorkflowRun{}).
+		Select("id").
+		Where("status = ?", models.WorkflowRunStatusRunning)
+
+	purged := 0
+	for {
+		var ids []string
+		if err := s.storage.DB().Model(&models.TaskExecution{}).
+			Where("status IN ? AND end_time < ?", terminal, cutoff).
+			Where("workflow_run_id IS NULL OR workflow_run_id NOT IN (?)", activeRuns).
+			Order("end_time").
+			Limit(retentionBatch).
+			Pluck("id", &ids).Error; err != nil {
+			return purged, fmt.Errorf("failed to load expired executions: %w", err)
+		}
+		if len(ids) == 0 {
+			return purged, nil
+		}
+
+		if err := s.storage.DB().Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("execution_id IN ?", ids).Delete(&models.ExecutionLogChunk{}).Error; err != nil {
+				return err
+			}
+			return tx.Where("id IN ?", ids).Delete(&models.TaskExecution{}).Error
+		}); err != nil {
+			return purged, fmt.Errorf("failed to delete expired executions: %w", err)
+		}
+
+		purged += len(ids)
+		if len(ids) < retentionBatch {
+			return purged, nil
+		}
+	}
+}
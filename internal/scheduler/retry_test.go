@@ -0,0 +1,250 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/executor"
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	// 未配置时为 1s 起翻倍、最长 30s
+	var none *models.RetryPolicy
+	assert.Equal(t, time.Second, none.Delay(1))
+	assert.Equal(t, 8*time.Second, none.Delay(4))
+	assert.Equal(t, 30*time.Second, none.Delay(10))
+	assert.False(t, none.RetriesOn(models.ExecutionStatusFailed))
+
+	fixed := &models.RetryPolicy{Backoff: models.RetryBackoffFixed, InitialDelaySeconds: 5}
+	assert.Equal(t, 5*time.Second, fixed.Delay(1))
+	assert.Equal(t, 5*time.Second, fixed.Delay(7))
+
+	exponential := &models.RetryPolicy{InitialDelaySeconds: 10, MaxDelaySeconds: 60}
+	assert.Equal(t, 10*time.Second, exponential.Delay(1))
+	assert.Equal(t, 40*time.Second, exponential.Delay(3))
+	assert.Equal(t, 60*time.Second, exponential.Delay(4))
+	assert.Equal(t, 60*time.Second, exponential.Delay(1000))
+
+	jittered := &models.RetryPolicy{Backoff: models.RetryBackoffJittered, InitialDelaySeconds: 10, MaxDelaySeconds: 60}
+	for i := 0; i < 20; i++ {
+		delay := jittered.Delay(3)
+		assert.GreaterOrEqual(t, delay, 20*time.Second)
+		assert.LessOrEqual(t, delay, 40*time.Second)
+	}
+
+	for name, policy := range map[string]models.RetryPolicy{
+		"backoff":  {Backoff: "linear"},
+		"negative": {InitialDelaySeconds: -1},
+		"max":      {InitialDelaySeconds: 10, MaxDelaySeconds: 5},
+		"retry_on": {RetryOn: []models.ExecutionStatus{models.ExecutionStatusCancelled}},
+	} {
+		assert.Error(t, policy.Validate(), name)
+	}
+	assert.NoError(t, (&models.RetryPolicy{RetryOn: []models.ExecutionStatus{models.ExecutionStatusFailed, models.ExecutionStatusTimeout}}).Validate())
+}
+
+func TestCallbackRetriesPerPolicy(t *testing.T) {
+	st := newTestStorage(t)
+	runner := newQueueRunner(t, st, "a", time.Minute)
+	ctx := context.Background()
+
+	task := models.Task{
+		ID:             "task-1",
+		Name:           "task-1",
+		CronExpression: "0 * * * * *",
+		MaxRetry:       1,
+		RetryPolicy: &models.RetryPolicy{
+			Backoff:             models.RetryBackoffFixed,
+			InitialDelaySeconds: 60,
+			RetryOn:             []models.ExecutionStatus{models.ExecutionStatusFailed},
+		},
+	}
+	require.NoError(t, st.DB().Create(&task).Error)
+	for _, id := range []string{"exec-1", "exec-2"} {
+		require.NoError(t, st.DB().Create(&models.TaskExecution{
+			ID:            id,
+			TaskID:        task.ID,
+			ScheduledTime: time.Now(),
+			Status:        models.ExecutionStatusRunning,
+		}).Error)
+	}
+	load := func(id string) models.TaskExecution {
+		var execution models.TaskExecution
+		require.NoError(t, st.DB().Where("id = ?", id).First(&execution).Error)
+		return execution
+	}
+
+	// 上报的失败在重试策略内，执行重新排队并延后分发
+	failed := executor.ExecutionCallbackRequest{Attempt: intPtr(0), Status: models.ExecutionStatusFailed, Logs: "exit 1"}
+	require.NoError(t, runner.HandleCallback(ctx, "exec-1", failed))
+	execution := load("exec-1")
+	assert.Equal(t, models.ExecutionStatusPending, execution.Status)
+	assert.Equal(t, 1, execution.RetryCount)
+	assert.Nil(t, execution.ClaimedBy)
+	require.NotNil(t, execution.ClaimedUntil)
+	assert.True(t, execution.ClaimedUntil.After(time.Now().Add(50*time.Second)))
+	assert.Contains(t, execution.Logs, "Attempt 1 failed, retrying in 1m0s: exit 1")
+
+	claimed, err := runner.claimPending(10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	// 上一次尝试迟到或重复的回调视为已处理，不能结束等待重试的执行
+	require.NoError(t, runner.HandleCallback(ctx, "exec-1", executor.ExecutionCallbackRequest{Attempt: intPtr(0), Status: models.ExecutionStatusSuccess}))
+	execution = load("exec-1")
+	assert.Equal(t, models.ExecutionStatusPending, execution.Status)
+	assert.Equal(t, 1, execution.RetryCount)
+
+	// 重试次数用尽后按上报的状态结束
+	require.NoError(t, st.DB().Model(&models.TaskExecution{}).
+		Where("id = ?", "exec-1").
+		Update("status", models.ExecutionStatusRunning).Error)
+	failed.Attempt = intPtr(1)
+	require.NoError(t, runner.HandleCallback(ctx, "exec-1", failed))
+	execution = load("exec-1")
+	assert.Equal(t, models.ExecutionStatusFailed, execution.Status)
+	assert.Equal(t, 1, execution.RetryCount)
+
+	// 不在 retry_on 中的终态不重试
+	runner.handleTimeout("exec-2")
+	execution = load("exec-2")
+	assert.Equal(t, models.ExecutionStatusTimeout, execution.Status)
+	assert.Equal(t, 0, execution.RetryCount)
+}
+
+func TestCallbackForUndispatchedExecution(t *testing.T) {
+	st := newTestStorage(t)
+	runner := newQueueRunner(t, st, "a", time.Minute)
+
+	require.NoError(t, st.DB().Create(&models.Task{ID: "task-1", Name: "task-1", CronExpression: "0 * * * * *"}).Error)
+	require.NoError(t, st.DB().Create(&models.TaskExecution{
+		ID:            "exec-1",
+		TaskID:        "task-1",
+		ScheduledTime: time.Now(),
+		Status:        models.ExecutionStatusPending,
+	}).Error)
+
+	// 从未分发过的执行不会有执行器回调
+	err := runner.HandleCallback(context.Background(), "exec-1", executor.ExecutionCallbackRequest{Attempt: intPtr(0), Status: models.ExecutionStatusSuccess})
+	assert.ErrorIs(t, err, ErrIllegalTransition)
+}
+
+func TestCallbackRetryConflict(t *testing.T) {
+	st := newTestStorage(t)
+	runner := newQueueRunner(t, st, "a", time.Minute)
+	ctx := context.Background()
+
+	task := models.Task{
+		ID:             "task-1",
+		Name:           "task-1",
+		CronExpression: "0 * * * * *",
+		MaxRetry:       3,
+		RetryPolicy:    &models.RetryPolicy{RetryOn: []models.ExecutionStatus{models.ExecutionStatusFailed}},
+	}
+	require.NoError(t, st.DB().Create(&task).Error)
+	require.NoError(t, st.DB().Create(&models.TaskExecution{
+		ID:            "exec-1",
+		TaskID:        task.ID,
+		ScheduledTime: time.Now(),
+		Status:        models.ExecutionStatusRunning,
+	}).Error)
+
+	// 读取执行之后、重新排队之前，并发的重复回调先将本次尝试重新排队
+	var raced atomic.Bool
+	require.NoError(t, st.DB().Callback().Query().After("gorm:query").Register("test:concurrent_retry", func(db *gorm.DB) {
+		if db.Statement.Table != "task_executions" || !raced.CompareAndSwap(false, true) {
+			return
+		}
+		require.NoError(t, st.DB().Model(&models.TaskExecution{}).
+			Where("id = ?", "exec-1").
+			Updates(map[string]interface{}{
+				"status":      models.ExecutionStatusPending,
+				"retry_count": 1,
+				"version":     gorm.Expr("version + 1"),
+			}).Error)
+	}))
+	t.Cleanup(func() { st.DB().Callback().Query().Remove("test:concurrent_retry") })
+
+	failed := executor.ExecutionCallbackRequest{Attempt: intPtr(0), Status: models.ExecutionStatusFailed, Logs: "exit 1"}
+	require.NoError(t, runner.HandleCallback(ctx, "exec-1", failed))
+	require.True(t, raced.Load())
+
+	// 输掉版本竞争的回调视为重复回调，不再按终态结束执行，也不重复计入重试次数
+	var execution models.TaskExecution
+	require.NoError(t, st.DB().Where("id = ?", "exec-1").First(&execution).Error)
+	assert.Equal(t, models.ExecutionStatusPending, execution.Status)
+	assert.Equal(t, 1, execution.RetryCount)
+	assert.Equal(t, int64(1), execution.Version)
+}
+
+func TestStaleAttemptRequestsIgnored(t *testing.T) {
+	st := newTestStorage(t)
+	runner := newQueueRunner(t, st, "a", time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, st.DB().Create(&models.Task{ID: "task-1", Name: "task-1", CronExpression: "0 * * * * *", MaxRetry: 3}).Error)
+	// 第一次尝试超时后停止失败，重试又分发到同一执行器，第二次尝试运行中
+	heartbeat := time.Now().Add(-time.Hour)
+	require.NoError(t, st.DB().Create(&models.TaskExecution{
+		ID:            "exec-1",
+		TaskID:        "task-1",
+		ScheduledTime: time.Now(),
+		Status:        models.ExecutionStatusRunning,
+		RetryCount:    1,
+		HeartbeatAt:   &heartbeat,
+	}).Error)
+	load := func() models.TaskExecution {
+		var execution models.TaskExecution
+		require.NoError(t, st.DB().Where("id = ?", "exec-1").First(&execution).Error)
+		return execution
+	}
+
+	// 第一次尝试迟到的回调、进度和日志都视为重复，不影响第二次尝试
+	require.NoError(t, runner.HandleCallback(ctx, "exec-1", executor.ExecutionCallbackRequest{
+		ExecutionID: "exec-1", Attempt: intPtr(0), Status: models.ExecutionStatusSuccess, Logs: "stale",
+	}))
+	require.NoError(t, runner.ReportProgress(ctx, "exec-1", executor.ExecutionProgressRequest{ExecutionID: "exec-1", Attempt: intPtr(0)}))
+	require.NoError(t, runner.AppendLog(ctx, "exec-1", executor.ExecutionLogRequest{ExecutionID: "exec-1", Attempt: intPtr(0), Seq: 1, Content: "stale\n"}))
+	// 未回显 attempt 的请求同样不被采纳
+	require.NoError(t, runner.HandleCallback(ctx, "exec-1", executor.ExecutionCallbackRequest{ExecutionID: "exec-1", Status: models.ExecutionStatusFailed}))
+
+	execution := load()
+	assert.Equal(t, models.ExecutionStatusRunning, execution.Status)
+	assert.Empty(t, execution.Logs)
+	require.NotNil(t, execution.HeartbeatAt)
+	assert.True(t, execution.HeartbeatAt.Before(time.Now().Add(-time.Minute)))
+	var chunks int64
+	st.DB().Model(&models.ExecutionLogChunk{}).Where("execution_id = ?", "exec-1").Count(&chunks)
+	assert.Zero(t, chunks)
+
+	// 当前尝试的回调照常结束执行
+	require.NoError(t, runner.HandleCallback(ctx, "exec-1", executor.ExecutionCallbackRequest{
+		ExecutionID: "exec-1", Attempt: intPtr(1), Status: models.ExecutionStatusSuccess, Logs: "done",
+	}))
+	execution = load()
+	assert.Equal(t, models.ExecutionStatusSuccess, execution.Status)
+	assert.Equal(t, "done", execution.Logs)
+}
+
+// intPtr 返回整数的指针，用于回显 attempt
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestExcludeExecutors(t *testing.T) {
+	executors := []*models.Executor{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+
+	remaining := excludeExecutors(executors, map[string]bool{"a": true, "c": true})
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "b", remaining[0].ID)
+
+	// 全部失败过时仍使用原来的候选
+	assert.Equal(t, executors, excludeExecutors(executors, map[string]bool{"a": true, "b": true, "c": true}))
+	assert.Equal(t, executors, excludeExecutors(executors, nil))
+}
@@ -3,8 +3,12 @@ package scheduler
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"net"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,7 +27,7 @@ type Scheduler struct {
 	config          config.SchedulerConfig
 	storage         *storage.Storage
 	sqlDB           *sql.DB
-	locker          *Locker
+	locker          LeaderLock
 	cron            *cron.Cron
 	executorManager *executor.Manager
 	lbManager       *loadbalance.Manager
@@ -31,12 +35,27 @@ type Scheduler struct {
 	logger          *zap.Logger
 
 	instanceID string
-	isLeader   bool
+	isLeader   atomic.Bool // 由选举循环修改，对账、补跑、状态轮询等协程读取
 	stopCh     chan struct{}
 	wg         sync.WaitGroup
 
 	// 任务执行器
 	taskRunner *TaskRunner
+
+	// 任务依赖引擎
+	dag *DAGEngine
+
+	// 历史区间补跑
+	backfills *BackfillEngine
+
+	// 任务ID到cron条目的映射，以及上次同步任务变更的时间
+	entriesMu sync.Mutex
+	entries   map[string]cronEntry
+	syncedAt  time.Time
+
+	// 执行器报告不存在或不可达的执行，记录首次发现的时间
+	lostMu    sync.Mutex
+	lostSince map[string]time.Time
 }
 
 // New 创建调度器
@@ -52,19 +71,31 @@ func New(cfg config.Config, storage *storage.Storage, logger *zap.Logger) (*Sche
 		sqlDB:           sqlDB,
 		logger:          logger,
 		instanceID:      cfg.Scheduler.InstanceID,
-		isLeader:        false,
 		stopCh:          make(chan struct{}),
 		executorManager: executor.NewManager(storage, logger),
 		lbManager:       loadbalance.NewManager(storage),
 		healthChecker:   executor.NewHealthChecker(storage, logger, cfg.HealthCheck),
-		cron:            cron.New(cron.WithSeconds()),
+		cron:            cron.New(), // 表达式由 cronexpr 解析后以 Schedule 注册，不使用 cron 自带的解析器
+		entries:         make(map[string]cronEntry),
+		lostSince:       make(map[string]time.Time),
 	}
 
-	// 创建分布式锁
-	s.locker = NewLocker(sqlDB, cfg.Scheduler.LockKey, cfg.Scheduler.LockTimeout, logger)
+	// 创建分布式锁（按数据库方言选择实现）
+	s.locker, err = NewLeaderLock(storage, cfg.Scheduler, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create leader lock: %w", err)
+	}
 
 	// 创建任务执行器
-	s.taskRunner = NewTaskRunner(storage, s.executorManager, s.lbManager, logger, cfg.Scheduler.MaxWorkers)
+	s.taskRunner = NewTaskRunner(storage, s.executorManager, s.lbManager, logger, cfg.Scheduler)
+
+	// 创建任务依赖引擎
+	s.dag = NewDAGEngine(storage, s.taskRunner, logger)
+	s.taskRunner.SetDAGEngine(s.dag)
+
+	// 创建补跑引擎
+	s.backfills = NewBackfillEngine(storage, s.taskRunner, s.dag, s.locker, logger)
+	s.taskRunner.SetBackfillEngine(s.backfills)
 
 	// 设置健康检查器的TaskRunner引用
 	s.healthChecker.SetTaskRunner(s.taskRunner)
@@ -92,6 +123,22 @@ func (s *Scheduler) Start() error {
 	s.wg.Add(1)
 	go s.leaderElection()
 
+	// 启动任务变更对账
+	s.wg.Add(1)
+	go s.reconcileLoop()
+
+	// 启动执行状态轮询
+	s.wg.Add(1)
+	go s.statusPollLoop()
+
+	// 启动过期执行清理
+	s.wg.Add(1)
+	go s.retentionLoop()
+
+	// 启动补跑推进
+	s.wg.Add(1)
+	go s.backfillLoop()
+
 	return nil
 }
 
@@ -135,26 +182,33 @@ func (s *Scheduler) Stop() error {
 	return nil
 }
 
-// registerInstance 注册调度器实例
+// registerInstance 注册调度器实例，记录本实例对外通告的地址
 func (s *Scheduler) registerInstance() error {
+	host, port, err := s.config.AdvertiseHostPort()
+	if err != nil {
+		return err
+	}
+
 	instance := models.SchedulerInstance{
 		ID:         uuid.New().String(),
 		InstanceID: s.instanceID,
-		Host:       "localhost", // TODO: 获取真实主机名
-		Port:       s.config.MaxWorkers,
+		Host:       host,
+		Port:       port,
 		IsLeader:   false,
 	}
 
 	// 检查实例是否已存在
 	var existing models.SchedulerInstance
-	err := s.storage.DB().Where("instance_id = ?", s.instanceID).First(&existing).Error
+	err = s.storage.DB().Where("instance_id = ?", s.instanceID).First(&existing).Error
 	if err == gorm.ErrRecordNotFound {
 		// 创建新实例
 		if err := s.storage.DB().Create(&instance).Error; err != nil {
 			return fmt.Errorf("failed to create scheduler instance: %w", err)
 		}
 	} else if err == nil {
-		// 更新现有实例
+		// 更新现有实例，重启后地址可能变化
+		existing.Host = host
+		existing.Port = port
 		existing.IsLeader = false
 		if err := s.storage.DB().Save(&existing).Error; err != nil {
 			return fmt.Errorf("failed to update scheduler instance: %w", err)
@@ -163,6 +217,11 @@ func (s *Scheduler) registerInstance() error {
 		return fmt.Errorf("failed to query scheduler instance: %w", err)
 	}
 
+	s.logger.Info("scheduler instance registered",
+		zap.String("instance_id", s.instanceID),
+		zap.String("advertise_addr", net.JoinHostPort(host, strconv.Itoa(port))),
+		zap.String("callback_base_url", s.config.CallbackBase()))
+
 	return nil
 }
 
@@ -188,7 +247,7 @@ func (s *Scheduler) tryBecomeLeader() {
 	ctx, cancel := context.WithTimeout(context.Background(), s.config.LockTimeout)
 	defer cancel()
 
-	if !s.isLeader {
+	if !s.isLeader.Load() {
 		// 尝试获取锁
 		locked, err := s.locker.TryLock(ctx)
 		if err != nil {
@@ -197,16 +256,27 @@ func (s *Scheduler) tryBecomeLeader() {
 		}
 
 		if locked {
-			s.isLeader = true
+			s.isLeader.Store(true)
 			s.updateInstanceStatus(true)
 			s.logger.Info("became leader",
-				zap.String("instance_id", s.instanceID))
+				zap.String("instance_id", s.instanceID),
+				zap.Int64("fencing_token", s.locker.FencingToken()))
 
-			// 加载并调度任务
-			if err := s.loadAndScheduleTasks(); err != nil {
+			// 接管前任遗留的运行中执行（重建超时、重新排队丢失的执行）
+			if err := s.recoverExecutions(); err != nil {
+				s.logger.Error("failed to recover unfinished executions", zap.Error(err))
+			}
+
+			// 对账并调度任务
+			if err := s.reconcileTasks(); err != nil {
 				s.logger.Error("failed to load and schedule tasks", zap.Error(err))
 			}
 
+			// 补偿领导者空缺期间错过的触发
+			if err := s.recoverMisfires(); err != nil {
+				s.logger.Error("failed to recover misfired tasks", zap.Error(err))
+			}
+
 			// 启动cron调度器
 			s.cron.Start()
 		}
@@ -214,7 +284,7 @@ func (s *Scheduler) tryBecomeLeader() {
 		// 续约锁
 		if err := s.locker.Renew(ctx); err != nil {
 			s.logger.Error("failed to renew leader lock", zap.Error(err))
-			s.isLeader = false
+			s.isLeader.Store(false)
 			s.updateInstanceStatus(false)
 
 			// 停止cron调度器
@@ -236,58 +306,13 @@ func (s *Scheduler) updateInstanceStatus(isLeader bool) {
 	}
 }
 
-// loadAndScheduleTasks 加载并调度任务
-func (s *Scheduler) loadAndScheduleTasks() error {
-	// 清除所有现有的cron任务
-	entries := s.cron.Entries()
-	for _, entry := range entries {
-		s.cron.Remove(entry.ID)
-	}
-
-	// 加载所有活跃任务
-	var tasks []models.Task
-	err := s.storage.DB().
-		Where("status = ?", models.TaskStatusActive).
-		Find(&tasks).Error
-	if err != nil {
-		return fmt.Errorf("failed to load tasks: %w", err)
-	}
-
-	// 为每个任务添加cron调度
-	for _, task := range tasks {
-		t := task // 创建副本避免闭包问题
-		entryID, err := s.cron.AddFunc(t.CronExpression, func() {
-			s.scheduleTask(&t)
-		})
-
-		if err != nil {
-			s.logger.Error("failed to add cron job",
-				zap.String("task_id", t.ID),
-				zap.String("task_name", t.Name),
-				zap.Error(err))
-			continue
-		}
-
-		s.logger.Info("scheduled task",
-			zap.String("task_id", t.ID),
-			zap.String("task_name", t.Name),
-			zap.String("cron", t.CronExpression),
-			zap.Int("entry_id", int(entryID)))
-	}
-
-	s.logger.Info("loaded and scheduled tasks",
-		zap.Int("count", len(tasks)))
-
-	return nil
-}
-
-// ReloadTasks 重新加载和调度任务，用于暂停/恢复功能
+// ReloadTasks 全量对账所有任务的cron条目
 func (s *Scheduler) ReloadTasks() error {
-	return s.loadAndScheduleTasks()
+	return s.reconcileTasks()
 }
 
 // scheduleTask 调度任务执行
-func (s *Scheduler) scheduleTask(task *models.Task) {
+func (s *Scheduler) scheduleTask(task *models.Task, scheduledTime time.Time) {
 	ctx := context.Background()
 
 	s.logger.Info("scheduling task",
@@ -314,11 +339,17 @@ func (s *Scheduler) scheduleTask(task *models.Task) {
 	execution := &models.TaskExecution{
 		ID:            uuid.New().String(),
 		TaskID:        task.ID,
-		ScheduledTime: time.Now(),
+		ScheduledTime: scheduledTime,
 		Status:        models.ExecutionStatusPending,
+		Parameters:    task.EffectiveParameters(nil),
 	}
 
-	if err := s.storage.DB().Create(execution).Error; err != nil {
+	if err := s.createFencedExecution(ctx, task, execution); err != nil {
+		if errors.Is(err, ErrLeaseLost) {
+			s.logger.Warn("leadership lost, not creating execution",
+				zap.String("task_id", task.ID))
+			return
+		}
 		s.logger.Error("failed to create execution record",
 			zap.String("task_id", task.ID),
 			zap.Error(err))
@@ -329,6 +360,23 @@ func (s *Scheduler) scheduleTask(task *models.Task) {
 	s.taskRunner.Submit(task, execution)
 }
 
+// createFencedExecution 以领导者身份创建执行记录（及其工作流运行）
+// 事务内校验租约仍由本实例持有，被取代的领导者写入会以 ErrLeaseLost 失败
+func (s *Scheduler) createFencedExecution(ctx context.Context, task *models.Task, execution *models.TaskExecution) error {
+	return s.storage.DB().Transaction(func(tx *gorm.DB) error {
+		token, err := s.locker.Fence(tx)
+		if err != nil {
+			return err
+		}
+		execution.FencingToken = token
+
+		if err := s.dag.BeginRun(ctx, tx, task, execution); err != nil {
+			return err
+		}
+		return tx.Create(execution).Error
+	})
+}
+
 // checkExecutionMode 检查执行模式
 func (s *Scheduler) checkExecutionMode(ctx context.Context, task *models.Task) (bool, error) {
 	switch task.ExecutionMode {
@@ -363,14 +411,20 @@ func (s *Scheduler) checkExecutionMode(ctx context.Context, task *models.Task) (
 
 		if count > 0 {
 			// 创建跳过记录
+			now := time.Now()
 			execution := &models.TaskExecution{
 				ID:            uuid.New().String(),
 				TaskID:        task.ID,
-				ScheduledTime: time.Now(),
+				ScheduledTime: now,
+				EndTime:       &now,
 				Status:        models.ExecutionStatusSkipped,
 				Logs:          "Skipped due to execution mode",
+				Parameters:    task.EffectiveParameters(nil),
+			}
+			if err := s.createFencedExecution(ctx, task, execution); err != nil {
+				return false, err
 			}
-			s.storage.DB().Create(execution)
+			s.dag.OnExecutionFinished(ctx, execution)
 			return false, nil
 		}
 		return true, nil
@@ -380,43 +434,119 @@ func (s *Scheduler) checkExecutionMode(ctx context.Context, task *models.Task) (
 	}
 }
 
-// TriggerTask 手动触发任务
+// TriggerTask 手动触发任务，parameters 覆盖同名的任务默认参数，合并结果只记录在本次执行上
+// 任务声明了参数 schema 时合并结果必须满足 schema，否则返回 *jsonschema.ValidationError
 func (s *Scheduler) TriggerTask(ctx context.Context, taskID string, parameters map[string]interface{}) (*models.TaskExecution, error) {
-	// 获取任务
 	var task models.Task
 	if err := s.storage.DB().Where("id = ?", taskID).First(&task).Error; err != nil {
 		return nil, fmt.Errorf("task not found: %w", err)
 	}
 
-	// 合并参数
-	if parameters != nil {
-		if task.Parameters == nil {
-			task.Parameters = make(models.JSONMap)
-		}
-		for k, v := range parameters {
-			task.Parameters[k] = v
-		}
-	}
-
-	// 创建执行记录
 	execution := &models.TaskExecution{
 		ID:            uuid.New().String(),
 		TaskID:        task.ID,
 		ScheduledTime: time.Now(),
 		Status:        models.ExecutionStatusPending,
+		Parameters:    task.EffectiveParameters(parameters),
 	}
+	if err := task.ValidateParameters(execution.Parameters); err != nil {
+		return nil, err
+	}
+	if err := s.createManualExecution(ctx, &task, execution); err != nil {
+		return nil, err
+	}
+	return execution, nil
+}
 
-	if err := s.storage.DB().Create(execution).Error; err != nil {
-		return nil, fmt.Errorf("failed to create execution record: %w", err)
+// RerunExecution 以已结束执行的参数重跑其任务；parameters 不为 nil 时作为编辑后的完整参数替换原参数
+// 原样重跑时使用原执行实际下发的参数（模板已按原执行展开），保证计划时间等模板变量与原执行一致
+func (s *Scheduler) RerunExecution(ctx context.Context, executionID string, parameters map[string]interface{}) (*models.TaskExecution, error) {
+	var source models.TaskExecution
+	if err := s.storage.DB().Where("id = ?", executionID).First(&source).Error; err != nil {
+		return nil, fmt.Errorf("execution not found: %w", err)
+	}
+	if !source.Status.IsTerminal() {
+		return nil, fmt.Errorf("%w: execution is %s", ErrExecutionNotFinished, source.Status)
 	}
 
-	// 提交到任务执行器
-	s.taskRunner.Submit(&task, execution)
+	var task models.Task
+	if err := s.storage.DB().Where("id = ?", source.TaskID).First(&task).Error; err != nil {
+		return nil, fmt.Errorf("task not found: %w", err)
+	}
+
+	params := models.JSONMap(parameters)
+	if parameters != nil {
+		// 修改后的参数需要满足任务当前的 schema
+		if err := task.ValidateParameters(params); err != nil {
+			return nil, err
+		}
+	} else {
+		// 原样重跑不再校验，保证能复现原执行
+		params = sourceParameters(&source)
+		if params == nil {
+			// 早于参数记录的执行没有保存参数，使用任务当前的默认参数
+			params = task.EffectiveParameters(nil)
+		}
+	}
+
+	execution := &models.TaskExecution{
+		ID:                 uuid.New().String(),
+		TaskID:             task.ID,
+		ScheduledTime:      time.Now(),
+		Status:             models.ExecutionStatusPending,
+		Parameters:         params,
+		RerunOfExecutionID: &source.ID,
+	}
+	if err := s.createManualExecution(ctx, &task, execution); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("execution rerun",
+		zap.String("execution_id", execution.ID),
+		zap.String("rerun_of", source.ID),
+		zap.Bool("edited_parameters", parameters != nil))
 
 	return execution, nil
 }
 
+// sourceParameters 重跑时沿用的参数：优先使用实际下发的参数，未分发过的执行使用其记录的参数
+func sourceParameters(execution *models.TaskExecution) models.JSONMap {
+	if execution.RenderedParameters != nil {
+		return execution.RenderedParameters
+	}
+	return execution.Parameters
+}
+
+// createManualExecution 创建手动触发或重跑的执行记录并提交分发
+func (s *Scheduler) createManualExecution(ctx context.Context, task *models.Task, execution *models.TaskExecution) error {
+	// 手动触发不要求持有领导权，仅记录当前令牌
+	execution.FencingToken = s.locker.FencingToken()
+
+	err := s.storage.DB().Transaction(func(tx *gorm.DB) error {
+		if err := s.dag.BeginRun(ctx, tx, task, execution); err != nil {
+			return err
+		}
+		return tx.Create(execution).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create execution record: %w", err)
+	}
+
+	s.taskRunner.Submit(task, execution)
+	return nil
+}
+
 // GetTaskRunner 获取任务执行器
 func (s *Scheduler) GetTaskRunner() *TaskRunner {
 	return s.taskRunner
 }
+
+// DAG 获取任务依赖引擎
+func (s *Scheduler) DAG() *DAGEngine {
+	return s.dag
+}
+
+// Backfills 获取补跑引擎
+func (s *Scheduler) Backfills() *BackfillEngine {
+	return s.backfills
+}
@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jobs/scheduler/internal/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrIllegalTransition 执行状态迁移不在迁移表中（如超时后迟到的成功回调）
+	ErrIllegalTransition = errors.New("illegal execution status transition")
+	// ErrExecutionConflict 执行记录已被并发修改
+	ErrExecutionConflict = errors.New("execution modified concurrently")
+	// ErrStopRefused 执行器拒绝或无法停止执行，执行状态保持不变
+	ErrStopRefused = errors.New("executor refused to stop execution")
+	// ErrExecutionNotRunning 执行不在运行中，不再接受进度上报
+	ErrExecutionNotRunning = errors.New("execution is not running")
+	// ErrExecutionNotFinished 执行尚未结束，不能重跑
+	ErrExecutionNotFinished = errors.New("execution is not finished")
+)
+
+// maxTransitionAttempts 版本冲突时重新加载并重试的次数
+const maxTransitionAttempts = 3
+
+// transitionExecution 按迁移表将执行迁移到 to，并一同更新 changes 中的字段
+// 以版本号做乐观锁：记录在加载后被修改过时返回 ErrExecutionConflict，成功后更新 execution 的状态和版本号
+func transitionExecution(db *gorm.DB, execution *models.TaskExecution, to models.ExecutionStatus, changes map[string]interface{}) error {
+	if !execution.Status.CanTransitionTo(to) {
+		return fmt.Errorf("%w: %s -> %s", ErrIllegalTransition, execution.Status, to)
+	}
+
+	updates := map[string]interface{}{
+		"status":  to,
+		"version": gorm.Expr("version + 1"),
+	}
+	for column, value := range changes {
+		updates[column] = value
+	}
+
+	result := db.Model(&models.TaskExecution{}).
+		Where("id = ? AND version = ?", execution.ID, execution.Version).
+		Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update execution: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrExecutionConflict
+	}
+
+	execution.Status = to
+	execution.Version++
+	return nil
+}
+
+// transition 加载执行的最新记录并迁移到 to，版本冲突时重新加载后重试
+// changes 根据最新记录生成一同更新的字段；执行已处于 to 时不做修改，返回 changed 为 false
+func (r *TaskRunner) transition(executionID string, to models.ExecutionStatus, changes func(current *models.TaskExecution) map[string]interface{}) (execution *models.TaskExecution, changed bool, err error) {
+	for attempt := 1; ; attempt++ {
+		var current models.TaskExecution
+		if err := r.storage.DB().Where("id = ?", executionID).First(&current).Error; err != nil {
+			return nil, false, fmt.Errorf("execution not found: %w", err)
+		}
+		if current.Status == to {
+			return &current, false, nil
+		}
+
+		var fields map[string]interface{}
+		if changes != nil {
+			fields = changes(&current)
+		}
+
+		err := transitionExecution(r.storage.DB(), &current, to, fields)
+		if errors.Is(err, ErrExecutionConflict) && attempt < maxTransitionAttempts {
+			continue
+		}
+		if err != nil {
+			return &current, false, err
+		}
+		return &current, true, nil
+	}
+}
@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/executor"
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionStateTransitions(t *testing.T) {
+	st := newTestStorage(t)
+	runner := newQueueRunner(t, st, "a", time.Minute)
+
+	task := models.Task{ID: "task-1", Name: "task-1", CronExpression: "0 * * * * *"}
+	require.NoError(t, st.DB().Create(&task).Error)
+	for _, id := range []string{"exec-success", "exec-timeout", "exec-pending"} {
+		status := models.ExecutionStatusRunning
+		if id == "exec-pending" {
+			status = models.ExecutionStatusPending
+		}
+		require.NoError(t, st.DB().Create(&models.TaskExecution{
+			ID:            id,
+			TaskID:        task.ID,
+			ScheduledTime: time.Now(),
+			Status:        status,
+		}).Error)
+	}
+	load := func(id string) models.TaskExecution {
+		var execution models.TaskExecution
+		require.NoError(t, st.DB().Where("id = ?", id).First(&execution).Error)
+		return execution
+	}
+	ctx := context.Background()
+
+	// 重复回调幂等，不同终态的回调不合法
+	success := executor.ExecutionCallbackRequest{Attempt: intPtr(0), ExecutionID: "exec-success", Status: models.ExecutionStatusSuccess, Logs: "done"}
+	require.NoError(t, runner.HandleCallback(ctx, "exec-success", success))
+	require.NoError(t, runner.HandleCallback(ctx, "exec-success", success))
+	err := runner.HandleCallback(ctx, "exec-success", executor.ExecutionCallbackRequest{Attempt: intPtr(0), Status: models.ExecutionStatusFailed})
+	assert.ErrorIs(t, err, ErrIllegalTransition)
+	assert.Equal(t, models.ExecutionStatusSuccess, load("exec-success").Status)
+	assert.Equal(t, int64(1), load("exec-success").Version)
+
+	// 超时后迟到的成功回调不能改写状态
+	runner.handleTimeout("exec-timeout")
+	err = runner.HandleCallback(ctx, "exec-timeout", executor.ExecutionCallbackRequest{Attempt: intPtr(0), Status: models.ExecutionStatusSuccess})
+	assert.ErrorIs(t, err, ErrIllegalTransition)
+	assert.Equal(t, models.ExecutionStatusTimeout, load("exec-timeout").Status)
+
+	// 回调不能把执行迁移到非终态
+	err = runner.HandleCallback(ctx, "exec-pending", executor.ExecutionCallbackRequest{Attempt: intPtr(0), Status: models.ExecutionStatusRunning})
+	assert.ErrorIs(t, err, ErrIllegalTransition)
+
+	// 加载后被并发修改的记录版本过期
+	stale := load("exec-pending")
+	cancelled, err := runner.CancelExecution(ctx, "exec-pending")
+	require.NoError(t, err)
+	assert.Equal(t, models.ExecutionStatusCancelled, cancelled.Status)
+	assert.ErrorIs(t, transitionExecution(st.DB(), &stale, models.ExecutionStatusRunning, nil), ErrExecutionConflict)
+	assert.Equal(t, models.ExecutionStatusCancelled, load("exec-pending").Status)
+}
+
+func TestCancelExecutionKeepsStatusWhenStopRefused(t *testing.T) {
+	st := newTestStorage(t)
+	runner := newQueueRunner(t, st, "a", time.Minute)
+
+	var accept atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !accept.Load() {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	executorID := "executor-1"
+	require.NoError(t, st.DB().Create(&models.Task{ID: "task-1", Name: "task-1", CronExpression: "0 * * * * *"}).Error)
+	require.NoError(t, st.DB().Create(&models.Executor{ID: executorID, Name: "e1", InstanceID: "e1", BaseURL: server.URL}).Error)
+	require.NoError(t, st.DB().Create(&models.TaskExecution{
+		ID:            "exec-1",
+		TaskID:        "task-1",
+		ExecutorID:    &executorID,
+		ScheduledTime: time.Now(),
+		Status:        models.ExecutionStatusRunning,
+	}).Error)
+
+	_, err := runner.CancelExecution(context.Background(), "exec-1")
+	assert.ErrorIs(t, err, ErrStopRefused)
+
+	var execution models.TaskExecution
+	require.NoError(t, st.DB().Where("id = ?", "exec-1").First(&execution).Error)
+	assert.Equal(t, models.ExecutionStatusRunning, execution.Status)
+
+	accept.Store(true)
+	cancelled, err := runner.CancelExecution(context.Background(), "exec-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.ExecutionStatusCancelled, cancelled.Status)
+	assert.NotNil(t, cancelled.EndTime)
+
+	// 重复取消以及执行器随后报告的取消回调都是幂等的
+	_, err = runner.CancelExecution(context.Background(), "exec-1")
+	require.NoError(t, err)
+	require.NoError(t, runner.HandleCallback(context.Background(), "exec-1", executor.ExecutionCallbackRequest{Attempt: intPtr(0), Status: models.ExecutionStatusCancelled}))
+}
@@ -0,0 +1,170 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jobs/scheduler/internal/executor"
+	"github.com/jobs/scheduler/internal/models"
+	"go.uber.org/zap"
+)
+
+// statusPollBatch 每轮最多查询的运行中执行数
+const statusPollBatch = 200
+
+// statusPollLoop 领导者定期向执行器查询运行较久的执行，补偿丢失的回调
+func (s *Scheduler) statusPollLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.statusPollInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if !s.isLeader.Load() {
+				continue
+			}
+			if err := s.pollRunningExecutions(); err != nil {
+				s.logger.Error("failed to poll running executions", zap.Error(err))
+			}
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+func (s *Scheduler) statusPollInterval() time.Duration {
+	if s.config.StatusPollInterval > 0 {
+		return s.config.StatusPollInterval
+	}
+	return 30 * time.Second
+}
+
+func (s *Scheduler) lostGracePeriod() time.Duration {
+	if s.config.LostGracePeriod > 0 {
+		return s.config.LostGracePeriod
+	}
+	return time.Minute
+}
+
+// pollRunningExecutions 查询已交给执行器且运行超过一个轮询间隔的执行，一个轮询间隔内上报过心跳的执行无需查询
+func (s *Scheduler) pollRunningExecutions() error {
+	now := time.Now()
+	since := now.Add(-s.statusPollInterval())
+
+	var executions []models.TaskExecution
+	if err := s.storage.DB().
+		Preload("Task").
+		Preload("Executor").
+		Where("status = ? AND claimed_until IS NULL AND executor_id IS NOT NULL AND start_time < ? AND (heartbeat_at IS NULL OR heartbeat_at < ?)",
+			models.ExecutionStatusRunning, since, since).
+		Order("start_time").
+		Limit(statusPollBatch).
+		Find(&executions).Error; err != nil {
+		return fmt.Errorf("failed to load running executions: %w", err)
+	}
+
+	polled := make(map[string]bool, len(executions))
+	for i := range executions {
+		execution := &executions[i]
+		polled[execution.ID] = true
+
+		task, exec := execution.Task, execution.Executor
+		execution.Task = nil
+		execution.Executor = nil
+		if task == nil || exec == nil {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+		status, err := s.taskRunner.QueryStatus(ctx, execution, exec)
+		cancel()
+		s.applyExecutorStatus(execution, task, status, err, now)
+	}
+
+	// 不足一批时已覆盖全部运行中的执行，清理已结束执行的丢失记录
+	if len(executions) < statusPollBatch {
+		s.lostMu.Lock()
+		for id := range s.lostSince {
+			if !polled[id] {
+				delete(s.lostSince, id)
+			}
+		}
+		s.lostMu.Unlock()
+	}
+
+	return nil
+}
+
+// applyExecutorStatus 按执行器报告的状态处理执行：终态按回调处理，
+// 执行器持续报告不存在或不可达超过宽限期时判定丢失并重新排队
+func (s *Scheduler) applyExecutorStatus(execution *models.TaskExecution, task *models.Task, status *executor.ExecutionStatusResponse, err error, now time.Time) {
+	switch {
+	case errors.Is(err, ErrExecutorUnreachable):
+		s.markLost(execution, task, "executor unreachable", now)
+
+	case err != nil:
+		// 执行器未实现状态接口或响应异常，继续依赖回调和超时
+		s.logger.Debug("executor status unavailable",
+			zap.String("execution_id", execution.ID),
+			zap.Error(err))
+
+	case status.Status == executor.ExecutionStatusUnknown:
+		s.markLost(execution, task, "executor has no record of the execution", now)
+
+	case status.Status.IsTerminal():
+		s.clearLost(execution.ID)
+		s.logger.Warn("applying executor-reported status for execution without callback",
+			zap.String("execution_id", execution.ID),
+			zap.String("status", string(status.Status)))
+		if err := s.taskRunner.HandleCallback(context.Background(), execution.ID, executor.ExecutionCallbackRequest{
+			ExecutionID: execution.ID,
+			Attempt:     &execution.RetryCount, // 查询时的尝试，期间已重试则按上一次尝试忽略
+			Status:      status.Status,
+			Result:      status.Result,
+			Logs:        status.Logs,
+		}); err != nil {
+			s.logger.Error("failed to apply executor-reported status",
+				zap.String("execution_id", execution.ID),
+				zap.Error(err))
+		}
+
+	default:
+		s.clearLost(execution.ID)
+	}
+}
+
+// markLost 记录执行疑似丢失，宽限期内回调仍可能到达，超过宽限期后重新排队
+func (s *Scheduler) markLost(execution *models.TaskExecution, task *models.Task, reason string, now time.Time) {
+	s.lostMu.Lock()
+	since, ok := s.lostSince[execution.ID]
+	if !ok {
+		s.lostSince[execution.ID] = now
+	}
+	s.lostMu.Unlock()
+
+	if !ok {
+		s.logger.Warn("execution may be lost, waiting for grace period",
+			zap.String("execution_id", execution.ID),
+			zap.String("reason", reason),
+			zap.Duration("grace_period", s.lostGracePeriod()))
+		return
+	}
+
+	if now.Sub(since) < s.lostGracePeriod() {
+		return
+	}
+
+	s.clearLost(execution.ID)
+	s.taskRunner.cancelTimeout(execution.ID)
+	s.taskRunner.requeueExecution(execution, task.MaxRetry, fmt.Sprintf("execution lost: %s for %s", reason, now.Sub(since).Round(time.Second)))
+}
+
+func (s *Scheduler) clearLost(executionID string) {
+	s.lostMu.Lock()
+	delete(s.lostSince, executionID)
+	s.lostMu.Unlock()
+}
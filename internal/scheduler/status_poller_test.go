@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/executor"
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPollRunningExecutions(t *testing.T) {
+	st := newTestStorage(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/status/")
+		resp := executor.ExecutionStatusResponse{ExecutionID: id, Status: models.ExecutionStatusRunning}
+		switch id {
+		case "done":
+			resp.Status = models.ExecutionStatusSuccess
+			resp.Result = map[string]interface{}{"rows": float64(42)}
+		case "gone":
+			resp.Status = executor.ExecutionStatusUnknown
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	require.NoError(t, st.DB().Create(&models.Task{ID: "task-1", Name: "task-1", CronExpression: "0 * * * * *"}).Error)
+	require.NoError(t, st.DB().Create(&models.Executor{ID: "exec", Name: "exec", InstanceID: "exec", BaseURL: server.URL}).Error)
+
+	executorID := "exec"
+	started := time.Now().Add(-time.Minute)
+	for _, id := range []string{"done", "gone", "working"} {
+		require.NoError(t, st.DB().Create(&models.TaskExecution{
+			ID:            id,
+			TaskID:        "task-1",
+			ExecutorID:    &executorID,
+			ScheduledTime: started,
+			StartTime:     &started,
+			Status:        models.ExecutionStatusRunning,
+		}).Error)
+	}
+
+	logger := zap.NewNop()
+	s := &Scheduler{
+		config:     config.SchedulerConfig{StatusPollInterval: time.Second, LostGracePeriod: 50 * time.Millisecond},
+		storage:    st,
+		logger:     logger,
+		taskRunner: NewTaskRunner(st, nil, nil, logger, config.SchedulerConfig{InstanceID: "leader", MaxWorkers: 1}),
+		lostSince:  make(map[string]time.Time),
+	}
+
+	load := func(id string) models.TaskExecution {
+		var execution models.TaskExecution
+		require.NoError(t, st.DB().Where("id = ?", id).First(&execution).Error)
+		return execution
+	}
+
+	// 回调丢失的执行按执行器报告结束；不存在的执行在宽限期内保持运行中
+	require.NoError(t, s.pollRunningExecutions())
+	done := load("done")
+	assert.Equal(t, models.ExecutionStatusSuccess, done.Status)
+	assert.Equal(t, float64(42), done.Result["rows"])
+	assert.Equal(t, models.ExecutionStatusRunning, load("gone").Status)
+	assert.Equal(t, models.ExecutionStatusRunning, load("working").Status)
+
+	// 超过宽限期后判定丢失并重新排队
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, s.pollRunningExecutions())
+	gone := load("gone")
+	assert.Equal(t, models.ExecutionStatusPending, gone.Status)
+	assert.Equal(t, 1, gone.RetryCount)
+	assert.Equal(t, models.ExecutionStatusRunning, load("working").Status)
+	assert.Empty(t, s.lostSince)
+}
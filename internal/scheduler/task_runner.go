@@ -4,15 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jobs/scheduler/internal/executor"
 	"github.com/jobs/scheduler/internal/loadbalance"
 	"github.com/jobs/scheduler/internal/models"
 	"github.com/jobs/scheduler/internal/storage"
+	"github.com/jobs/scheduler/pkg/config"
+	"github.com/jobs/scheduler/pkg/cronexpr"
+	"github.com/jobs/scheduler/pkg/paramtemplate"
 	"go.uber.org/zap"
 )
 
@@ -82,11 +87,25 @@ type TaskRunner struct {
 	logger          *zap.Logger
 	httpClient      *http.Client
 
+	instanceID string
 	maxWorkers int
-	taskCh     chan *taskJob
+	jobCh      chan *taskJob
+	notifyCh   chan struct{}
 	stopCh     chan struct{}
 	wg         sync.WaitGroup
 
+	// 待分发队列：轮询间隔、认领有效期，以及已派给工作协程尚未结束的执行数
+	pollInterval      time.Duration
+	visibilityTimeout time.Duration
+	busy              int32
+
+	// 执行器回调调度器的基础地址
+	callbackBaseURL string
+
+	// 单次追加日志和单个执行日志总量的上限
+	logChunkMaxBytes int
+	logMaxBytes      int64
+
 	// 超时管理器，避免goroutine泄漏
 	timeoutMu sync.RWMutex
 	timeouts  map[string]*time.Timer
@@ -94,6 +113,12 @@ type TaskRunner struct {
 	// 熔断器管理，每个执行器一个熔断器
 	breakerMu sync.RWMutex
 	breakers  map[string]*CircuitBreaker
+
+	// 任务依赖引擎，执行进入终态后触发下游
+	dag *DAGEngine
+
+	// 补跑引擎，执行进入终态后继续创建补跑的执行
+	backfills *BackfillEngine
 }
 
 type taskJob struct {
@@ -107,8 +132,25 @@ func NewTaskRunner(
 	executorManager *executor.Manager,
 	lbManager *loadbalance.Manager,
 	logger *zap.Logger,
-	maxWorkers int,
+	cfg config.SchedulerConfig,
 ) *TaskRunner {
+	pollInterval := cfg.QueuePollInterval
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	visibilityTimeout := cfg.VisibilityTimeout
+	if visibilityTimeout <= 0 {
+		visibilityTimeout = 30 * time.Second
+	}
+	logChunkMaxBytes := cfg.LogChunkMaxBytes
+	if logChunkMaxBytes <= 0 {
+		logChunkMaxBytes = 64 * 1024
+	}
+	logMaxBytes := cfg.LogMaxBytes
+	if logMaxBytes <= 0 {
+		logMaxBytes = 10 * 1024 * 1024
+	}
+
 	return &TaskRunner{
 		storage:         storage,
 		executorManager: executorManager,
@@ -117,11 +159,38 @@ func NewTaskRunner(
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		maxWorkers: maxWorkers,
-		taskCh:     make(chan *taskJob, maxWorkers*2),
-		stopCh:     make(chan struct{}),
-		timeouts:   make(map[string]*time.Timer),
-		breakers:   make(map[string]*CircuitBreaker),
+		instanceID:        cfg.InstanceID,
+		maxWorkers:        cfg.MaxWorkers,
+		jobCh:             make(chan *taskJob),
+		notifyCh:          make(chan struct{}, 1),
+		stopCh:            make(chan struct{}),
+		pollInterval:      pollInterval,
+		visibilityTimeout: visibilityTimeout,
+		callbackBaseURL:   cfg.CallbackBase(),
+		logChunkMaxBytes:  logChunkMaxBytes,
+		logMaxBytes:       logMaxBytes,
+		timeouts:          make(map[string]*time.Timer),
+		breakers:          make(map[string]*CircuitBreaker),
+	}
+}
+
+// SetDAGEngine 设置任务依赖引擎
+func (r *TaskRunner) SetDAGEngine(dag *DAGEngine) {
+	r.dag = dag
+}
+
+// SetBackfillEngine 设置补跑引擎
+func (r *TaskRunner) SetBackfillEngine(backfills *BackfillEngine) {
+	r.backfills = backfills
+}
+
+// notifyFinished 通知依赖引擎和补跑引擎执行已结束
+func (r *TaskRunner) notifyFinished(ctx context.Context, execution *models.TaskExecution) {
+	if r.dag != nil {
+		r.dag.OnExecutionFinished(ctx, execution)
+	}
+	if r.backfills != nil {
+		r.backfills.OnExecutionFinished(ctx, execution)
 	}
 }
 
@@ -131,8 +200,14 @@ func (r *TaskRunner) Start() {
 		r.wg.Add(1)
 		go r.worker(i)
 	}
+
+	r.wg.Add(1)
+	go r.dispatch()
+
 	r.logger.Info("task runner started",
-		zap.Int("workers", r.maxWorkers))
+		zap.Int("workers", r.maxWorkers),
+		zap.Duration("poll_interval", r.pollInterval),
+		zap.Duration("visibility_timeout", r.visibilityTimeout))
 }
 
 // Stop 停止任务执行器
@@ -151,24 +226,21 @@ func (r *TaskRunner) Stop() {
 	r.logger.Info("task runner stopped")
 }
 
-// Submit 提交任务
+// Submit 通知有新的待分发执行
+// 执行记录已以 pending 状态持久化，由任意实例的分发协程从数据库认领，此处只唤醒本实例的分发协程
 func (r *TaskRunner) Submit(task *models.Task, execution *models.TaskExecution) {
+	r.wake()
+
+	r.logger.Debug("task submitted",
+		zap.String("task_id", task.ID),
+		zap.String("execution_id", execution.ID))
+}
+
+// wake 唤醒分发协程，已有未处理的唤醒时直接返回
+func (r *TaskRunner) wake() {
 	select {
-	case r.taskCh <- &taskJob{task: task, execution: execution}:
-		r.logger.Debug("task submitted",
-			zap.String("task_id", task.ID),
-			zap.String("execution_id", execution.ID))
+	case r.notifyCh <- struct{}{}:
 	default:
-		r.logger.Warn("task queue is full, dropping task",
-			zap.String("task_id", task.ID),
-			zap.String("execution_id", execution.ID))
-
-		// 更新执行状态为失败
-		execution.Status = models.ExecutionStatusFailed
-		execution.Logs = "Task queue is full"
-		now := time.Now()
-		execution.EndTime = &now
-		r.storage.DB().Save(execution)
 	}
 }
 
@@ -180,8 +252,11 @@ func (r *TaskRunner) worker(id int) {
 
 	for {
 		select {
-		case job := <-r.taskCh:
+		case job := <-r.jobCh:
 			r.executeTask(job.task, job.execution)
+			atomic.AddInt32(&r.busy, -1)
+			// 空出工作协程后继续认领积压的执行
+			r.wake()
 		case <-r.stopCh:
 			r.logger.Debug("worker stopped", zap.Int("worker_id", id))
 			return
@@ -198,35 +273,44 @@ func (r *TaskRunner) executeTask(task *models.Task, execution *models.TaskExecut
 		zap.String("task_name", task.Name),
 		zap.String("execution_id", execution.ID))
 
-	// 更新执行状态为运行中
-	now := time.Now()
-	execution.Status = models.ExecutionStatusRunning
-	execution.StartTime = &now
-	if err := r.storage.DB().Save(execution).Error; err != nil {
+	// 更新执行状态为运行中；排队期间可能已被取消（如工作流运行被取消）或认领过期后被其他实例接管
+	started, err := r.startExecution(execution)
+	if err != nil {
 		r.logger.Error("failed to update execution status",
 			zap.String("execution_id", execution.ID),
 			zap.Error(err))
+		return
+	}
+	if !started {
+		r.logger.Info("execution is no longer claimed by this instance, skipping",
+			zap.String("execution_id", execution.ID))
+		return
 	}
 
-	// 使用循环处理重试，避免递归调用
+	// 使用循环处理重试，避免递归调用；被接管重新排队的执行只使用剩余的重试次数
 	var lastErr error
-	maxRetries := task.MaxRetry
+	retried := execution.RetryCount
+	maxRetries := task.MaxRetry - retried
 	if maxRetries < 0 {
 		maxRetries = 0
 	}
 
+	// 需要更换执行器时避开本次分发失败过的执行器，以及执行器上报失败后重试时的上一个执行器
+	failedExecutors := map[string]bool{}
+	if task.RetryPolicy != nil && task.RetryPolicy.SwitchExecutor && retried > 0 && execution.ExecutorID != nil {
+		failedExecutors[*execution.ExecutorID] = true
+	}
+
 	for attempt := 0; attempt <= maxRetries; attempt++ {
 		if attempt > 0 {
-			// 指数退避策略：1s, 2s, 4s, 8s... 最大30s
-			backoff := time.Duration(1<<uint(attempt-1)) * time.Second
-			if backoff > 30*time.Second {
-				backoff = 30 * time.Second
-			}
+			// 按任务的重试策略退避，默认指数退避：1s, 2s, 4s, 8s... 最大30s
+			backoff := task.RetryPolicy.Delay(retried + attempt)
 			r.logger.Info("retrying task execution",
 				zap.String("task_id", task.ID),
 				zap.String("execution_id", execution.ID),
 				zap.Int("attempt", attempt),
 				zap.Duration("backoff", backoff))
+			r.extendClaim(execution, backoff)
 			time.Sleep(backoff)
 		}
 
@@ -237,6 +321,10 @@ func (r *TaskRunner) executeTask(task *models.Task, execution *models.TaskExecut
 			continue
 		}
 
+		if task.RetryPolicy != nil && task.RetryPolicy.SwitchExecutor {
+			executors = excludeExecutors(executors, failedExecutors)
+		}
+
 		// 使用负载均衡策略选择执行器
 		selectedExecutor, err := r.lbManager.SelectExecutor(ctx, task, executors)
 		if err != nil {
@@ -244,10 +332,15 @@ func (r *TaskRunner) executeTask(task *models.Task, execution *models.TaskExecut
 			continue
 		}
 
-		// 更新执行记录中的执行器ID
+		// 更新执行记录中的执行器ID（不涉及状态迁移，只更新这两列）
 		execution.ExecutorID = &selectedExecutor.ID
-		execution.RetryCount = attempt
-		if err := r.storage.DB().Save(execution).Error; err != nil {
+		execution.RetryCount = retried + attempt
+		if err := r.storage.DB().Model(&models.TaskExecution{}).
+			Where("id = ?", execution.ID).
+			Updates(map[string]interface{}{
+				"executor_id": selectedExecutor.ID,
+				"retry_count": execution.RetryCount,
+			}).Error; err != nil {
 			r.logger.Error("failed to update executor id",
 				zap.String("execution_id", execution.ID),
 				zap.Error(err))
@@ -255,11 +348,20 @@ func (r *TaskRunner) executeTask(task *models.Task, execution *models.TaskExecut
 
 		// 调用执行器
 		err = r.callExecutor(ctx, task, execution, selectedExecutor)
+		if errors.Is(err, paramtemplate.ErrInvalidTemplate) {
+			// 模板错误重试也不会成功
+			r.failExecution(execution, fmt.Sprintf("Failed to render parameters: %v", err))
+			return
+		}
 		if err != nil {
 			lastErr = err
+			failedExecutors[selectedExecutor.ID] = true
 			continue
 		}
 
+		// 执行器已接收，此后由回调和超时监控跟踪
+		r.releaseDispatch(execution)
+
 		// 执行成功，设置超时监控
 		if task.TimeoutSeconds > 0 {
 			// 使用context取消机制替代goroutine
@@ -272,6 +374,23 @@ func (r *TaskRunner) executeTask(task *models.Task, execution *models.TaskExecut
 	r.failExecution(execution, fmt.Sprintf("Execution failed after %d attempts: %v", maxRetries+1, lastErr))
 }
 
+// excludeExecutors 去掉失败过的执行器，全部失败过时返回原列表
+func excludeExecutors(executors []*models.Executor, failed map[string]bool) []*models.Executor {
+	if len(failed) == 0 {
+		return executors
+	}
+	remaining := make([]*models.Executor, 0, len(executors))
+	for _, e := range executors {
+		if !failed[e.ID] {
+			remaining = append(remaining, e)
+		}
+	}
+	if len(remaining) == 0 {
+		return executors
+	}
+	return remaining
+}
+
 // getOrCreateBreaker 获取或创建执行器的熔断器
 func (r *TaskRunner) getOrCreateBreaker(executorID string) *CircuitBreaker {
 	r.breakerMu.RLock()
@@ -315,8 +434,40 @@ func (r *TaskRunner) ResetBreaker(executorID string) {
 	}
 }
 
+// executionParameters 返回下发给执行器的参数，早于参数记录的执行没有保存参数时使用任务当前的默认参数
+func executionParameters(task *models.Task, execution *models.TaskExecution) models.JSONMap {
+	if execution.Parameters != nil {
+		return execution.Parameters
+	}
+	return task.Parameters
+}
+
+// renderParameters 按本次分发的上下文展开参数模板，计划触发时间按任务时区展开
+func renderParameters(task *models.Task, execution *models.TaskExecution) (models.JSONMap, error) {
+	loc, err := cronexpr.LoadLocation(task.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", paramtemplate.ErrInvalidTemplate, err)
+	}
+	ctx := paramtemplate.NewContext(execution.ScheduledTime, loc, execution.ID, task.ID, task.Name, execution.RetryCount)
+	return paramtemplate.Render(executionParameters(task, execution), ctx)
+}
+
 // callExecutor 调用执行器（带熔断器保护）
+// 每次调用前按当前重试次数重新展开参数模板，并记录实际下发的参数
 func (r *TaskRunner) callExecutor(ctx context.Context, task *models.Task, execution *models.TaskExecution, exec *models.Executor) error {
+	params, err := renderParameters(task, execution)
+	if err != nil {
+		return err
+	}
+	execution.RenderedParameters = params
+	if err := r.storage.DB().Model(&models.TaskExecution{}).
+		Where("id = ?", execution.ID).
+		Update("rendered_parameters", execution.RenderedParameters).Error; err != nil {
+		r.logger.Error("failed to record rendered parameters",
+			zap.String("execution_id", execution.ID),
+			zap.Error(err))
+	}
+
 	// 获取该执行器的熔断器
 	breaker := r.getOrCreateBreaker(exec.ID)
 
@@ -326,11 +477,13 @@ func (r *TaskRunner) callExecutor(ctx context.Context, task *models.Task, execut
 		url := fmt.Sprintf("%s/execute", exec.BaseURL)
 
 		payload := map[string]interface{}{
-			"execution_id": execution.ID,
-			"task_id":      task.ID,
-			"task_name":    task.Name,
-			"parameters":   task.Parameters,
-			"callback_url": fmt.Sprintf("http://localhost:8080/api/v1/executions/%s/callback", execution.ID),
+			"execution_id":  execution.ID,
+			"task_id":       task.ID,
+			"task_name":     task.Name,
+			"parameters":    params,
+			"fencing_token": execution.FencingToken,
+			"attempt":       execution.RetryCount, // 回调、进度和日志需回显，用于识别上一次尝试的迟到请求
+			"callback_url":  fmt.Sprintf("%s/api/v1/executions/%s/callback", r.callbackBaseURL, execution.ID),
 		}
 
 		jsonData, err := json.Marshal(payload)
@@ -366,22 +519,107 @@ func (r *TaskRunner) callExecutor(ctx context.Context, task *models.Task, execut
 	})
 }
 
-// failExecution 标记执行失败
+// StopOnExecutor 通知执行器停止指定执行
+func (r *TaskRunner) StopOnExecutor(ctx context.Context, execution *models.TaskExecution) error {
+	if execution.Executor == nil {
+		if execution.ExecutorID == nil {
+			return fmt.Errorf("execution has no executor")
+		}
+		exec, err := r.executorManager.GetExecutorByID(ctx, *execution.ExecutorID)
+		if err != nil {
+			return err
+		}
+		execution.Executor = exec
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"execution_id": execution.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal stop request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/stop", execution.Executor.BaseURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call executor stop endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("executor stop endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ErrExecutorUnreachable 无法连接执行器
+var ErrExecutorUnreachable = errors.New("executor unreachable")
+
+// QueryStatus 向执行器查询执行状态（GET /status/:execution_id）
+// 连接失败时返回 ErrExecutorUnreachable；执行器未实现该接口等非200响应返回其他错误，表示无法获知状态
+func (r *TaskRunner) QueryStatus(ctx context.Context, execution *models.TaskExecution, exec *models.Executor) (*executor.ExecutionStatusResponse, error) {
+	url := fmt.Sprintf("%s/status/%s", exec.BaseURL, execution.ID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Execution-ID", execution.ID)
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExecutorUnreachable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("executor status endpoint returned status %d", resp.StatusCode)
+	}
+
+	var status executor.ExecutionStatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("failed to decode executor status: %w", err)
+	}
+	if status.Status == "" {
+		return nil, fmt.Errorf("executor status response has no status")
+	}
+	return &status, nil
+}
+
+// failExecution 标记执行失败，执行已被取消或结束（版本号变化）时放弃
 func (r *TaskRunner) failExecution(execution *models.TaskExecution, reason string) {
 	now := time.Now()
-	execution.Status = models.ExecutionStatusFailed
-	execution.EndTime = &now
-	execution.Logs = reason
-
-	if err := r.storage.DB().Save(execution).Error; err != nil {
+	err := transitionExecution(r.storage.DB(), execution, models.ExecutionStatusFailed, map[string]interface{}{
+		"end_time":      now,
+		"logs":          reason,
+		"claimed_until": nil,
+	})
+	if errors.Is(err, ErrExecutionConflict) || errors.Is(err, ErrIllegalTransition) {
+		r.logger.Info("execution changed concurrently, not marked failed",
+			zap.String("execution_id", execution.ID),
+			zap.String("reason", reason))
+		return
+	}
+	if err != nil {
 		r.logger.Error("failed to update execution status",
 			zap.String("execution_id", execution.ID),
 			zap.Error(err))
+		return
 	}
+	execution.EndTime = &now
+	execution.Logs = reason
+	execution.ClaimedUntil = nil
 
 	r.logger.Error("task execution failed",
 		zap.String("execution_id", execution.ID),
 		zap.String("reason", reason))
+
+	r.notifyFinished(context.Background(), execution)
 }
 
 // scheduleTimeout 设置超时定时器（避免goroutine泄漏）
@@ -413,6 +651,16 @@ func (r *TaskRunner) cancelTimeout(executionID string) {
 	}
 }
 
+// extendTimeout 收到心跳后顺延本实例持有的超时定时器；定时器在其他实例上时，由其到期时按心跳时间顺延
+func (r *TaskRunner) extendTimeout(executionID string, timeout time.Duration) {
+	r.timeoutMu.Lock()
+	defer r.timeoutMu.Unlock()
+
+	if timer, exists := r.timeouts[executionID]; exists {
+		timer.Reset(timeout)
+	}
+}
+
 // handleTimeout 处理执行超时
 func (r *TaskRunner) handleTimeout(executionID string) {
 	// 清理定时器记录
@@ -420,58 +668,280 @@ func (r *TaskRunner) handleTimeout(executionID string) {
 	delete(r.timeouts, executionID)
 	r.timeoutMu.Unlock()
 
-	// 重新加载执行记录
-	var current models.TaskExecution
-	if err := r.storage.DB().Where("id = ?", executionID).First(&current).Error; err != nil {
+	// 心跳可能由其他实例接收，定时器到期时按最近一次心跳重新计算截止时间
+	var execution models.TaskExecution
+	if err := r.storage.DB().Preload("Task").Preload("Executor").Where("id = ?", executionID).First(&execution).Error; err != nil {
 		r.logger.Error("failed to load execution",
 			zap.String("execution_id", executionID),
 			zap.Error(err))
 		return
 	}
+	if execution.Status == models.ExecutionStatusRunning && execution.Task != nil && execution.Task.TimeoutSeconds > 0 {
+		deadline := execution.Deadline(time.Duration(execution.Task.TimeoutSeconds) * time.Second)
+		if remaining := time.Until(deadline); remaining > 0 {
+			r.scheduleTimeout(executionID, remaining)
+			return
+		}
+	}
 
-	// 如果仍在运行，标记为超时
-	if current.Status == models.ExecutionStatusRunning {
-		now := time.Now()
-		current.Status = models.ExecutionStatusTimeout
-		current.EndTime = &now
-		current.Logs = "Execution timeout"
-
-		if err := r.storage.DB().Save(&current).Error; err != nil {
-			r.logger.Error("failed to update execution status",
+	// 重试策略包含 timeout 时先通知执行器停止本次尝试，再重新排队
+	if execution.Status == models.ExecutionStatusRunning && execution.Task != nil && r.canRetry(execution.Task, &execution, models.ExecutionStatusTimeout) {
+		if err := r.StopOnExecutor(context.Background(), &execution); err != nil {
+			r.logger.Warn("failed to stop timed out execution before retry",
 				zap.String("execution_id", executionID),
 				zap.Error(err))
 		}
+		err := r.retryExecution(execution.Task, &execution, models.ExecutionStatusTimeout, "Execution timeout")
+		if err == nil || errors.Is(err, ErrExecutionConflict) {
+			// 版本冲突说明回调或取消已先处理了本次尝试
+			return
+		}
+		r.logger.Error("failed to requeue execution for retry",
+			zap.String("execution_id", executionID),
+			zap.Error(err))
+		return
+	}
 
-		r.logger.Warn("task execution timeout",
-			zap.String("execution_id", executionID))
+	// 只有仍在运行的执行才标记为超时，已结束或已重新排队的执行迁移不合法，直接忽略
+	now := time.Now()
+	current, changed, err := r.transition(executionID, models.ExecutionStatusTimeout, func(*models.TaskExecution) map[string]interface{} {
+		return map[string]interface{}{
+			"end_time": now,
+			"logs":     "Execution timeout",
+		}
+	})
+	if errors.Is(err, ErrIllegalTransition) {
+		return
+	}
+	if err != nil {
+		r.logger.Error("failed to update execution status",
+			zap.String("execution_id", executionID),
+			zap.Error(err))
+		return
+	}
+	if !changed {
+		return
 	}
+	current.EndTime = &now
+	current.Logs = "Execution timeout"
+
+	r.logger.Warn("task execution timeout",
+		zap.String("execution_id", executionID))
+
+	r.notifyFinished(context.Background(), current)
 }
 
 // HandleCallback 处理执行回调
+// 回调只能将运行中的执行迁移到终态：重复回调（执行已处于同一状态）和其他尝试的回调（attempt 与执行当前的尝试次数不符）
+// 直接返回成功，迟到的回调（如超时或取消后的成功回调）返回 ErrIllegalTransition
 func (r *TaskRunner) HandleCallback(ctx context.Context, executionID string, req executor.ExecutionCallbackRequest) error {
-	// 取消超时定时器（如果存在）
-	r.cancelTimeout(executionID)
+	if !req.Status.IsTerminal() {
+		return fmt.Errorf("%w: callback status %q is not terminal", ErrIllegalTransition, req.Status)
+	}
 
-	// 加载执行记录
-	var execution models.TaskExecution
-	if err := r.storage.DB().Where("id = ?", executionID).First(&execution).Error; err != nil {
+	for attempt := 1; ; attempt++ {
+		err := r.applyCallback(ctx, executionID, req)
+		// 执行被并发修改（重复回调、超时或重试），重新加载后按最新状态判定
+		if errors.Is(err, ErrExecutionConflict) && attempt < maxTransitionAttempts {
+			continue
+		}
+		return err
+	}
+}
+
+// applyCallback 按执行的最新记录处理一次回调，记录在加载后被修改时返回 ErrExecutionConflict
+func (r *TaskRunner) applyCallback(ctx context.Context, executionID string, req executor.ExecutionCallbackRequest) error {
+	var current models.TaskExecution
+	if err := r.storage.DB().Preload("Task").Where("id = ?", executionID).First(&current).Error; err != nil {
 		return fmt.Errorf("execution not found: %w", err)
 	}
+	if !currentAttempt(&current, req.Attempt) {
+		r.logger.Info("callback from another attempt ignored",
+			zap.String("execution_id", executionID),
+			zap.String("status", string(req.Status)),
+			zap.Int("retry_count", current.RetryCount))
+		return nil
+	}
+	if current.Status == req.Status {
+		r.logger.Info("duplicate execution callback ignored",
+			zap.String("execution_id", executionID),
+			zap.String("status", string(req.Status)))
+		return nil
+	}
+	if current.Status == models.ExecutionStatusPending {
+		return fmt.Errorf("%w: execution is pending and has not been dispatched", ErrIllegalTransition)
+	}
+	if current.Status == models.ExecutionStatusRunning && current.Task != nil &&
+		r.canRetry(current.Task, &current, req.Status) {
+		if err := r.retryExecution(current.Task, &current, req.Status, req.Logs); err != nil {
+			return err
+		}
+		r.cancelTimeout(executionID)
+		return nil
+	}
 
-	// 更新执行状态
 	now := time.Now()
-	execution.Status = req.Status
-	execution.EndTime = &now
-	execution.Result = req.Result
-	execution.Logs = req.Logs
-
-	if err := r.storage.DB().Save(&execution).Error; err != nil {
-		return fmt.Errorf("failed to update execution: %w", err)
+	if err := transitionExecution(r.storage.DB(), &current, req.Status, map[string]interface{}{
+		"end_time":      now,
+		"result":        models.JSONMap(req.Result),
+		"logs":          req.Logs,
+		"claimed_until": nil,
+	}); err != nil {
+		return err
 	}
+	current.EndTime = &now
+	current.Result = req.Result
+	current.Logs = req.Logs
+	current.ClaimedUntil = nil
+
+	// 取消超时定时器（如果存在）
+	r.cancelTimeout(executionID)
 
 	r.logger.Info("execution callback received",
 		zap.String("execution_id", executionID),
 		zap.String("status", string(req.Status)))
 
+	r.notifyFinished(ctx, &current)
+
+	return nil
+}
+
+// currentAttempt 执行器回显的 attempt 是否为执行当前的尝试，未回显时视为不符
+func currentAttempt(execution *models.TaskExecution, attempt *int) bool {
+	return attempt != nil && *attempt == execution.RetryCount
+}
+
+// canRetry 执行器上报或超时的终态是否按任务的重试策略重试，且重试次数未用尽
+func (r *TaskRunner) canRetry(task *models.Task, execution *models.TaskExecution, status models.ExecutionStatus) bool {
+	return task.RetryPolicy.RetriesOn(status) && execution.RetryCount < task.MaxRetry
+}
+
+// retryExecution 将运行中的执行重新排队，按重试策略的等待时长延后分发
+// 等待期间 claimed_by 为空、claimed_until 为可再次认领的时间；保留 executor_id 供更换执行器时避开
+// 执行已被并发修改时返回 ErrExecutionConflict，说明本次尝试已由其他回调或超时处理
+func (r *TaskRunner) retryExecution(task *models.Task, execution *models.TaskExecution, status models.ExecutionStatus, reason string) error {
+	retry := execution.RetryCount + 1
+	delay := task.RetryPolicy.Delay(retry)
+	notBefore := time.Now().Add(delay)
+	logs := fmt.Sprintf("Attempt %d %s, retrying in %s", retry, status, delay)
+	if reason != "" {
+		logs += ": " + reason
+	}
+
+	err := transitionExecution(r.storage.DB(), execution, models.ExecutionStatusPending, map[string]interface{}{
+		"start_time":    nil,
+		"claimed_by":    nil,
+		"claimed_until": notBefore,
+		"retry_count":   retry,
+		"logs":          logs,
+		// 进度属于上一次尝试
+		"progress_percent":  nil,
+		"progress_stage":    "",
+		"progress_counters": nil,
+		"heartbeat_at":      nil,
+	})
+	if err != nil {
+		return err
+	}
+
+	r.logger.Warn("execution will be retried",
+		zap.String("execution_id", execution.ID),
+		zap.String("status", string(status)),
+		zap.Int("retry_count", retry),
+		zap.Duration("delay", delay))
+	return nil
+}
+
+// ReportProgress 记录执行器上报的进度并刷新心跳，未上报的字段保持不变
+// 执行不在运行中时返回 ErrExecutionNotRunning，其他尝试的上报直接忽略
+func (r *TaskRunner) ReportProgress(ctx context.Context, executionID string, req executor.ExecutionProgressRequest) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"heartbeat_at": now,
+	}
+	if req.Percent != nil {
+		updates["progress_percent"] = *req.Percent
+	}
+	if req.Stage != "" {
+		updates["progress_stage"] = req.Stage
+	}
+	if req.Counters != nil {
+		counters := make(models.JSONMap, len(req.Counters))
+		for name, value := range req.Counters {
+			counters[name] = value
+		}
+		updates["progress_counters"] = counters
+	}
+
+	if req.Attempt != nil {
+		if err := r.storage.DB().Model(&models.TaskExecution{}).
+			Where("id = ? AND status = ? AND retry_count = ?", executionID, models.ExecutionStatusRunning, *req.Attempt).
+			Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update execution progress: %w", err)
+		}
+	}
+
+	var execution models.TaskExecution
+	if err := r.storage.DB().Preload("Task").Where("id = ?", executionID).First(&execution).Error; err != nil {
+		return fmt.Errorf("execution not found: %w", err)
+	}
+	if !currentAttempt(&execution, req.Attempt) {
+		// 上一次尝试的迟到上报，不能顺延当前尝试的心跳和超时
+		r.logger.Info("progress from another attempt ignored",
+			zap.String("execution_id", executionID),
+			zap.Int("retry_count", execution.RetryCount))
+		return nil
+	}
+	if execution.Status != models.ExecutionStatusRunning {
+		return fmt.Errorf("%w: execution is %s", ErrExecutionNotRunning, execution.Status)
+	}
+
+	if execution.Task != nil && execution.Task.TimeoutSeconds > 0 {
+		r.extendTimeout(executionID, time.Duration(execution.Task.TimeoutSeconds)*time.Second)
+	}
 	return nil
 }
+
+// CancelExecution 取消执行：待分发的执行直接取消，运行中的执行先通知执行器停止，
+// 执行器拒绝或无法停止时返回 ErrStopRefused 且不修改状态；执行已结束时返回 ErrIllegalTransition
+func (r *TaskRunner) CancelExecution(ctx context.Context, executionID string) (*models.TaskExecution, error) {
+	var execution models.TaskExecution
+	if err := r.storage.DB().Preload("Executor").Where("id = ?", executionID).First(&execution).Error; err != nil {
+		return nil, fmt.Errorf("execution not found: %w", err)
+	}
+	if execution.Status != models.ExecutionStatusCancelled &&
+		!execution.Status.CanTransitionTo(models.ExecutionStatusCancelled) {
+		return nil, fmt.Errorf("%w: execution is %s", ErrIllegalTransition, execution.Status)
+	}
+
+	if execution.Status == models.ExecutionStatusRunning {
+		if err := r.StopOnExecutor(ctx, &execution); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrStopRefused, err)
+		}
+	}
+
+	now := time.Now()
+	current, changed, err := r.transition(executionID, models.ExecutionStatusCancelled, func(*models.TaskExecution) map[string]interface{} {
+		return map[string]interface{}{
+			"end_time":      now,
+			"claimed_until": nil,
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return current, nil
+	}
+	current.EndTime = &now
+	current.ClaimedUntil = nil
+
+	r.cancelTimeout(executionID)
+
+	r.logger.Info("execution cancelled",
+		zap.String("execution_id", executionID))
+
+	r.notifyFinished(ctx, current)
+
+	return current, nil
+}
@@ -0,0 +1,259 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jobs/scheduler/internal/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrWorkflowRunState 工作流运行当前状态不允许该操作
+var ErrWorkflowRunState = errors.New("invalid workflow run state")
+
+// BeginRun 任务存在下游依赖时，为根执行创建工作流运行并关联
+// db 可以是调用方的事务，保证运行与根执行一起写入
+func (d *DAGEngine) BeginRun(ctx context.Context, db *gorm.DB, task *models.Task, execution *models.TaskExecution) error {
+	if execution.WorkflowRunID != nil {
+		return nil
+	}
+
+	var count int64
+	if err := db.
+		Model(&models.TaskDependency{}).
+		Where("depends_on_task_id = ?", task.ID).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to count downstream dependencies: %w", err)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	run := &models.WorkflowRun{
+		ID:              uuid.New().String(),
+		RootTaskID:      task.ID,
+		RootExecutionID: execution.ID,
+		Status:          models.WorkflowRunStatusRunning,
+		StartTime:       time.Now(),
+	}
+	if err := db.Create(run).Error; err != nil {
+		return fmt.Errorf("failed to create workflow run: %w", err)
+	}
+
+	execution.WorkflowRunID = &run.ID
+
+	d.logger.Info("workflow run started",
+		zap.String("workflow_run_id", run.ID),
+		zap.String("root_task_id", task.ID),
+		zap.String("root_execution_id", execution.ID))
+
+	return nil
+}
+
+// latestRunExecutions 获取运行内每个任务最近一次执行
+func (d *DAGEngine) latestRunExecutions(runID string) (map[string]models.TaskExecution, error) {
+	var executions []models.TaskExecution
+	if err := d.storage.DB().
+		Where("workflow_run_id = ?", runID).
+		Order("created_at ASC").
+		Find(&executions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load workflow executions: %w", err)
+	}
+
+	latest := make(map[string]models.TaskExecution, len(executions))
+	for _, e := range executions {
+		latest[e.TaskID] = e
+	}
+	return latest, nil
+}
+
+// refreshRunStatus 运行内所有执行都进入终态后汇总运行状态
+func (d *DAGEngine) refreshRunStatus(runID string) {
+	var run models.WorkflowRun
+	if err := d.storage.DB().Where("id = ?", runID).First(&run).Error; err != nil {
+		d.logger.Error("failed to load workflow run",
+			zap.String("workflow_run_id", runID),
+			zap.Error(err))
+		return
+	}
+	if run.Status == models.WorkflowRunStatusCancelled {
+		return
+	}
+
+	latest, err := d.latestRunExecutions(runID)
+	if err != nil {
+		d.logger.Error("failed to refresh workflow run status",
+			zap.String("workflow_run_id", runID),
+			zap.Error(err))
+		return
+	}
+
+	var succeeded, unsucceeded int
+	for _, e := range latest {
+		if !e.Status.IsTerminal() {
+			return
+		}
+		switch e.Status {
+		case models.ExecutionStatusSuccess:
+			succeeded++
+		case models.ExecutionStatusSkipped:
+			// 按依赖策略跳过的分支是预期结果，不计为失败
+		default:
+			unsucceeded++
+		}
+	}
+
+	status := models.WorkflowRunStatusSucceeded
+	if unsucceeded > 0 {
+		status = models.WorkflowRunStatusFailed
+		if succeeded > 0 {
+			status = models.WorkflowRunStatusPartiallyFailed
+		}
+	}
+
+	now := time.Now()
+	if err := d.storage.DB().
+		Model(&models.WorkflowRun{}).
+		Where("id = ? AND status = ?", runID, models.WorkflowRunStatusRunning).
+		Updates(map[string]interface{}{
+			"status":   status,
+			"end_time": now,
+		}).Error; err != nil {
+		d.logger.Error("failed to update workflow run status",
+			zap.String("workflow_run_id", runID),
+			zap.Error(err))
+		return
+	}
+
+	d.logger.Info("workflow run finished",
+		zap.String("workflow_run_id", runID),
+		zap.String("status", string(status)))
+}
+
+// CancelRun 取消运行：待执行的直接取消，运行中的通知执行器停止
+func (d *DAGEngine) CancelRun(ctx context.Context, runID string) (*models.WorkflowRun, error) {
+	var run models.WorkflowRun
+	if err := d.storage.DB().Where("id = ?", runID).First(&run).Error; err != nil {
+		return nil, err
+	}
+	if run.Status != models.WorkflowRunStatusRunning {
+		return nil, fmt.Errorf("%w: workflow run is %s", ErrWorkflowRunState, run.Status)
+	}
+
+	now := time.Now()
+	run.Status = models.WorkflowRunStatusCancelled
+	run.EndTime = &now
+	if err := d.storage.DB().Save(&run).Error; err != nil {
+		return nil, fmt.Errorf("failed to update workflow run: %w", err)
+	}
+
+	var active []models.TaskExecution
+	if err := d.storage.DB().
+		Where("workflow_run_id = ? AND status IN ?", runID,
+			[]models.ExecutionStatus{models.ExecutionStatusPending, models.ExecutionStatusRunning}).
+		Find(&active).Error; err != nil {
+		return nil, fmt.Errorf("failed to load active executions: %w", err)
+	}
+
+	// 执行器拒绝停止的执行保持运行，由回调或超时结束；运行已取消，不会再派生下游执行
+	for i := range active {
+		if _, err := d.taskRunner.CancelExecution(ctx, active[i].ID); err != nil {
+			d.logger.Error("failed to cancel execution",
+				zap.String("workflow_run_id", runID),
+				zap.String("execution_id", active[i].ID),
+				zap.Error(err))
+		}
+	}
+
+	d.logger.Info("workflow run cancelled",
+		zap.String("workflow_run_id", runID),
+		zap.Int("active_executions", len(active)))
+
+	return &run, nil
+}
+
+// RerunFromFailed 在原运行内重新执行失败节点，成功后按依赖继续向下游推进
+// taskIDs为空时重跑所有最近一次执行为 failed/timeout/cancelled 的节点
+func (d *DAGEngine) RerunFromFailed(ctx context.Context, runID string, taskIDs []string) ([]*models.TaskExecution, error) {
+	var run models.WorkflowRun
+	if err := d.storage.DB().Where("id = ?", runID).First(&run).Error; err != nil {
+		return nil, err
+	}
+	if run.Status == models.WorkflowRunStatusRunning {
+		return nil, fmt.Errorf("%w: workflow run is still running", ErrWorkflowRunState)
+	}
+
+	latest, err := d.latestRunExecutions(runID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(taskIDs) == 0 {
+		for taskID, e := range latest {
+			switch e.Status {
+			case models.ExecutionStatusFailed, models.ExecutionStatusTimeout, models.ExecutionStatusCancelled:
+				taskIDs = append(taskIDs, taskID)
+			}
+		}
+		if len(taskIDs) == 0 {
+			return nil, fmt.Errorf("%w: no failed nodes to rerun", ErrWorkflowRunState)
+		}
+	}
+
+	tasks := make([]models.Task, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		if _, ok := latest[taskID]; !ok {
+			return nil, fmt.Errorf("%w: task %s is not part of workflow run", ErrWorkflowRunState, taskID)
+		}
+		var task models.Task
+		if err := d.storage.DB().Where("id = ?", taskID).First(&task).Error; err != nil {
+			return nil, fmt.Errorf("task not found: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := d.storage.DB().
+		Model(&models.WorkflowRun{}).
+		Where("id = ?", runID).
+		Updates(map[string]interface{}{
+			"status":   models.WorkflowRunStatusRunning,
+			"end_time": nil,
+		}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update workflow run: %w", err)
+	}
+
+	executions := make([]*models.TaskExecution, 0, len(tasks))
+	for i := range tasks {
+		task := &tasks[i]
+		previous := latest[task.ID]
+		execution := &models.TaskExecution{
+			ID:                uuid.New().String(),
+			TaskID:            task.ID,
+			ScheduledTime:     time.Now(),
+			Status:            models.ExecutionStatusPending,
+			WorkflowRunID:     &run.ID,
+			ParentExecutionID: previous.ParentExecutionID,
+			FencingToken:      previous.FencingToken,
+			Parameters:        sourceParameters(&previous), // 沿用失败节点实际下发的参数以便复现
+		}
+		if execution.Parameters == nil {
+			execution.Parameters = task.EffectiveParameters(nil)
+		}
+		if err := d.storage.DB().Create(execution).Error; err != nil {
+			return executions, fmt.Errorf("failed to create execution record: %w", err)
+		}
+
+		d.taskRunner.Submit(task, execution)
+		executions = append(executions, execution)
+	}
+
+	d.logger.Info("workflow run rerun from failed nodes",
+		zap.String("workflow_run_id", runID),
+		zap.Strings("task_ids", taskIDs))
+
+	return executions, nil
+}
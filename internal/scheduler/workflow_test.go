@@ -0,0 +1,199 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startRun 为根任务创建执行和工作流运行
+func startRun(t *testing.T, s *Scheduler, taskID string) *models.TaskExecution {
+	t.Helper()
+	var task models.Task
+	require.NoError(t, s.storage.DB().Where("id = ?", taskID).First(&task).Error)
+	execution := &models.TaskExecution{
+		ID:            "root-" + taskID,
+		TaskID:        taskID,
+		ScheduledTime: time.Now(),
+		Status:        models.ExecutionStatusRunning,
+		FencingToken:  7,
+		Parameters:    models.JSONMap{"date": "2024-01-01"},
+	}
+	require.NoError(t, s.dag.BeginRun(context.Background(), s.storage.DB(), &task, execution))
+	require.NoError(t, s.storage.DB().Create(execution).Error)
+	return execution
+}
+
+// endExecution 将执行置为终态并通知依赖引擎
+func endExecution(t *testing.T, s *Scheduler, execution *models.TaskExecution, status models.ExecutionStatus) {
+	t.Helper()
+	now := time.Now()
+	require.NoError(t, s.storage.DB().Model(&models.TaskExecution{}).
+		Where("id = ?", execution.ID).
+		Updates(map[string]interface{}{"status": status, "end_time": now}).Error)
+	execution.Status = status
+	execution.EndTime = &now
+	s.dag.OnExecutionFinished(context.Background(), execution)
+}
+
+func loadRun(t *testing.T, s *Scheduler, id string) models.WorkflowRun {
+	t.Helper()
+	var run models.WorkflowRun
+	require.NoError(t, s.storage.DB().Where("id = ?", id).First(&run).Error)
+	return run
+}
+
+func TestBeginRunOnlyForTasksWithDownstream(t *testing.T) {
+	s, st := newManualScheduler(t)
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, st.DB().Create(&models.Task{ID: id, Name: id, CronExpression: "0 0 * * * *"}).Error)
+	}
+	dependOn(t, s, "b", "a", "")
+
+	root := startRun(t, s, "a")
+	require.NotNil(t, root.WorkflowRunID)
+	run := loadRun(t, s, *root.WorkflowRunID)
+	assert.Equal(t, "a", run.RootTaskID)
+	assert.Equal(t, root.ID, run.RootExecutionID)
+	assert.Equal(t, models.WorkflowRunStatusRunning, run.Status)
+
+	// 没有下游的任务不创建运行
+	leaf := startRun(t, s, "c")
+	assert.Nil(t, leaf.WorkflowRunID)
+}
+
+func TestWorkflowRunStatusAggregation(t *testing.T) {
+	s, st := newManualScheduler(t)
+	for _, id := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, st.DB().Create(&models.Task{ID: id, Name: id, CronExpression: "0 0 * * * *"}).Error)
+	}
+	// a -> b -> c，b 失败时 c 被跳过；a -> d
+	dependOn(t, s, "b", "a", "")
+	dependOn(t, s, "c", "b", models.DependencyPolicySkip)
+	dependOn(t, s, "d", "a", "")
+
+	root := startRun(t, s, "a")
+	runID := *root.WorkflowRunID
+	endExecution(t, s, root, models.ExecutionStatusSuccess)
+
+	b := latestOf(t, s, "b")
+	d := latestOf(t, s, "d")
+	require.NotNil(t, b)
+	require.NotNil(t, d)
+	assert.Equal(t, runID, *b.WorkflowRunID)
+
+	endExecution(t, s, b, models.ExecutionStatusFailed)
+	assert.Equal(t, models.ExecutionStatusSkipped, latestOf(t, s, "c").Status)
+	// d 仍在等待分发，运行未结束
+	assert.Equal(t, models.WorkflowRunStatusRunning, loadRun(t, s, runID).Status)
+
+	endExecution(t, s, d, models.ExecutionStatusSuccess)
+	run := loadRun(t, s, runID)
+	assert.Equal(t, models.WorkflowRunStatusPartiallyFailed, run.Status)
+	assert.NotNil(t, run.EndTime)
+
+	// 跳过的节点不计为失败
+	for name, tc := range map[string]struct {
+		statuses []models.ExecutionStatus
+		want     models.WorkflowRunStatus
+	}{
+		"skipped":  {[]models.ExecutionStatus{models.ExecutionStatusSuccess, models.ExecutionStatusSkipped}, models.WorkflowRunStatusSucceeded},
+		"failed":   {[]models.ExecutionStatus{models.ExecutionStatusTimeout, models.ExecutionStatusSkipped}, models.WorkflowRunStatusFailed},
+		"partial":  {[]models.ExecutionStatus{models.ExecutionStatusSuccess, models.ExecutionStatusCancelled}, models.WorkflowRunStatusPartiallyFailed},
+		"unfinish": {[]models.ExecutionStatus{models.ExecutionStatusSuccess, models.ExecutionStatusRunning}, models.WorkflowRunStatusRunning},
+	} {
+		run := models.WorkflowRun{ID: "run-" + name, RootTaskID: "a", RootExecutionID: "x", Status: models.WorkflowRunStatusRunning, StartTime: time.Now()}
+		require.NoError(t, st.DB().Create(&run).Error)
+		for i, status := range tc.statuses {
+			require.NoError(t, st.DB().Create(&models.TaskExecution{
+				ID:            run.ID + "-" + string(rune('a'+i)),
+				TaskID:        string(rune('a' + i)),
+				ScheduledTime: time.Now(),
+				Status:        status,
+				WorkflowRunID: &run.ID,
+			}).Error)
+		}
+		s.dag.refreshRunStatus(run.ID)
+		assert.Equal(t, tc.want, loadRun(t, s, run.ID).Status, name)
+	}
+}
+
+func TestRerunFromFailed(t *testing.T) {
+	s, st := newManualScheduler(t)
+	ctx := context.Background()
+	for _, id := range []string{"a", "b"} {
+		require.NoError(t, st.DB().Create(&models.Task{ID: id, Name: id, CronExpression: "0 0 * * * *"}).Error)
+	}
+	dependOn(t, s, "b", "a", models.DependencyPolicySkip)
+
+	root := startRun(t, s, "a")
+	runID := *root.WorkflowRunID
+
+	// 运行中不能重跑
+	_, err := s.dag.RerunFromFailed(ctx, runID, nil)
+	assert.ErrorIs(t, err, ErrWorkflowRunState)
+
+	endExecution(t, s, root, models.ExecutionStatusFailed)
+	assert.Equal(t, models.ExecutionStatusSkipped, latestOf(t, s, "b").Status)
+	assert.Equal(t, models.WorkflowRunStatusFailed, loadRun(t, s, runID).Status)
+
+	_, err = s.dag.RerunFromFailed(ctx, runID, []string{"other"})
+	assert.ErrorIs(t, err, ErrWorkflowRunState)
+
+	// 只重跑失败节点，沿用原执行的参数和令牌
+	executions, err := s.dag.RerunFromFailed(ctx, runID, nil)
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+	rerun := executions[0]
+	assert.Equal(t, "a", rerun.TaskID)
+	assert.Equal(t, runID, *rerun.WorkflowRunID)
+	assert.Equal(t, models.ExecutionStatusPending, rerun.Status)
+	assert.Equal(t, root.Parameters, rerun.Parameters)
+	assert.Equal(t, root.FencingToken, rerun.FencingToken)
+	assert.Equal(t, models.WorkflowRunStatusRunning, loadRun(t, s, runID).Status)
+
+	// 重跑成功后继续推进被跳过的下游
+	endExecution(t, s, rerun, models.ExecutionStatusSuccess)
+	b := latestOf(t, s, "b")
+	assert.Equal(t, models.ExecutionStatusPending, b.Status)
+	assert.Equal(t, rerun.ID, *b.ParentExecutionID)
+
+	endExecution(t, s, b, models.ExecutionStatusSuccess)
+	run := loadRun(t, s, runID)
+	assert.Equal(t, models.WorkflowRunStatusSucceeded, run.Status)
+
+	// 没有失败节点时无可重跑
+	_, err = s.dag.RerunFromFailed(ctx, runID, nil)
+	assert.ErrorIs(t, err, ErrWorkflowRunState)
+}
+
+func TestCancelRun(t *testing.T) {
+	s, st := newManualScheduler(t)
+	ctx := context.Background()
+	for _, id := range []string{"a", "b"} {
+		require.NoError(t, st.DB().Create(&models.Task{ID: id, Name: id, CronExpression: "0 0 * * * *"}).Error)
+	}
+	dependOn(t, s, "b", "a", "")
+
+	root := startRun(t, s, "a")
+	runID := *root.WorkflowRunID
+	endExecution(t, s, root, models.ExecutionStatusSuccess)
+	b := latestOf(t, s, "b")
+	require.Equal(t, models.ExecutionStatusPending, b.Status)
+
+	run, err := s.dag.CancelRun(ctx, runID)
+	require.NoError(t, err)
+	assert.Equal(t, models.WorkflowRunStatusCancelled, run.Status)
+	assert.Equal(t, models.ExecutionStatusCancelled, latestOf(t, s, "b").Status)
+
+	// 已取消的运行不再汇总状态
+	s.dag.refreshRunStatus(runID)
+	assert.Equal(t, models.WorkflowRunStatusCancelled, loadRun(t, s, runID).Status)
+
+	_, err = s.dag.CancelRun(ctx, runID)
+	assert.ErrorIs(t, err, ErrWorkflowRunState)
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// 支持的数据库驱动
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Dialect 数据库方言，屏蔽不同数据库在连接和SQL上的差异
+type Dialect interface {
+	// Name 驱动名称
+	Name() string
+	// Dialector 根据配置创建gorm驱动
+	Dialector(cfg Config) gorm.Dialector
+	// SecondsBetween 计算两个时间列相差秒数的SQL表达式
+	SecondsBetween(start, end string) string
+	// SkipLocked 是否支持 SELECT ... FOR UPDATE SKIP LOCKED
+	SkipLocked() bool
+}
+
+// NewDialect 根据驱动名称创建方言，为空时默认MySQL
+func NewDialect(driver string) (Dialect, error) {
+	switch strings.ToLower(driver) {
+	case "", DriverMySQL:
+		return mysqlDialect{}, nil
+	case DriverPostgres, "postgresql", "pg":
+		return postgresDialect{}, nil
+	case DriverSQLite, "sqlite3":
+		return sqliteDialect{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", driver)
+	}
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return DriverMySQL }
+
+func (mysqlDialect) Dialector(cfg Config) gorm.Dialector {
+	dsn := cfg.DSN
+	if dsn == "" {
+		dsn = fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Database)
+	}
+	return mysql.Open(dsn)
+}
+
+func (mysqlDialect) SecondsBetween(start, end string) string {
+	return fmt.Sprintf("TIMESTAMPDIFF(SECOND, %s, %s)", start, end)
+}
+
+// SkipLocked MySQL 8.0 起支持
+func (mysqlDialect) SkipLocked() bool { return true }
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return DriverPostgres }
+
+func (postgresDialect) Dialector(cfg Config) gorm.Dialector {
+	dsn := cfg.DSN
+	if dsn == "" {
+		sslMode := cfg.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		dsn = fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Database, sslMode)
+	}
+	return postgres.Open(dsn)
+}
+
+func (postgresDialect) SecondsBetween(start, end string) string {
+	return fmt.Sprintf("EXTRACT(EPOCH FROM (%s - %s))", end, start)
+}
+
+func (postgresDialect) SkipLocked() bool { return true }
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return DriverSQLite }
+
+// Dialector SQLite使用 Database 作为数据库文件路径，":memory:" 表示内存库
+func (sqliteDialect) Dialector(cfg Config) gorm.Dialector {
+	dsn := cfg.DSN
+	if dsn == "" {
+		path := cfg.Database
+		if path == "" || path == ":memory:" {
+			path = "file::memory:?cache=shared"
+		} else {
+			path = "file:" + path + "?cache=shared"
+		}
+		dsn = path + "&_busy_timeout=5000&_foreign_keys=on"
+	}
+	return sqlite.Open(dsn)
+}
+
+func (sqliteDialect) SecondsBetween(start, end string) string {
+	return fmt.Sprintf("(julianday(%s) - julianday(%s)) * 86400", end, start)
+}
+
+// SkipLocked SQLite没有行锁，写事务本身互斥
+func (sqliteDialect) SkipLocked() bool { return false }
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDialect(t *testing.T) {
+	for driver, want := range map[string]string{
+		"":           DriverMySQL,
+		"mysql":      DriverMySQL,
+		"postgres":   DriverPostgres,
+		"postgresql": DriverPostgres,
+		"pg":         DriverPostgres,
+		"sqlite":     DriverSQLite,
+		"SQLite3":    DriverSQLite,
+	} {
+		dialect, err := NewDialect(driver)
+		require.NoError(t, err, driver)
+		assert.Equal(t, want, dialect.Name(), driver)
+	}
+
+	_, err := NewDialect("oracle")
+	assert.Error(t, err)
+}
+
+// TestOpenDialects 打开每种方言并完成迁移；MySQL 和 PostgreSQL 需要通过环境变量提供连接串，未提供时跳过
+func TestOpenDialects(t *testing.T) {
+	cases := map[string]Config{
+		DriverSQLite:   {Driver: DriverSQLite, Database: filepath.Join(t.TempDir(), "scheduler.db")},
+		DriverMySQL:    {Driver: DriverMySQL, DSN: os.Getenv("SCHEDULER_TEST_MYSQL_DSN"), MaxConnections: 4},
+		DriverPostgres: {Driver: DriverPostgres, DSN: os.Getenv("SCHEDULER_TEST_POSTGRES_DSN"), MaxConnections: 4},
+	}
+	for name, cfg := range cases {
+		t.Run(name, func(t *testing.T) {
+			if name != DriverSQLite && cfg.DSN == "" {
+				t.Skipf("set SCHEDULER_TEST_%s_DSN to run", map[string]string{DriverMySQL: "MYSQL", DriverPostgres: "POSTGRES"}[name])
+			}
+
+			st, err := New(cfg)
+			require.NoError(t, err)
+			defer st.Close()
+			require.NoError(t, st.Ping())
+			assert.Equal(t, name, st.Dialect().Name())
+
+			// 方言相关的SQL在真实数据库上可执行
+			task := models.Task{ID: "dialect-" + name, Name: "dialect-" + name, CronExpression: "0 * * * * *"}
+			require.NoError(t, st.DB().Create(&task).Error)
+			defer st.DB().Delete(&task)
+
+			start := time.Now()
+			execution := models.TaskExecution{
+				ID:            "dialect-" + name,
+				TaskID:        task.ID,
+				ScheduledTime: start,
+				Status:        models.ExecutionStatusSuccess,
+				StartTime:     &start,
+			}
+			end := start.Add(90 * time.Second)
+			execution.EndTime = &end
+			require.NoError(t, st.DB().Create(&execution).Error)
+			defer st.DB().Delete(&execution)
+
+			var seconds float64
+			require.NoError(t, st.DB().Model(&models.TaskExecution{}).
+				Select(st.Dialect().SecondsBetween("start_time", "end_time")).
+				Where("id = ?", execution.ID).
+				Scan(&seconds).Error)
+			assert.InDelta(t, 90, seconds, 1)
+		})
+	}
+}
@@ -5,12 +5,14 @@ import (
 	"time"
 
 	"github.com/jobs/scheduler/internal/models"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 type Config struct {
+	Driver                string // mysql（默认）、postgres、sqlite
+	DSN                   string // 完整连接串，设置后忽略Host等字段
+	SSLMode               string // 仅postgres使用
 	Host                  string
 	Port                  int
 	Database              string
@@ -22,14 +24,17 @@ type Config struct {
 }
 
 type Storage struct {
-	db *gorm.DB
+	db      *gorm.DB
+	dialect Dialect
 }
 
 func New(cfg Config) (*Storage, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Database)
+	dialect, err := NewDialect(cfg.Driver)
+	if err != nil {
+		return nil, err
+	}
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+	db, err := gorm.Open(dialect.Dialector(cfg), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
 	if err != nil {
@@ -45,25 +50,42 @@ func New(cfg Config) (*Storage, error) {
 	sqlDB.SetMaxIdleConns(cfg.MaxIdleConnections)
 	sqlDB.SetConnMaxLifetime(cfg.ConnectionMaxLifetime)
 
+	// SQLite同一时间只允许一个写者，单连接避免 database is locked
+	if dialect.Name() == DriverSQLite {
+		sqlDB.SetMaxOpenConns(1)
+	}
+
 	// 自动迁移
 	if err := db.AutoMigrate(
 		&models.Task{},
+		&models.WorkflowRun{},
+		&models.Backfill{},
 		&models.Executor{},
 		&models.TaskExecutor{},
 		&models.TaskExecution{},
+		&models.ExecutionLogChunk{},
 		&models.LoadBalanceState{},
 		&models.SchedulerInstance{},
+		&models.TaskDependency{},
+		&models.SchedulerLock{},
+		&models.SchedulerLease{},
+		&models.CallbackNonce{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
-	return &Storage{db: db}, nil
+	return &Storage{db: db, dialect: dialect}, nil
 }
 
 func (s *Storage) DB() *gorm.DB {
 	return s.db
 }
 
+// Dialect 返回当前数据库方言
+func (s *Storage) Dialect() Dialect {
+	return s.dialect
+}
+
 func (s *Storage) Close() error {
 	sqlDB, err := s.db.DB()
 	if err != nil {
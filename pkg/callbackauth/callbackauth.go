@@ -0,0 +1,98 @@
+// Package callbackauth 执行器回调的签名与校验。
+//
+// 执行器注册时获得 executor_id 和 callback_secret，回调时携带以下请求头：
+//   - X-Executor-ID：注册返回的执行器ID
+//   - X-Callback-Timestamp：Unix 秒级时间戳，与调度器时间相差不得超过 MaxSkew
+//   - X-Callback-Nonce：随机串，有效期内不得重复
+//   - X-Callback-Signature：hex(HMAC-SHA256(callback_secret, timestamp + "\n" + nonce + "\n" + body))
+//
+// 已有执行器重新注册时同样用当前密钥签名注册请求，无法签名（如重启后丢失密钥）时携带 X-Registration-Token。
+package callbackauth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// 回调签名使用的请求头
+const (
+	HeaderExecutorID = "X-Executor-ID"
+	HeaderTimestamp  = "X-Callback-Timestamp"
+	HeaderNonce      = "X-Callback-Nonce"
+	HeaderSignature  = "X-Callback-Signature"
+
+	// HeaderRegistrationToken 重新注册已有执行器时出示的注册令牌
+	HeaderRegistrationToken = "X-Registration-Token"
+)
+
+// MaxSkew 回调时间戳允许的最大偏差，nonce 至少保留两倍于此的时间
+const MaxSkew = 5 * time.Minute
+
+// GenerateSecret 生成新的回调密钥
+func GenerateSecret() (string, error) {
+	return randomHex(32)
+}
+
+// NewNonce 生成随机 nonce
+func NewNonce() (string, error) {
+	return randomHex(16)
+}
+
+// Sign 计算回调签名
+func Sign(secret, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(nonce))
+	mac.Write([]byte("\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 以常量时间比较签名
+func Verify(secret, timestamp, nonce string, body []byte, signature string) bool {
+	expected := Sign(secret, timestamp, nonce, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// CheckTimestamp 校验时间戳格式及与 now 的偏差
+func CheckTimestamp(timestamp string, now time.Time) error {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid callback timestamp: %s", timestamp)
+	}
+	skew := now.Sub(time.Unix(sec, 0))
+	if skew > MaxSkew || skew < -MaxSkew {
+		return fmt.Errorf("callback timestamp is outside the allowed window of %s", MaxSkew)
+	}
+	return nil
+}
+
+// SignRequest 为回调请求设置签名请求头，body 必须与请求体完全一致
+func SignRequest(req *http.Request, executorID, secret string, body []byte) error {
+	nonce, err := NewNonce()
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set(HeaderExecutorID, executorID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderNonce, nonce)
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, nonce, body))
+	return nil
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,43 @@
+package callbackauth
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"execution_id":"e1","status":"success"}`)
+	signature := Sign("secret", "1700000000", "n1", body)
+
+	assert.True(t, Verify("secret", "1700000000", "n1", body, signature))
+	assert.False(t, Verify("other", "1700000000", "n1", body, signature))
+	assert.False(t, Verify("secret", "1700000001", "n1", body, signature))
+	assert.False(t, Verify("secret", "1700000000", "n2", body, signature))
+	assert.False(t, Verify("secret", "1700000000", "n1", []byte(`{"execution_id":"e1","status":"failed"}`), signature))
+}
+
+func TestCheckTimestamp(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	assert.NoError(t, CheckTimestamp("1700000000", now))
+	assert.NoError(t, CheckTimestamp(strconv.FormatInt(now.Add(-MaxSkew+time.Second).Unix(), 10), now))
+	assert.Error(t, CheckTimestamp(strconv.FormatInt(now.Add(-MaxSkew-time.Second).Unix(), 10), now))
+	assert.Error(t, CheckTimestamp(strconv.FormatInt(now.Add(MaxSkew+time.Second).Unix(), 10), now))
+	assert.Error(t, CheckTimestamp("yesterday", now))
+}
+
+func TestSignRequest(t *testing.T) {
+	body := []byte(`{}`)
+	req, err := http.NewRequest(http.MethodPost, "http://scheduler/callback", nil)
+	require.NoError(t, err)
+	require.NoError(t, SignRequest(req, "executor-1", "secret", body))
+
+	assert.Equal(t, "executor-1", req.Header.Get(HeaderExecutorID))
+	assert.NoError(t, CheckTimestamp(req.Header.Get(HeaderTimestamp), time.Now()))
+	assert.True(t, Verify("secret", req.Header.Get(HeaderTimestamp), req.Header.Get(HeaderNonce), body, req.Header.Get(HeaderSignature)))
+}
@@ -0,0 +1,24 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Apply 将任务清单应用到调度器，返回的差异在 dry-run 时为计划变更，否则为已应用的变更
+func (c *Client) Apply(ctx context.Context, m *Manifest, opts ApplyOptions) (*ApplyPlan, error) {
+	query := url.Values{}
+	if opts.DryRun {
+		query.Set("dry_run", "true")
+	}
+	if opts.Prune {
+		query.Set("prune", "true")
+	}
+
+	var plan ApplyPlan
+	if err := c.do(ctx, http.MethodPost, "/apply", query, m, &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+)
+
+// CreateBackfill 为任务创建历史区间补跑，区间内没有触发时间或触发次数过多时返回 ErrBadRequest
+func (c *Client) CreateBackfill(ctx context.Context, taskID string, req BackfillRequest) (*Backfill, error) {
+	var backfill Backfill
+	if err := c.do(ctx, http.MethodPost, "/tasks/"+escape(taskID)+"/backfill", nil, req, &backfill); err != nil {
+		return nil, err
+	}
+	return &backfill, nil
+}
+
+// ListBackfills 获取一页补跑，按创建时间倒序
+func (c *Client) ListBackfills(ctx context.Context, filter BackfillFilter) (*BackfillPage, error) {
+	query := url.Values{}
+	if filter.TaskID != "" {
+		query.Set("task_id", filter.TaskID)
+	}
+	if filter.Status != "" {
+		query.Set("status", string(filter.Status))
+	}
+	setPage(query, filter.Page, filter.PageSize)
+
+	var page BackfillPage
+	if err := c.do(ctx, http.MethodGet, "/backfills", query, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Backfills 从 filter.Page 开始逐页遍历补跑，出错时产出错误并结束
+func (c *Client) Backfills(ctx context.Context, filter BackfillFilter) iter.Seq2[Backfill, error] {
+	return func(yield func(Backfill, error) bool) {
+		if filter.Page <= 0 {
+			filter.Page = 1
+		}
+		for {
+			page, err := c.ListBackfills(ctx, filter)
+			if err != nil {
+				yield(Backfill{}, err)
+				return
+			}
+			for _, backfill := range page.Data {
+				if !yield(backfill, nil) {
+					return
+				}
+			}
+			if page.Page >= page.TotalPages || len(page.Data) == 0 {
+				return
+			}
+			filter.Page = page.Page + 1
+		}
+	}
+}
+
+// GetBackfill 获取补跑及其已创建的执行
+func (c *Client) GetBackfill(ctx context.Context, id string) (*Backfill, error) {
+	var backfill Backfill
+	if err := c.do(ctx, http.MethodGet, "/backfills/"+escape(id), nil, nil, &backfill); err != nil {
+		return nil, err
+	}
+	return &backfill, nil
+}
+
+// CancelBackfill 取消补跑，已结束时返回 ErrConflict
+func (c *Client) CancelBackfill(ctx context.Context, id string) (*Backfill, error) {
+	var backfill Backfill
+	if err := c.do(ctx, http.MethodPost, "/backfills/"+escape(id)+"/cancel", nil, nil, &backfill); err != nil {
+		return nil, err
+	}
+	return &backfill, nil
+}
@@ -0,0 +1,145 @@
+// Package client 调度器 REST API 的 Go 客户端。
+//
+//	c := client.New(client.Config{BaseURL: "http://scheduler:8080"})
+//	task, err := c.CreateTask(ctx, client.CreateTaskRequest{Name: "daily_report", CronExpression: "0 0 6 * * *"})
+//	for execution, err := range c.Executions(ctx, client.ExecutionFilter{TaskID: task.ID}) {
+//		...
+//	}
+//
+// 服务端返回的错误转换为 *APIError，可用 errors.Is(err, client.ErrNotFound) 等判断。
+// 执行器回调、进度和日志上报需要签名，由 pkg/executor 负责。
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Config 客户端配置
+type Config struct {
+	// BaseURL 调度器地址，例如 http://scheduler:8080
+	BaseURL string
+	// HTTPClient 发送请求使用的客户端，默认超时 30 秒
+	HTTPClient *http.Client
+	// Header 附加到每个请求的请求头，例如经网关访问时的认证信息
+	Header http.Header
+}
+
+// Client 调度器 API 客户端，可并发使用
+type Client struct {
+	baseURL string
+	http    *http.Client
+	header  http.Header
+}
+
+// New 创建客户端
+func New(cfg Config) *Client {
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(cfg.BaseURL, "/") + "/api/v1",
+		http:    cfg.HTTPClient,
+		header:  cfg.Header,
+	}
+}
+
+// Health 调度器健康检查，数据库不可用时返回 ErrUnavailable
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	var health Health
+	if err := c.do(ctx, http.MethodGet, "/health", nil, nil, &health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// SchedulerStatus 获取调度器实例和租约
+func (c *Client) SchedulerStatus(ctx context.Context) (*SchedulerStatus, error) {
+	var status SchedulerStatus
+	if err := c.do(ctx, http.MethodGet, "/scheduler/status", nil, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// PreviewCron 预览 cron 表达式接下来的触发时间
+func (c *Client) PreviewCron(ctx context.Context, req CronPreviewRequest) (*CronPreview, error) {
+	var preview CronPreview
+	if err := c.do(ctx, http.MethodPost, "/cron/preview", nil, req, &preview); err != nil {
+		return nil, err
+	}
+	return &preview, nil
+}
+
+// newRequest 构造请求，body 不为 nil 时编码为 JSON
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Request, error) {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range c.header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// do 发送请求并将 2xx 响应解码到 out，其余状态码转换为 *APIError
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	req, err := c.newRequest(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(method, path, resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: failed to decode response: %w", method, path, err)
+	}
+	return nil
+}
+
+// escape 转义路径参数
+func escape(id string) string {
+	return url.PathEscape(id)
+}
+
+func formatTime(t *time.Time) string {
+	return t.Format(time.RFC3339)
+}
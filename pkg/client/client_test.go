@@ -0,0 +1,140 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/internal/api"
+	"github.com/jobs/scheduler/internal/models"
+	"github.com/jobs/scheduler/internal/scheduler"
+	"github.com/jobs/scheduler/internal/storage"
+	"github.com/jobs/scheduler/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newTestClient(t *testing.T) (*Client, *storage.Storage) {
+	st, err := storage.New(storage.Config{
+		Driver:   storage.DriverSQLite,
+		Database: filepath.Join(t.TempDir(), "client.db"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { st.Close() })
+
+	logger := zap.NewNop()
+	runner := scheduler.NewTaskRunner(st, nil, nil, logger, config.SchedulerConfig{InstanceID: "test", MaxWorkers: 1})
+	server := httptest.NewServer(api.NewServer(st, nil, nil, runner, logger).Router())
+	t.Cleanup(server.Close)
+
+	return New(Config{BaseURL: server.URL}), st
+}
+
+func TestClientExecutions(t *testing.T) {
+	c, st := newTestClient(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	require.NoError(t, st.DB().Create(&models.Task{ID: "task-1", Name: "task-1", CronExpression: "0 * * * * *"}).Error)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, st.DB().Create(&models.TaskExecution{
+			ID:            fmt.Sprintf("exec-%d", i),
+			TaskID:        "task-1",
+			ScheduledTime: now.Add(-time.Duration(i) * time.Minute),
+			EndTime:       &now,
+			Status:        models.ExecutionStatusSuccess,
+		}).Error)
+	}
+	require.NoError(t, st.DB().Create(&models.ExecutionLogChunk{ExecutionID: "exec-0", Seq: 1, Content: "line 1\n", Size: 7}).Error)
+	require.NoError(t, st.DB().Create(&models.ExecutionLogChunk{ExecutionID: "exec-0", Seq: 2, Content: "line 2\n", Size: 7}).Error)
+
+	// 迭代器逐页读取全部执行
+	var ids []string
+	for execution, err := range c.Executions(ctx, ExecutionFilter{TaskID: "task-1", PageSize: 2}) {
+		require.NoError(t, err)
+		ids = append(ids, execution.ID)
+	}
+	assert.Equal(t, []string{"exec-0", "exec-1", "exec-2", "exec-3", "exec-4"}, ids)
+
+	// 服务端错误映射为类型化错误
+	_, err := c.GetTask(ctx, "missing")
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.Equal(t, "task not found", apiErr.Message)
+
+	_, err = c.StopExecution(ctx, "exec-1")
+	assert.ErrorIs(t, err, ErrConflict)
+
+	var lines []string
+	status, err := c.FollowExecutionLogs(ctx, "exec-0", 0, func(chunk ExecutionLogChunk) error {
+		lines = append(lines, chunk.Content)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ExecutionStatusSuccess, status)
+	assert.Equal(t, []string{"line 1\n", "line 2\n"}, lines)
+}
+
+func TestClientApply(t *testing.T) {
+	c, st := newTestClient(t)
+	ctx := context.Background()
+
+	m := &Manifest{Tasks: []TaskSpec{{Name: "report", CronExpression: "0 0 6 * * *"}}}
+	plan, err := c.Apply(ctx, m, ApplyOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.True(t, plan.DryRun)
+	require.Len(t, plan.Changes, 1)
+	assert.Equal(t, ApplyActionCreate, plan.Changes[0].Action)
+
+	_, err = c.Apply(ctx, m, ApplyOptions{})
+	require.NoError(t, err)
+	var task models.Task
+	require.NoError(t, st.DB().Where("name = ?", "report").First(&task).Error)
+
+	m.Tasks[0].Dependencies = []DependencySpec{{Task: "missing"}}
+	_, err = c.Apply(ctx, m, ApplyOptions{})
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+// 客户端的资源类型独立定义，需要能完整解码服务端返回的 JSON
+func TestResourceTypesMatchServerModels(t *testing.T) {
+	now := time.Now().UTC()
+	id := "id"
+	percent := 50.0
+	for name, tc := range map[string]struct {
+		server interface{}
+		client interface{}
+	}{
+		"task": {
+			models.Task{ID: id, RetryPolicy: &models.RetryPolicy{RetryOn: []models.ExecutionStatus{models.ExecutionStatusFailed}}, NextRunTime: &now,
+				TaskExecutors: []models.TaskExecutor{{ID: id, Executor: &models.Executor{ID: id}}}},
+			&Task{},
+		},
+		"execution": {
+			models.TaskExecution{ID: id, StartTime: &now, ClaimedBy: &id, ProgressPercent: &percent, LogsRotated: true,
+				RerunOfExecutionID: &id, BackfillID: &id, RenderedParameters: models.JSONMap{"a": "b"}},
+			&TaskExecution{},
+		},
+		"log":        {models.ExecutionLogChunk{ID: 1, Seq: 2}, &ExecutionLogChunk{}},
+		"executor":   {models.Executor{ID: id, CallbackSecret: "secret"}, &Executor{}},
+		"dependency": {models.TaskDependency{ID: id, DependsOnTask: &models.Task{ID: id}}, &TaskDependency{}},
+		"run":        {models.WorkflowRun{ID: id, EndTime: &now}, &WorkflowRun{}},
+		"backfill":   {models.Backfill{ID: id, NextTime: &now, Parameters: models.JSONMap{"a": "b"}}, &Backfill{}},
+		"instance":   {models.SchedulerInstance{ID: id, IsLeader: true}, &SchedulerInstance{}},
+		"lease":      {models.SchedulerLease{Name: id, FencingToken: 3}, &SchedulerLease{}},
+	} {
+		data, err := json.Marshal(tc.server)
+		require.NoError(t, err, name)
+		require.NoError(t, json.Unmarshal(data, tc.client), name)
+		roundTrip, err := json.Marshal(tc.client)
+		require.NoError(t, err, name)
+		assert.JSONEq(t, string(data), string(roundTrip), name)
+	}
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// 按状态码归类的错误，用 errors.Is 判断
+var (
+	ErrBadRequest      = errors.New("bad request")
+	ErrUnauthorized    = errors.New("unauthorized")
+	ErrNotFound        = errors.New("not found")
+	ErrConflict        = errors.New("conflict")
+	ErrTooLarge        = errors.New("request entity too large")
+	ErrServer          = errors.New("server error")
+	ErrExecutorFailure = errors.New("executor failure")
+	ErrUnavailable     = errors.New("service unavailable")
+)
+
+// APIError 服务端返回的错误响应
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	// Code 中间件返回的错误码，如 NOT_FOUND、SERVICE_UNAVAILABLE，处理函数的错误没有错误码
+	Code string
+	// Message 响应体中的 error 或 message 字段，响应不是 JSON 时为原始内容
+	Message string
+	// Fields 参数不满足任务参数 schema 时的逐字段错误
+	Fields []FieldError
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s: %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Is 使 errors.Is(err, ErrNotFound) 等按状态码匹配
+func (e *APIError) Is(target error) bool {
+	switch e.StatusCode {
+	case http.StatusBadRequest:
+		return target == ErrBadRequest
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return target == ErrUnauthorized
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusConflict:
+		return target == ErrConflict
+	case http.StatusRequestEntityTooLarge:
+		return target == ErrTooLarge
+	case http.StatusBadGateway:
+		// 停止执行时执行器拒绝或不可达
+		return target == ErrExecutorFailure
+	case http.StatusServiceUnavailable:
+		return target == ErrUnavailable
+	}
+	return e.StatusCode >= 500 && target == ErrServer
+}
+
+func newAPIError(method, path string, resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	// 处理函数返回 {"error": ...}，中间件返回 {"code", "message", "details"}
+	var body struct {
+		Error   string       `json:"error"`
+		Code    string       `json:"code"`
+		Message string       `json:"message"`
+		Details string       `json:"details"`
+		Fields  []FieldError `json:"fields"`
+	}
+	apiErr := &APIError{
+		Method:     method,
+		Path:       path,
+		StatusCode: resp.StatusCode,
+		Message:    string(data),
+	}
+	if err := json.Unmarshal(data, &body); err == nil {
+		switch {
+		case body.Error != "":
+			apiErr.Message = body.Error
+			apiErr.Fields = body.Fields
+		case body.Message != "":
+			apiErr.Code = body.Code
+			apiErr.Message = body.Message
+			if body.Details != "" {
+				apiErr.Message += ": " + body.Details
+			}
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,223 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ListExecutions 获取一页执行历史，按计划时间倒序
+func (c *Client) ListExecutions(ctx context.Context, filter ExecutionFilter) (*ExecutionPage, error) {
+	query := url.Values{}
+	if filter.TaskID != "" {
+		query.Set("task_id", filter.TaskID)
+	}
+	if filter.Status != "" {
+		query.Set("status", string(filter.Status))
+	}
+	if filter.WorkflowRunID != "" {
+		query.Set("workflow_run_id", filter.WorkflowRunID)
+	}
+	if filter.BackfillID != "" {
+		query.Set("backfill_id", filter.BackfillID)
+	}
+	if filter.StartTime != nil {
+		query.Set("start_time", formatTime(filter.StartTime))
+	}
+	if filter.EndTime != nil {
+		query.Set("end_time", formatTime(filter.EndTime))
+	}
+	setPage(query, filter.Page, filter.PageSize)
+
+	var page ExecutionPage
+	if err := c.do(ctx, http.MethodGet, "/executions", query, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Executions 从 filter.Page 开始逐页遍历执行历史，出错时产出错误并结束。
+// 遍历期间新产生的执行会使后续页整体后移，可能重复返回少量记录
+func (c *Client) Executions(ctx context.Context, filter ExecutionFilter) iter.Seq2[TaskExecution, error] {
+	return func(yield func(TaskExecution, error) bool) {
+		if filter.Page <= 0 {
+			filter.Page = 1
+		}
+		for {
+			page, err := c.ListExecutions(ctx, filter)
+			if err != nil {
+				yield(TaskExecution{}, err)
+				return
+			}
+			for _, execution := range page.Data {
+				if !yield(execution, nil) {
+					return
+				}
+			}
+			if page.Page >= page.TotalPages || len(page.Data) == 0 {
+				return
+			}
+			filter.Page = page.Page + 1
+		}
+	}
+}
+
+// ExecutionStats 获取执行统计
+func (c *Client) ExecutionStats(ctx context.Context, filter ExecutionStatsFilter) (*ExecutionStats, error) {
+	query := url.Values{}
+	if filter.TaskID != "" {
+		query.Set("task_id", filter.TaskID)
+	}
+	if filter.StartTime != nil {
+		query.Set("start_time", formatTime(filter.StartTime))
+	}
+	if filter.EndTime != nil {
+		query.Set("end_time", formatTime(filter.EndTime))
+	}
+
+	var stats ExecutionStats
+	if err := c.do(ctx, http.MethodGet, "/executions/stats", query, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetExecution 获取执行详情
+func (c *Client) GetExecution(ctx context.Context, id string) (*TaskExecution, error) {
+	var execution TaskExecution
+	if err := c.do(ctx, http.MethodGet, "/executions/"+escape(id), nil, nil, &execution); err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// StopExecution 停止执行。执行器拒绝停止时返回 ErrExecutorFailure，执行已结束时返回 ErrConflict
+func (c *Client) StopExecution(ctx context.Context, id string) (*StopExecutionResponse, error) {
+	var resp StopExecutionResponse
+	if err := c.do(ctx, http.MethodPost, "/executions/"+escape(id)+"/stop", nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RerunExecution 重跑已结束的执行。parameters 为 nil 时沿用原执行的参数，否则以其作为完整参数替换；
+// 执行尚未结束时返回 ErrConflict
+func (c *Client) RerunExecution(ctx context.Context, id string, parameters map[string]interface{}) (*TaskExecution, error) {
+	var body interface{}
+	if parameters != nil {
+		body = map[string]interface{}{"parameters": parameters}
+	}
+	var execution TaskExecution
+	if err := c.do(ctx, http.MethodPost, "/executions/"+escape(id)+"/rerun", nil, body, &execution); err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// ExecutionLogs 获取 after 之后的一页执行日志，after 为上次收到的最后一个日志块ID
+func (c *Client) ExecutionLogs(ctx context.Context, id string, after uint64) (*ExecutionLogPage, error) {
+	query := url.Values{}
+	if after > 0 {
+		query.Set("after", strconv.FormatUint(after, 10))
+	}
+	var page ExecutionLogPage
+	if err := c.do(ctx, http.MethodGet, "/executions/"+escape(id)+"/logs", query, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// FollowExecutionLogs 持续接收 after 之后的日志块直到执行结束，返回执行的终态。
+// 连接中断时返回错误，调用方可以最后收到的日志块ID作为 after 重新跟踪
+func (c *Client) FollowExecutionLogs(ctx context.Context, id string, after uint64, fn func(ExecutionLogChunk) error) (ExecutionStatus, error) {
+	path := "/executions/" + escape(id) + "/logs"
+	query := url.Values{"follow": {"true"}}
+	req, err := c.newRequest(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if after > 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatUint(after, 10))
+	}
+
+	// 跟踪可能持续很久，不使用客户端的整体超时
+	stream := *c.http
+	stream.Timeout = 0
+	resp, err := stream.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("GET %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", newAPIError(http.MethodGet, path, resp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var event, data string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		case line == "":
+			status, done, err := dispatchLogEvent(event, data, fn)
+			if err != nil || done {
+				return status, err
+			}
+			event, data = "", ""
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("GET %s: %w", path, err)
+	}
+	return "", fmt.Errorf("GET %s: stream closed before the execution finished", path)
+}
+
+// dispatchLogEvent 处理一个 SSE 事件，end 事件返回执行终态
+func dispatchLogEvent(event, data string, fn func(ExecutionLogChunk) error) (ExecutionStatus, bool, error) {
+	switch event {
+	case "log":
+		var chunk ExecutionLogChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", true, fmt.Errorf("failed to decode log event: %w", err)
+		}
+		if err := fn(chunk); err != nil {
+			return "", true, err
+		}
+	case "end":
+		var end struct {
+			Status ExecutionStatus `json:"status"`
+		}
+		if err := json.Unmarshal([]byte(data), &end); err != nil {
+			return "", true, fmt.Errorf("failed to decode end event: %w", err)
+		}
+		return end.Status, true, nil
+	case "error":
+		var body struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal([]byte(data), &body)
+		return "", true, fmt.Errorf("log stream error: %s", body.Error)
+	}
+	return "", false, nil
+}
+
+// setPage 设置分页参数，零值使用服务端默认值
+func setPage(query url.Values, page, pageSize int) {
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+	if pageSize > 0 {
+		query.Set("page_size", strconv.Itoa(pageSize))
+	}
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ListExecutors 获取执行器列表，includeTasks 为 true 时同时返回关联的任务
+func (c *Client) ListExecutors(ctx context.Context, includeTasks bool) ([]Executor, error) {
+	query := url.Values{}
+	if includeTasks {
+		query.Set("include_tasks", "true")
+	}
+	var executors []Executor
+	if err := c.do(ctx, http.MethodGet, "/executors", query, nil, &executors); err != nil {
+		return nil, err
+	}
+	return executors, nil
+}
+
+// GetExecutor 获取执行器详情
+func (c *Client) GetExecutor(ctx context.Context, id string) (*Executor, error) {
+	var executor Executor
+	if err := c.do(ctx, http.MethodGet, "/executors/"+escape(id), nil, nil, &executor); err != nil {
+		return nil, err
+	}
+	return &executor, nil
+}
+
+// RegisterExecutor 注册执行器，每次注册都会签发新的回调密钥
+// 已注册过的 instance_id 需在 Config.Header 中携带 X-Registration-Token，否则返回 401
+func (c *Client) RegisterExecutor(ctx context.Context, req RegisterExecutorRequest) (*RegisterExecutorResponse, error) {
+	var resp RegisterExecutorResponse
+	if err := c.do(ctx, http.MethodPost, "/executors/register", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// UpdateExecutor 更新执行器信息
+func (c *Client) UpdateExecutor(ctx context.Context, id string, req UpdateExecutorRequest) (*Executor, error) {
+	var executor Executor
+	if err := c.do(ctx, http.MethodPut, "/executors/"+escape(id), nil, req, &executor); err != nil {
+		return nil, err
+	}
+	return &executor, nil
+}
+
+// UpdateExecutorStatus 更新执行器状态，例如在维护前置为 maintenance
+func (c *Client) UpdateExecutorStatus(ctx context.Context, id string, status ExecutorStatus, reason string) error {
+	body := map[string]interface{}{"status": status, "reason": reason}
+	return c.do(ctx, http.MethodPut, "/executors/"+escape(id)+"/status", nil, body, nil)
+}
+
+// DeleteExecutor 删除执行器及其任务分配
+func (c *Client) DeleteExecutor(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/executors/"+escape(id), nil, nil, nil)
+}
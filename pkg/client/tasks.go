@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ListTasks 获取任务列表，status 为空时返回全部状态
+func (c *Client) ListTasks(ctx context.Context, status TaskStatus) ([]Task, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", string(status))
+	}
+	var tasks []Task
+	if err := c.do(ctx, http.MethodGet, "/tasks", query, nil, &tasks); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// GetTask 获取任务详情
+func (c *Client) GetTask(ctx context.Context, id string) (*Task, error) {
+	var task Task
+	if err := c.do(ctx, http.MethodGet, "/tasks/"+escape(id), nil, nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// CreateTask 创建任务
+func (c *Client) CreateTask(ctx context.Context, req CreateTaskRequest) (*Task, error) {
+	var task Task
+	if err := c.do(ctx, http.MethodPost, "/tasks", nil, req, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// UpdateTask 更新任务
+func (c *Client) UpdateTask(ctx context.Context, id string, req UpdateTaskRequest) (*Task, error) {
+	var task Task
+	if err := c.do(ctx, http.MethodPut, "/tasks/"+escape(id), nil, req, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// DeleteTask 删除任务（软删除）
+func (c *Client) DeleteTask(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/tasks/"+escape(id), nil, nil, nil)
+}
+
+// TriggerTask 手动触发任务，parameters 覆盖任务的默认参数
+func (c *Client) TriggerTask(ctx context.Context, id string, parameters map[string]interface{}) (*TaskExecution, error) {
+	body := map[string]interface{}{"parameters": parameters}
+	var execution TaskExecution
+	if err := c.do(ctx, http.MethodPost, "/tasks/"+escape(id)+"/trigger", nil, body, &execution); err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+// PauseTask 暂停任务调度，已暂停时返回 ErrBadRequest
+func (c *Client) PauseTask(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/tasks/"+escape(id)+"/pause", nil, nil, nil)
+}
+
+// ResumeTask 恢复任务调度，已活跃时返回 ErrBadRequest
+func (c *Client) ResumeTask(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodPost, "/tasks/"+escape(id)+"/resume", nil, nil, nil)
+}
+
+// TaskStats 获取任务统计
+func (c *Client) TaskStats(ctx context.Context, id string) (*TaskStats, error) {
+	var stats TaskStats
+	if err := c.do(ctx, http.MethodGet, "/tasks/"+escape(id)+"/stats", nil, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// TaskExecutors 获取任务分配的执行器
+func (c *Client) TaskExecutors(ctx context.Context, id string) ([]TaskExecutor, error) {
+	var assignments []TaskExecutor
+	if err := c.do(ctx, http.MethodGet, "/tasks/"+escape(id)+"/executors", nil, nil, &assignments); err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// AssignExecutor 为任务分配执行器
+func (c *Client) AssignExecutor(ctx context.Context, taskID string, req AssignExecutorRequest) (*TaskExecutor, error) {
+	var assignment TaskExecutor
+	if err := c.do(ctx, http.MethodPost, "/tasks/"+escape(taskID)+"/executors", nil, req, &assignment); err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// UpdateExecutorAssignment 更新执行器分配的优先级和权重
+func (c *Client) UpdateExecutorAssignment(ctx context.Context, taskID, executorID string, req UpdateExecutorAssignmentRequest) (*TaskExecutor, error) {
+	var assignment TaskExecutor
+	path := "/tasks/" + escape(taskID) + "/executors/" + escape(executorID)
+	if err := c.do(ctx, http.MethodPut, path, nil, req, &assignment); err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// UnassignExecutor 取消执行器分配
+func (c *Client) UnassignExecutor(ctx context.Context, taskID, executorID string) error {
+	return c.do(ctx, http.MethodDelete, "/tasks/"+escape(taskID)+"/executors/"+escape(executorID), nil, nil, nil)
+}
+
+// TaskDependencies 获取任务的上下游依赖
+func (c *Client) TaskDependencies(ctx context.Context, id string) (*TaskDependencies, error) {
+	var deps TaskDependencies
+	if err := c.do(ctx, http.MethodGet, "/tasks/"+escape(id)+"/dependencies", nil, nil, &deps); err != nil {
+		return nil, err
+	}
+	return &deps, nil
+}
+
+// AddTaskDependency 新增上游依赖，形成环或上游不存在时返回 ErrBadRequest
+func (c *Client) AddTaskDependency(ctx context.Context, taskID string, req DependencyRequest) (*TaskDependency, error) {
+	var dep TaskDependency
+	if err := c.do(ctx, http.MethodPost, "/tasks/"+escape(taskID)+"/dependencies", nil, req, &dep); err != nil {
+		return nil, err
+	}
+	return &dep, nil
+}
+
+// SetTaskDependencies 替换全部上游依赖，deps 为空时清空
+func (c *Client) SetTaskDependencies(ctx context.Context, taskID string, deps []DependencyRequest) ([]TaskDependency, error) {
+	if deps == nil {
+		deps = []DependencyRequest{}
+	}
+	body := map[string]interface{}{"dependencies": deps}
+	var result []TaskDependency
+	if err := c.do(ctx, http.MethodPut, "/tasks/"+escape(taskID)+"/dependencies", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RemoveTaskDependency 删除上游依赖
+func (c *Client) RemoveTaskDependency(ctx context.Context, taskID, upstreamID string) error {
+	return c.do(ctx, http.MethodDelete, "/tasks/"+escape(taskID)+"/dependencies/"+escape(upstreamID), nil, nil, nil)
+}
@@ -0,0 +1,582 @@
+package client
+
+import (
+	"time"
+
+	"github.com/jobs/scheduler/pkg/jsonschema"
+)
+
+// 以下资源与枚举按接口的 JSON 格式定义，不依赖服务端的模型和存储实现
+
+type ExecutionMode string
+
+type LoadBalanceStrategy string
+
+type TaskStatus string
+
+// MisfirePolicy 调度器停机或切换领导者期间错过的触发如何补偿
+type MisfirePolicy string
+
+// RetryBackoff 重试间隔的增长方式
+type RetryBackoff string
+
+type ExecutionStatus string
+
+type ExecutorStatus string
+
+type WorkflowRunStatus string
+
+type BackfillStatus string
+
+// DependencyFailurePolicy 上游未成功时对下游的处理策略
+type DependencyFailurePolicy string
+
+// RetryPolicy 任务的重试策略，重试次数仍由 MaxRetry 限制
+type RetryPolicy struct {
+	Backoff             RetryBackoff      `json:"backoff,omitempty"`               // 为空表示 exponential
+	InitialDelaySeconds int               `json:"initial_delay_seconds,omitempty"` // 首次重试前的等待，0表示1秒
+	MaxDelaySeconds     int               `json:"max_delay_seconds,omitempty"`     // 等待上限，0表示30秒
+	RetryOn             []ExecutionStatus `json:"retry_on,omitempty"`              // 需要重试的上报终态，可选 failed、timeout
+	SwitchExecutor      bool              `json:"switch_executor,omitempty"`       // 重试时优先选择未失败过的执行器
+}
+
+// Task 任务
+type Task struct {
+	ID                  string                 `json:"id"`
+	Name                string                 `json:"name"`
+	CronExpression      string                 `json:"cron_expression"`
+	Timezone            string                 `json:"timezone"` // 为空表示服务器本地时区
+	Parameters          map[string]interface{} `json:"parameters"`
+	ParametersSchema    map[string]interface{} `json:"parameters_schema,omitempty"`
+	ExecutionMode       ExecutionMode          `json:"execution_mode"`
+	LoadBalanceStrategy LoadBalanceStrategy    `json:"load_balance_strategy"`
+	MaxRetry            int                    `json:"max_retry"`
+	RetryPolicy         *RetryPolicy           `json:"retry_policy,omitempty"`
+	TimeoutSeconds      int                    `json:"timeout_seconds"`
+	Status              TaskStatus             `json:"status"`
+	MisfirePolicy       MisfirePolicy          `json:"misfire_policy"`
+	MisfireLimit        int                    `json:"misfire_limit"`
+	LastScheduledTime   *time.Time             `json:"last_scheduled_time"`
+	NextRunTime         *time.Time             `json:"next_run_time,omitempty"`
+	CreatedAt           time.Time              `json:"created_at"`
+	UpdatedAt           time.Time              `json:"updated_at"`
+
+	TaskExecutors []TaskExecutor  `json:"task_executors,omitempty"`
+	Executions    []TaskExecution `json:"executions,omitempty"`
+}
+
+// TaskExecutor 任务的执行器分配
+type TaskExecutor struct {
+	ID         string    `json:"id"`
+	TaskID     string    `json:"task_id"`
+	ExecutorID string    `json:"executor_id"`
+	Priority   int       `json:"priority"`
+	Weight     int       `json:"weight"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	Task     *Task     `json:"task,omitempty"`
+	Executor *Executor `json:"executor,omitempty"`
+}
+
+// TaskDependency 任务依赖关系（TaskID 依赖 DependsOnTaskID）
+type TaskDependency struct {
+	ID              string                  `json:"id"`
+	TaskID          string                  `json:"task_id"`
+	DependsOnTaskID string                  `json:"depends_on_task_id"`
+	FailurePolicy   DependencyFailurePolicy `json:"failure_policy"`
+	CreatedAt       time.Time               `json:"created_at"`
+
+	Task          *Task `json:"task,omitempty"`
+	DependsOnTask *Task `json:"depends_on_task,omitempty"`
+}
+
+// TaskExecution 任务的一次执行
+type TaskExecution struct {
+	ID                 string                 `json:"id"`
+	TaskID             string                 `json:"task_id"`
+	ExecutorID         *string                `json:"executor_id"`
+	ScheduledTime      time.Time              `json:"scheduled_time"`
+	StartTime          *time.Time             `json:"start_time"`
+	EndTime            *time.Time             `json:"end_time"`
+	Status             ExecutionStatus        `json:"status"`
+	Result             map[string]interface{} `json:"result"`
+	Logs               string                 `json:"logs"`
+	RetryCount         int                    `json:"retry_count"`
+	CreatedAt          time.Time              `json:"created_at"`
+	Version            int64                  `json:"version"`
+	FencingToken       int64                  `json:"fencing_token"`
+	WorkflowRunID      *string                `json:"workflow_run_id"`
+	ParentExecutionID  *string                `json:"parent_execution_id"`
+	Parameters         map[string]interface{} `json:"parameters"`
+	RenderedParameters map[string]interface{} `json:"rendered_parameters,omitempty"` // 最近一次分发时实际下发的参数
+	RerunOfExecutionID *string                `json:"rerun_of_execution_id,omitempty"`
+	BackfillID         *string                `json:"backfill_id,omitempty"`
+	ClaimedBy          *string                `json:"claimed_by,omitempty"`
+	ClaimedUntil       *time.Time             `json:"claimed_until,omitempty"`
+	ProgressPercent    *float64               `json:"progress_percent,omitempty"`
+	ProgressStage      string                 `json:"progress_stage,omitempty"`
+	ProgressCounters   map[string]interface{} `json:"progress_counters,omitempty"`
+	HeartbeatAt        *time.Time             `json:"heartbeat_at,omitempty"`
+	LogsRotated        bool                   `json:"logs_rotated,omitempty"`
+
+	Task     *Task     `json:"task,omitempty"`
+	Executor *Executor `json:"executor,omitempty"`
+}
+
+// ExecutionLogChunk 执行器在运行期间追加的日志块，按ID排序即为写入顺序
+type ExecutionLogChunk struct {
+	ID          uint64    `json:"id"`
+	ExecutionID string    `json:"execution_id"`
+	Attempt     int       `json:"attempt"`
+	Seq         int64     `json:"seq"`
+	Content     string    `json:"content"`
+	Size        int       `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Executor 执行器
+type Executor struct {
+	ID                  string                 `json:"id"`
+	Name                string                 `json:"name"`
+	InstanceID          string                 `json:"instance_id"`
+	BaseURL             string                 `json:"base_url"`
+	HealthCheckURL      string                 `json:"health_check_url"`
+	Status              ExecutorStatus         `json:"status"`
+	IsHealthy           bool                   `json:"is_healthy"`
+	LastHealthCheck     *time.Time             `json:"last_health_check"`
+	HealthCheckFailures int                    `json:"health_check_failures"`
+	Metadata            map[string]interface{} `json:"metadata"`
+	CreatedAt           time.Time              `json:"created_at"`
+	UpdatedAt           time.Time              `json:"updated_at"`
+
+	TaskExecutors []TaskExecutor `json:"task_executors,omitempty"`
+}
+
+// WorkflowRun 工作流运行，聚合一次根触发沿依赖链产生的所有执行
+type WorkflowRun struct {
+	ID              string            `json:"id"`
+	RootTaskID      string            `json:"root_task_id"`
+	RootExecutionID string            `json:"root_execution_id"`
+	Status          WorkflowRunStatus `json:"status"`
+	StartTime       time.Time         `json:"start_time"`
+	EndTime         *time.Time        `json:"end_time"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+
+	RootTask   *Task           `json:"root_task,omitempty"`
+	Executions []TaskExecution `json:"executions,omitempty"`
+}
+
+// Backfill 历史区间补跑
+type Backfill struct {
+	ID             string                 `json:"id"`
+	TaskID         string                 `json:"task_id"`
+	StartTime      time.Time              `json:"start_time"`
+	EndTime        time.Time              `json:"end_time"`
+	CronExpression string                 `json:"cron_expression"`
+	Timezone       string                 `json:"timezone"`
+	Parameters     map[string]interface{} `json:"parameters,omitempty"`
+	MaxConcurrency int                    `json:"max_concurrency"`
+	Status         BackfillStatus         `json:"status"`
+	Total          int                    `json:"total"`
+	Created        int                    `json:"created"`
+	NextTime       *time.Time             `json:"next_time"` // 下一个待创建的触发时间，全部创建后为空
+	FinishedAt     *time.Time             `json:"finished_at"`
+	CreatedAt      time.Time              `json:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at"`
+
+	Task       *Task           `json:"task,omitempty"`
+	Executions []TaskExecution `json:"executions,omitempty"`
+}
+
+// SchedulerInstance 调度器实例
+type SchedulerInstance struct {
+	ID         string    `json:"id"`
+	InstanceID string    `json:"instance_id"`
+	Host       string    `json:"host"`
+	Port       int       `json:"port"`
+	IsLeader   bool      `json:"is_leader"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// SchedulerLease 领导者租约，FencingToken 每次易主时递增
+type SchedulerLease struct {
+	Name         string    `json:"name"`
+	Holder       string    `json:"holder"`
+	FencingToken int64     `json:"fencing_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Manifest 声明式任务清单，任务按名称匹配，依赖以上游任务名称引用
+type Manifest struct {
+	Tasks []TaskSpec `json:"tasks"`
+}
+
+// TaskSpec 任务的期望状态，零值字段使用与创建任务接口相同的默认值
+type TaskSpec struct {
+	Name                string                 `json:"name"`
+	CronExpression      string                 `json:"cron_expression"`
+	Timezone            string                 `json:"timezone,omitempty"`
+	Parameters          map[string]interface{} `json:"parameters,omitempty"`
+	ExecutionMode       ExecutionMode          `json:"execution_mode,omitempty"`
+	LoadBalanceStrategy LoadBalanceStrategy    `json:"load_balance_strategy,omitempty"`
+	MaxRetry            *int                   `json:"max_retry,omitempty"` // nil表示默认3次
+	RetryPolicy         *RetryPolicy           `json:"retry_policy,omitempty"`
+	TimeoutSeconds      int                    `json:"timeout_seconds,omitempty"`
+	MisfirePolicy       MisfirePolicy          `json:"misfire_policy,omitempty"`
+	MisfireLimit        int                    `json:"misfire_limit,omitempty"`
+	Status              TaskStatus             `json:"status,omitempty"` // active 或 paused，默认 active
+	Dependencies        []DependencySpec       `json:"dependencies,omitempty"`
+	// Executors nil表示不管理执行器分配（保留执行器注册时创建的分配），空数组表示取消全部分配
+	Executors []ExecutorSpec `json:"executors"`
+}
+
+// DependencySpec 按名称引用的上游依赖
+type DependencySpec struct {
+	Task          string                  `json:"task"`
+	FailurePolicy DependencyFailurePolicy `json:"failure_policy,omitempty"`
+}
+
+// ExecutorSpec 任务的执行器分配
+type ExecutorSpec struct {
+	ID       string `json:"id"`
+	Priority int    `json:"priority,omitempty"`
+	Weight   int    `json:"weight,omitempty"`
+}
+
+// ApplyAction 任务的变更类型
+type ApplyAction string
+
+// FieldChange 字段的当前值和期望值
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// ApplyChange 单个任务的变更
+type ApplyChange struct {
+	Action ApplyAction   `json:"action"`
+	Task   string        `json:"task"`
+	TaskID string        `json:"task_id"`
+	Fields []FieldChange `json:"fields,omitempty"` // 仅 update
+}
+
+// ApplyPlan 清单与调度器中任务的差异，DryRun 为 false 时表示已应用的变更
+type ApplyPlan struct {
+	DryRun    bool          `json:"dry_run"`
+	Changes   []ApplyChange `json:"changes"`
+	Unchanged []string      `json:"unchanged"`
+}
+
+// FieldError 参数不满足任务参数 schema 时的逐字段错误
+type FieldError = jsonschema.FieldError
+
+// CreateTaskRequest 创建任务请求
+type CreateTaskRequest struct {
+	Name                string                 `json:"name"`
+	CronExpression      string                 `json:"cron_expression"`
+	Timezone            string                 `json:"timezone,omitempty"`
+	Parameters          map[string]interface{} `json:"parameters,omitempty"`
+	ParametersSchema    map[string]interface{} `json:"parameters_schema,omitempty"`
+	ExecutionMode       ExecutionMode          `json:"execution_mode,omitempty"`
+	LoadBalanceStrategy LoadBalanceStrategy    `json:"load_balance_strategy,omitempty"`
+	MaxRetry            int                    `json:"max_retry,omitempty"`
+	RetryPolicy         *RetryPolicy           `json:"retry_policy,omitempty"`
+	TimeoutSeconds      int                    `json:"timeout_seconds,omitempty"`
+	MisfirePolicy       MisfirePolicy          `json:"misfire_policy,omitempty"`
+	MisfireLimit        int                    `json:"misfire_limit,omitempty"`
+	Dependencies        []DependencyRequest    `json:"dependencies,omitempty"`
+}
+
+// UpdateTaskRequest 更新任务请求，零值字段不修改
+type UpdateTaskRequest struct {
+	Name                string                 `json:"name,omitempty"`
+	CronExpression      string                 `json:"cron_expression,omitempty"`
+	Timezone            *string                `json:"timezone,omitempty"` // nil表示不修改，空字符串表示服务器本地时区
+	Parameters          map[string]interface{} `json:"parameters,omitempty"`
+	ParametersSchema    map[string]interface{} `json:"parameters_schema"` // nil表示不修改，空map表示取消校验
+	ExecutionMode       ExecutionMode          `json:"execution_mode,omitempty"`
+	LoadBalanceStrategy LoadBalanceStrategy    `json:"load_balance_strategy,omitempty"`
+	MaxRetry            int                    `json:"max_retry,omitempty"`
+	RetryPolicy         *RetryPolicy           `json:"retry_policy,omitempty"` // nil表示不修改，空对象表示恢复默认策略
+	TimeoutSeconds      int                    `json:"timeout_seconds,omitempty"`
+	Status              TaskStatus             `json:"status,omitempty"`
+	MisfirePolicy       MisfirePolicy          `json:"misfire_policy,omitempty"`
+	MisfireLimit        *int                   `json:"misfire_limit,omitempty"` // nil表示不修改，0表示使用默认上限
+	Dependencies        []DependencyRequest    `json:"dependencies,omitempty"`  // nil表示不修改，使用 SetTaskDependencies 清空
+}
+
+// DependencyRequest 任务依赖请求
+type DependencyRequest struct {
+	DependsOnTaskID string                  `json:"depends_on_task_id"`
+	FailurePolicy   DependencyFailurePolicy `json:"failure_policy,omitempty"`
+}
+
+// TaskDependencies 任务的上下游依赖
+type TaskDependencies struct {
+	Upstream   []TaskDependency `json:"upstream"`
+	Downstream []TaskDependency `json:"downstream"`
+}
+
+// AssignExecutorRequest 分配执行器请求
+type AssignExecutorRequest struct {
+	ExecutorID string `json:"executor_id"`
+	Priority   int    `json:"priority,omitempty"`
+	Weight     int    `json:"weight,omitempty"`
+}
+
+// UpdateExecutorAssignmentRequest 更新执行器分配请求
+type UpdateExecutorAssignmentRequest struct {
+	Priority int `json:"priority"`
+	Weight   int `json:"weight"`
+}
+
+// TaskStats 任务统计
+type TaskStats struct {
+	SuccessRate24h   float64                  `json:"success_rate_24h"`
+	Total24h         int64                    `json:"total_24h"`
+	Success24h       int64                    `json:"success_24h"`
+	Health90d        map[string]interface{}   `json:"health_90d"`
+	RecentExecutions []DailyExecutionStats    `json:"recent_executions"`
+	DailyStats90d    []map[string]interface{} `json:"daily_stats_90d"`
+}
+
+// DailyExecutionStats 单日执行统计
+type DailyExecutionStats struct {
+	Date        string  `json:"date"`
+	Total       int     `json:"total"`
+	Success     int     `json:"success"`
+	Failed      int     `json:"failed"`
+	SuccessRate float64 `json:"success_rate"`
+}
+
+// RegisterExecutorRequest 执行器注册请求，执行器进程应使用 pkg/executor
+type RegisterExecutorRequest struct {
+	ExecutorID     string                 `json:"executor_id"`
+	ExecutorName   string                 `json:"executor_name"`
+	ExecutorURL    string                 `json:"executor_url"`
+	HealthCheckURL string                 `json:"health_check_url,omitempty"`
+	Tasks          []TaskDefinition       `json:"tasks,omitempty"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// TaskDefinition 注册执行器时提交的任务定义
+type TaskDefinition struct {
+	Name                string                 `json:"name"`
+	ExecutionMode       ExecutionMode          `json:"execution_mode"`
+	CronExpression      string                 `json:"cron_expression"`
+	LoadBalanceStrategy LoadBalanceStrategy    `json:"load_balance_strategy"`
+	MaxRetry            int                    `json:"max_retry,omitempty"`
+	TimeoutSeconds      int                    `json:"timeout_seconds,omitempty"`
+	Parameters          map[string]interface{} `json:"parameters,omitempty"`
+	Status              TaskStatus             `json:"status,omitempty"`
+}
+
+// RegisterExecutorResponse 执行器注册响应，CallbackSecret 只在注册时返回
+type RegisterExecutorResponse struct {
+	Executor
+	CallbackSecret string `json:"callback_secret"`
+}
+
+// UpdateExecutorRequest 更新执行器信息请求，空字段不修改
+type UpdateExecutorRequest struct {
+	Name           string `json:"name,omitempty"`
+	BaseURL        string `json:"base_url,omitempty"`
+	HealthCheckURL string `json:"health_check_url,omitempty"`
+}
+
+// ExecutionFilter 执行历史过滤条件，零值字段不过滤
+type ExecutionFilter struct {
+	TaskID        string
+	Status        ExecutionStatus
+	WorkflowRunID string
+	BackfillID    string
+	StartTime     *time.Time // 计划时间下界
+	EndTime       *time.Time // 计划时间上界
+	Page          int
+	PageSize      int // 最大100
+}
+
+// ExecutionPage 执行历史分页
+type ExecutionPage struct {
+	Data       []TaskExecution `json:"data"`
+	Total      int64           `json:"total"`
+	Page       int             `json:"page"`
+	PageSize   int             `json:"page_size"`
+	TotalPages int             `json:"total_pages"`
+}
+
+// ExecutionStatsFilter 执行统计过滤条件
+type ExecutionStatsFilter struct {
+	TaskID    string
+	StartTime *time.Time
+	EndTime   *time.Time
+}
+
+// ExecutionStats 执行统计
+type ExecutionStats struct {
+	Total   int64 `json:"total"`
+	Success int64 `json:"success"`
+	Failed  int64 `json:"failed"`
+	Running int64 `json:"running"`
+	Pending int64 `json:"pending"`
+}
+
+// StopExecutionResponse 停止执行响应
+type StopExecutionResponse struct {
+	Message     string          `json:"message"`
+	ExecutionID string          `json:"execution_id"`
+	Status      ExecutionStatus `json:"status"`
+}
+
+// ExecutionLogPage 执行日志分页，NextAfter 作为下一页的 after
+type ExecutionLogPage struct {
+	ExecutionID string              `json:"execution_id"`
+	Status      ExecutionStatus     `json:"status"`
+	LogsRotated bool                `json:"logs_rotated"`
+	Chunks      []ExecutionLogChunk `json:"chunks"`
+	NextAfter   uint64              `json:"next_after"`
+	HasMore     bool                `json:"has_more"`
+}
+
+// WorkflowRunFilter 工作流运行过滤条件
+type WorkflowRunFilter struct {
+	RootTaskID string
+	Status     WorkflowRunStatus
+	Page       int
+	PageSize   int
+}
+
+// WorkflowRunPage 工作流运行分页
+type WorkflowRunPage struct {
+	Data       []WorkflowRun `json:"data"`
+	Total      int64         `json:"total"`
+	Page       int           `json:"page"`
+	PageSize   int           `json:"page_size"`
+	TotalPages int           `json:"total_pages"`
+}
+
+// RerunWorkflowResponse 从失败节点重跑响应
+type RerunWorkflowResponse struct {
+	WorkflowRunID string          `json:"workflow_run_id"`
+	Executions    []TaskExecution `json:"executions"`
+}
+
+// BackfillRequest 补跑请求，区间为闭区间
+type BackfillRequest struct {
+	StartTime      time.Time              `json:"start_time"`
+	EndTime        time.Time              `json:"end_time"`
+	MaxConcurrency int                    `json:"max_concurrency,omitempty"`
+	Parameters     map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// BackfillFilter 补跑过滤条件
+type BackfillFilter struct {
+	TaskID   string
+	Status   BackfillStatus
+	Page     int
+	PageSize int
+}
+
+// BackfillPage 补跑分页
+type BackfillPage struct {
+	Data       []Backfill `json:"data"`
+	Total      int64      `json:"total"`
+	Page       int        `json:"page"`
+	PageSize   int        `json:"page_size"`
+	TotalPages int        `json:"total_pages"`
+}
+
+// ApplyOptions 应用清单选项
+type ApplyOptions struct {
+	DryRun bool // 只返回差异，不修改
+	Prune  bool // 删除清单之外的任务
+}
+
+// CronPreviewRequest 预览cron触发时间请求
+type CronPreviewRequest struct {
+	Expression string     `json:"expression"`
+	Timezone   string     `json:"timezone,omitempty"`
+	Count      int        `json:"count,omitempty"`
+	From       *time.Time `json:"from,omitempty"`
+}
+
+// CronPreview 预览结果
+type CronPreview struct {
+	Expression string      `json:"expression"`
+	Timezone   string      `json:"timezone"`
+	NextTimes  []time.Time `json:"next_times"`
+}
+
+// SchedulerStatus 调度器集群状态
+type SchedulerStatus struct {
+	Instances []SchedulerInstance `json:"instances"`
+	Leases    []SchedulerLease    `json:"leases"`
+	Time      time.Time           `json:"time"`
+}
+
+// Health 健康检查结果
+type Health struct {
+	Status string    `json:"status"`
+	Time   time.Time `json:"time"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// 枚举值
+const (
+	ExecutionModeSequential ExecutionMode = "sequential"
+	ExecutionModeParallel   ExecutionMode = "parallel"
+	ExecutionModeSkip       ExecutionMode = "skip"
+
+	LoadBalanceRoundRobin         LoadBalanceStrategy = "round_robin"
+	LoadBalanceWeightedRoundRobin LoadBalanceStrategy = "weighted_round_robin"
+	LoadBalanceRandom             LoadBalanceStrategy = "random"
+	LoadBalanceSticky             LoadBalanceStrategy = "sticky"
+	LoadBalanceLeastLoaded        LoadBalanceStrategy = "least_loaded"
+
+	TaskStatusActive  TaskStatus = "active"
+	TaskStatusPaused  TaskStatus = "paused"
+	TaskStatusDeleted TaskStatus = "deleted"
+
+	MisfirePolicyIgnore   MisfirePolicy = "ignore"
+	MisfirePolicyFireOnce MisfirePolicy = "fire_once"
+	MisfirePolicyFireAll  MisfirePolicy = "fire_all"
+
+	ExecutorStatusOnline      ExecutorStatus = "online"
+	ExecutorStatusOffline     ExecutorStatus = "offline"
+	ExecutorStatusMaintenance ExecutorStatus = "maintenance"
+
+	ExecutionStatusPending   ExecutionStatus = "pending"
+	ExecutionStatusRunning   ExecutionStatus = "running"
+	ExecutionStatusSuccess   ExecutionStatus = "success"
+	ExecutionStatusFailed    ExecutionStatus = "failed"
+	ExecutionStatusTimeout   ExecutionStatus = "timeout"
+	ExecutionStatusCancelled ExecutionStatus = "cancelled"
+	ExecutionStatusSkipped   ExecutionStatus = "skipped"
+
+	RetryBackoffFixed       RetryBackoff = "fixed"
+	RetryBackoffExponential RetryBackoff = "exponential"
+	RetryBackoffJittered    RetryBackoff = "jittered"
+
+	DependencyPolicySkip DependencyFailurePolicy = "skip"
+	DependencyPolicyFail DependencyFailurePolicy = "fail"
+	DependencyPolicyRun  DependencyFailurePolicy = "run"
+
+	WorkflowRunStatusRunning         WorkflowRunStatus = "running"
+	WorkflowRunStatusSucceeded       WorkflowRunStatus = "succeeded"
+	WorkflowRunStatusFailed          WorkflowRunStatus = "failed"
+	WorkflowRunStatusPartiallyFailed WorkflowRunStatus = "partially_failed"
+	WorkflowRunStatusCancelled       WorkflowRunStatus = "cancelled"
+
+	BackfillStatusRunning   BackfillStatus = "running"
+	BackfillStatusSucceeded BackfillStatus = "succeeded"
+	BackfillStatusFailed    BackfillStatus = "failed"
+	BackfillStatusCancelled BackfillStatus = "cancelled"
+
+	ApplyActionCreate ApplyAction = "create"
+	ApplyActionUpdate ApplyAction = "update"
+	ApplyActionDelete ApplyAction = "delete"
+)
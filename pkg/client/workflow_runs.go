@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+)
+
+// ListWorkflowRuns 获取一页工作流运行，按开始时间倒序
+func (c *Client) ListWorkflowRuns(ctx context.Context, filter WorkflowRunFilter) (*WorkflowRunPage, error) {
+	query := url.Values{}
+	if filter.RootTaskID != "" {
+		query.Set("root_task_id", filter.RootTaskID)
+	}
+	if filter.Status != "" {
+		query.Set("status", string(filter.Status))
+	}
+	setPage(query, filter.Page, filter.PageSize)
+
+	var page WorkflowRunPage
+	if err := c.do(ctx, http.MethodGet, "/workflow-runs", query, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// WorkflowRuns 从 filter.Page 开始逐页遍历工作流运行，出错时产出错误并结束
+func (c *Client) WorkflowRuns(ctx context.Context, filter WorkflowRunFilter) iter.Seq2[WorkflowRun, error] {
+	return func(yield func(WorkflowRun, error) bool) {
+		if filter.Page <= 0 {
+			filter.Page = 1
+		}
+		for {
+			page, err := c.ListWorkflowRuns(ctx, filter)
+			if err != nil {
+				yield(WorkflowRun{}, err)
+				return
+			}
+			for _, run := range page.Data {
+				if !yield(run, nil) {
+					return
+				}
+			}
+			if page.Page >= page.TotalPages || len(page.Data) == 0 {
+				return
+			}
+			filter.Page = page.Page + 1
+		}
+	}
+}
+
+// GetWorkflowRun 获取工作流运行及其全部执行
+func (c *Client) GetWorkflowRun(ctx context.Context, id string) (*WorkflowRun, error) {
+	var run WorkflowRun
+	if err := c.do(ctx, http.MethodGet, "/workflow-runs/"+escape(id), nil, nil, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// CancelWorkflowRun 取消工作流运行，已结束时返回 ErrConflict
+func (c *Client) CancelWorkflowRun(ctx context.Context, id string) (*WorkflowRun, error) {
+	var run WorkflowRun
+	if err := c.do(ctx, http.MethodPost, "/workflow-runs/"+escape(id)+"/cancel", nil, nil, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// RerunWorkflowRun 从失败节点重跑，taskIDs 为空时重跑所有失败节点
+func (c *Client) RerunWorkflowRun(ctx context.Context, id string, taskIDs []string) (*RerunWorkflowResponse, error) {
+	body := map[string]interface{}{"task_ids": taskIDs}
+	var resp RerunWorkflowResponse
+	if err := c.do(ctx, http.MethodPost, "/workflow-runs/"+escape(id)+"/rerun", nil, body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
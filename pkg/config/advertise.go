@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// defaultAdvertisePort 无法得知 HTTP 服务端口时使用的通告端口
+const defaultAdvertisePort = 8080
+
+// resolveAdvertise 补全并校验通告地址和回调地址
+// 未配置 advertise_addr 时使用本机地址和 HTTP 服务端口
+func (c *SchedulerConfig) resolveAdvertise(serverPort int) error {
+	if c.AdvertiseAddr == "" {
+		if serverPort <= 0 {
+			serverPort = defaultAdvertisePort
+		}
+		c.AdvertiseAddr = net.JoinHostPort(DetectHost(), strconv.Itoa(serverPort))
+	}
+	if _, _, err := c.AdvertiseHostPort(); err != nil {
+		return err
+	}
+
+	if c.CallbackBaseURL != "" {
+		u, err := url.Parse(c.CallbackBaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("scheduler.callback_base_url %q must be an absolute http(s) URL", c.CallbackBaseURL)
+		}
+	}
+	return nil
+}
+
+// AdvertiseHostPort 本实例对外通告的主机和端口，未配置时使用本机地址和默认端口
+func (c SchedulerConfig) AdvertiseHostPort() (string, int, error) {
+	addr := c.AdvertiseAddr
+	if addr == "" {
+		addr = net.JoinHostPort(DetectHost(), strconv.Itoa(defaultAdvertisePort))
+	}
+
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid scheduler.advertise_addr %q: %w", addr, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		return "", 0, fmt.Errorf("invalid scheduler.advertise_addr %q: bad port", addr)
+	}
+	if host == "" {
+		host = DetectHost()
+	}
+	return host, port, nil
+}
+
+// CallbackBase 执行器回调调度器使用的基础地址
+// 多实例部署时应配置为负载均衡地址，任一实例都能处理回调；未配置时直接回调本实例的通告地址
+func (c SchedulerConfig) CallbackBase() string {
+	if c.CallbackBaseURL != "" {
+		return strings.TrimRight(c.CallbackBaseURL, "/")
+	}
+
+	host, port, err := c.AdvertiseHostPort()
+	if err != nil {
+		host, port = DetectHost(), defaultAdvertisePort
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// DetectHost 本机首个非回环的 IPv4 地址，取不到时使用主机名
+func DetectHost() string {
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.IsLoopback() || ipNet.IP.IsLinkLocalUnicast() {
+				continue
+			}
+			if ip := ipNet.IP.To4(); ip != nil {
+				return ip.String()
+			}
+		}
+	}
+
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		return hostname
+	}
+	return "localhost"
+}
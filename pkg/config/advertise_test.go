@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveAdvertise(t *testing.T) {
+	// 未配置时使用本机地址和 HTTP 服务端口，回调直接发往本实例
+	cfg := SchedulerConfig{}
+	require.NoError(t, cfg.resolveAdvertise(9000))
+	host, port, err := cfg.AdvertiseHostPort()
+	require.NoError(t, err)
+	assert.Equal(t, DetectHost(), host)
+	assert.Equal(t, 9000, port)
+	assert.Equal(t, "http://"+cfg.AdvertiseAddr, cfg.CallbackBase())
+
+	// 显式配置优先，回调地址去掉末尾的斜杠
+	cfg = SchedulerConfig{AdvertiseAddr: "10.0.0.5:8081", CallbackBaseURL: "https://scheduler.internal/"}
+	require.NoError(t, cfg.resolveAdvertise(8080))
+	host, port, err = cfg.AdvertiseHostPort()
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.5", host)
+	assert.Equal(t, 8081, port)
+	assert.Equal(t, "https://scheduler.internal", cfg.CallbackBase())
+
+	for _, invalid := range []SchedulerConfig{
+		{AdvertiseAddr: "10.0.0.5"},
+		{AdvertiseAddr: "10.0.0.5:http"},
+		{AdvertiseAddr: "10.0.0.5:8080", CallbackBaseURL: "scheduler.internal:8080"},
+		{AdvertiseAddr: "10.0.0.5:8080", CallbackBaseURL: "ftp://scheduler.internal"},
+	} {
+		assert.Error(t, invalid.resolveAdvertise(8080), "%+v", invalid)
+	}
+}
@@ -16,11 +16,24 @@ type Config struct {
 }
 
 type SchedulerConfig struct {
-	InstanceID        string        `mapstructure:"instance_id"`
-	LockKey           string        `mapstructure:"lock_key"`
-	LockTimeout       time.Duration `mapstructure:"lock_timeout"`
-	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval"`
-	MaxWorkers        int           `mapstructure:"max_workers"`
+	InstanceID         string        `mapstructure:"instance_id"`
+	LockKey            string        `mapstructure:"lock_key"`
+	LockTimeout        time.Duration `mapstructure:"lock_timeout"`
+	HeartbeatInterval  time.Duration `mapstructure:"heartbeat_interval"`
+	MaxWorkers         int           `mapstructure:"max_workers"`
+	LeaderElection     string        `mapstructure:"leader_election"`      // lease（默认）或 lock
+	LeaseTTL           time.Duration `mapstructure:"lease_ttl"`            // 租约有效期，应大于心跳间隔
+	ReconcileInterval  time.Duration `mapstructure:"reconcile_interval"`   // 轮询任务变更的间隔
+	QueuePollInterval  time.Duration `mapstructure:"queue_poll_interval"`  // 轮询待分发执行的间隔
+	VisibilityTimeout  time.Duration `mapstructure:"visibility_timeout"`   // 认领待分发执行的有效期，过期未开始的执行可被其他实例重新认领
+	StatusPollInterval time.Duration `mapstructure:"status_poll_interval"` // 向执行器查询运行中执行状态的间隔，也是开始查询前的最短运行时长
+	LostGracePeriod    time.Duration `mapstructure:"lost_grace_period"`    // 执行器持续报告不存在（或不可达）超过该时长才判定执行丢失
+	AdvertiseAddr      string        `mapstructure:"advertise_addr"`       // 本实例对外通告的 host:port，为空时使用本机IP和 server.port
+	CallbackBaseURL    string        `mapstructure:"callback_base_url"`    // 执行器回调使用的基础地址（如负载均衡地址），为空时回调本实例
+	LogChunkMaxBytes   int           `mapstructure:"log_chunk_max_bytes"`  // 执行器单次追加日志的最大字节数
+	LogMaxBytes        int64         `mapstructure:"log_max_bytes"`        // 单个执行保留的日志总量上限，超过后删除最早的日志块
+	ExecutionRetention time.Duration `mapstructure:"execution_retention"`  // 已结束执行及其日志的保留时长，0表示永久保留
+	RegistrationToken  string        `mapstructure:"registration_token"`   // 已有执行器无法用当前回调密钥签名时，重新注册需携带的令牌
 }
 
 type HealthCheckConfig struct {
@@ -32,6 +45,9 @@ type HealthCheckConfig struct {
 }
 
 type DatabaseConfig struct {
+	Driver                string        `mapstructure:"driver"` // mysql、postgres、sqlite
+	DSN                   string        `mapstructure:"dsn"`    // 完整连接串，设置后忽略host等字段
+	SSLMode               string        `mapstructure:"ssl_mode"`
 	Host                  string        `mapstructure:"host"`
 	Port                  int           `mapstructure:"port"`
 	Database              string        `mapstructure:"database"`
@@ -66,6 +82,16 @@ func Load(configPath string) (*Config, error) {
 	viper.SetDefault("scheduler.lock_timeout", "30s")
 	viper.SetDefault("scheduler.heartbeat_interval", "10s")
 	viper.SetDefault("scheduler.max_workers", 10)
+	viper.SetDefault("scheduler.leader_election", "lease")
+	viper.SetDefault("scheduler.lease_ttl", "30s")
+	viper.SetDefault("scheduler.reconcile_interval", "5s")
+	viper.SetDefault("scheduler.queue_poll_interval", "1s")
+	viper.SetDefault("scheduler.visibility_timeout", "30s")
+	viper.SetDefault("scheduler.status_poll_interval", "30s")
+	viper.SetDefault("scheduler.lost_grace_period", "1m")
+	viper.SetDefault("scheduler.log_chunk_max_bytes", 64*1024)
+	viper.SetDefault("scheduler.log_max_bytes", 10*1024*1024)
+	viper.SetDefault("scheduler.execution_retention", "0s")
 
 	viper.SetDefault("health_check.enabled", true)
 	viper.SetDefault("health_check.interval", "30s")
@@ -73,6 +99,7 @@ func Load(configPath string) (*Config, error) {
 	viper.SetDefault("health_check.failure_threshold", 3)
 	viper.SetDefault("health_check.recovery_threshold", 2)
 
+	viper.SetDefault("database.driver", "mysql")
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 3306)
 	viper.SetDefault("database.max_connections", 20)
@@ -97,5 +124,14 @@ func Load(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if cfg.Scheduler.LeaseTTL <= cfg.Scheduler.HeartbeatInterval {
+		return nil, fmt.Errorf("scheduler.lease_ttl (%s) must be greater than scheduler.heartbeat_interval (%s)",
+			cfg.Scheduler.LeaseTTL, cfg.Scheduler.HeartbeatInterval)
+	}
+
+	if err := cfg.Scheduler.resolveAdvertise(cfg.Server.Port); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
@@ -0,0 +1,184 @@
+// Package cronexpr 统一的 cron 表达式方言，领域校验、调度器和接口预览都使用这里的解析结果。
+//
+// 支持的格式：
+//   - 6 个字段 "秒 分 时 日 月 星期"，或省略秒的 5 个字段（秒为 0）
+//   - 描述符 @yearly、@monthly、@weekly、@daily、@hourly 以及 @every <duration>
+//   - Quartz 风格的日期扩展：日字段 L、L-n、LW、nW，星期字段 xL、x#n（星期取值 0-7 或 SUN-SAT，0 和 7 均为周日）
+//   - CRON_TZ=<zone> 前缀，未显式指定时区时生效
+package cronexpr
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// parser 标准字段由 robfig/cron 解析，Quartz 扩展在此之上实现
+var parser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// LoadLocation 解析时区名称，为空时使用服务器本地时区
+func LoadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+	}
+	return loc, nil
+}
+
+// Validate 校验表达式和时区
+func Validate(expr, timezone string) error {
+	_, err := Parse(expr, timezone)
+	return err
+}
+
+// Parse 解析表达式，按 timezone 的挂钟时间计算触发时间
+// timezone 为空时使用表达式中的 CRON_TZ 前缀，都没有则使用服务器本地时区
+func Parse(expr, timezone string) (cron.Schedule, error) {
+	expr, prefixZone := splitTimezone(strings.TrimSpace(expr))
+	if timezone == "" {
+		timezone = prefixZone
+	}
+
+	loc, err := LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	if expr == "" {
+		return nil, fmt.Errorf("cron expression cannot be empty")
+	}
+
+	if strings.HasPrefix(expr, "@") {
+		schedule, err := parser.Parse(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression: %w", err)
+		}
+		spec, ok := schedule.(*cron.SpecSchedule)
+		if !ok {
+			// @every 为固定间隔，与时区无关
+			return schedule, nil
+		}
+		return zonedSchedule{inner: specSchedule{spec: *spec}, loc: loc}, nil
+	}
+
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("invalid cron expression: expected 5 or 6 fields, found %d: %s", len(fields), expr)
+	}
+
+	inner, err := parseFields(fields)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression: %w", err)
+	}
+
+	return zonedSchedule{inner: inner, loc: loc}, nil
+}
+
+// NextTimes 计算 from 之后的 n 次触发时间，结果位于表达式生效的时区
+func NextTimes(expr, timezone string, from time.Time, n int) ([]time.Time, error) {
+	schedule, err := Parse(expr, timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	if zs, ok := schedule.(zonedSchedule); ok {
+		from = from.In(zs.loc)
+	}
+
+	times := make([]time.Time, 0, n)
+	for t := from; len(times) < n; {
+		t = schedule.Next(t)
+		if t.IsZero() {
+			break
+		}
+		times = append(times, t)
+	}
+	return times, nil
+}
+
+// splitTimezone 拆分 CRON_TZ= 或 TZ= 前缀
+func splitTimezone(expr string) (string, string) {
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if !strings.HasPrefix(expr, prefix) {
+			continue
+		}
+		i := strings.IndexAny(expr, " \t")
+		if i == -1 {
+			return "", expr[len(prefix):]
+		}
+		return strings.TrimSpace(expr[i:]), expr[len(prefix):i]
+	}
+	return expr, ""
+}
+
+// parseFields 解析 6 个字段；日、星期字段包含 Quartz 扩展时由 quartzSchedule 过滤日期
+func parseFields(fields []string) (wallSchedule, error) {
+	dom, dow := fields[3], fields[5]
+
+	domRule, err := parseDomRule(dom)
+	if err != nil {
+		return nil, err
+	}
+	dowRule, err := parseDowRule(dow)
+	if err != nil {
+		return nil, err
+	}
+
+	if domRule == nil && dowRule == nil {
+		spec, err := parser.Parse(strings.Join(fields, " "))
+		if err != nil {
+			return nil, err
+		}
+		return specSchedule{spec: *spec.(*cron.SpecSchedule)}, nil
+	}
+
+	if domRule != nil && dowRule != nil {
+		return nil, fmt.Errorf("day-of-month and day-of-week cannot both use L, W or #")
+	}
+
+	// 另一个日期字段必须不受限，否则与扩展规则的组合语义不明确
+	rule := domRule
+	other := dow
+	if dowRule != nil {
+		rule = dowRule
+		other = dom
+	}
+	if other != "*" && other != "?" {
+		return nil, fmt.Errorf("%q must be combined with * or ? in the other day field", fields[3]+" "+fields[5])
+	}
+
+	base := append([]string(nil), fields...)
+	base[3], base[5] = "*", "*"
+	spec, err := parser.Parse(strings.Join(base, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	return quartzSchedule{base: specSchedule{spec: *spec.(*cron.SpecSchedule)}, day: rule}, nil
+}
+
+// wallSchedule 按给定时区的挂钟时间计算下次触发
+type wallSchedule interface {
+	nextIn(t time.Time, loc *time.Location) time.Time
+}
+
+// specSchedule 标准字段的调度
+type specSchedule struct {
+	spec cron.SpecSchedule
+}
+
+func (s specSchedule) nextIn(t time.Time, loc *time.Location) time.Time {
+	spec := s.spec
+	spec.Location = loc
+	// SpecSchedule 对 time.Local 取 t 自身的时区，因此先转换
+	return spec.Next(t.In(loc))
+}
@@ -0,0 +1,165 @@
+package cronexpr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func utc(year int, month time.Month, day, hour, min int) time.Time {
+	return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+}
+
+func nextUTC(t *testing.T, expr, timezone string, from time.Time, n int) []time.Time {
+	t.Helper()
+	times, err := NextTimes(expr, timezone, from, n)
+	require.NoError(t, err)
+	for i := range times {
+		times[i] = times[i].UTC()
+	}
+	return times
+}
+
+func TestNextTimesTimezone(t *testing.T) {
+	from := utc(2024, 1, 15, 0, 0)
+
+	// 同一表达式在不同时区对应不同的UTC时刻
+	assert.Equal(t, []time.Time{utc(2024, 1, 15, 9, 0)}, nextUTC(t, "0 0 9 * * *", "UTC", from, 1))
+	assert.Equal(t, []time.Time{utc(2024, 1, 15, 14, 0)}, nextUTC(t, "0 0 9 * * *", "America/New_York", from, 1))
+	assert.Equal(t, []time.Time{utc(2024, 1, 15, 1, 0)}, nextUTC(t, "0 0 9 * * *", "Asia/Shanghai", from, 1))
+
+	// 结果位于任务时区
+	times, err := NextTimes("0 0 9 * * *", "Europe/Berlin", from, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 9, times[0].Hour())
+	assert.Equal(t, "Europe/Berlin", times[0].Location().String())
+
+	_, err = NextTimes("0 0 9 * * *", "Mars/Olympus", from, 1)
+	assert.Error(t, err)
+}
+
+func TestNextTimesDSTKeepsWallClock(t *testing.T) {
+	// 纽约 2024-03-10 拨快、2024-11-03 拨回，09:00 始终是当地 09:00
+	assert.Equal(t, []time.Time{
+		utc(2024, 3, 9, 14, 0),  // EST
+		utc(2024, 3, 10, 13, 0), // EDT
+		utc(2024, 3, 11, 13, 0),
+	}, nextUTC(t, "0 0 9 * * *", "America/New_York", utc(2024, 3, 9, 0, 0), 3))
+
+	assert.Equal(t, []time.Time{
+		utc(2024, 11, 2, 13, 0), // EDT
+		utc(2024, 11, 3, 14, 0), // EST
+	}, nextUTC(t, "0 0 9 * * *", "America/New_York", utc(2024, 11, 2, 0, 0), 2))
+}
+
+func TestNextTimesDSTSpringForward(t *testing.T) {
+	// 02:30 在 2024-03-10 不存在，顺延到 03:30 EDT 触发，而不是跳过当天
+	assert.Equal(t, []time.Time{
+		utc(2024, 3, 9, 7, 30),  // 02:30 EST
+		utc(2024, 3, 10, 7, 30), // 03:30 EDT
+		utc(2024, 3, 11, 6, 30), // 02:30 EDT
+	}, nextUTC(t, "0 30 2 * * *", "America/New_York", utc(2024, 3, 9, 0, 0), 3))
+
+	// 每30分钟：被跳过的 02:00、02:30 与 03:00、03:30 重合，各触发一次
+	assert.Equal(t, []time.Time{
+		utc(2024, 3, 10, 6, 30), // 01:30 EST
+		utc(2024, 3, 10, 7, 0),  // 03:00 EDT
+		utc(2024, 3, 10, 7, 30), // 03:30 EDT
+	}, nextUTC(t, "0 */30 * * * *", "America/New_York", utc(2024, 3, 10, 6, 0), 3))
+}
+
+func TestNextTimesDSTFallBack(t *testing.T) {
+	// 01:30 在 2024-11-03 出现两次，只在第一次（EDT）触发
+	assert.Equal(t, []time.Time{
+		utc(2024, 11, 2, 5, 30), // 01:30 EDT
+		utc(2024, 11, 3, 5, 30), // 01:30 EDT
+		utc(2024, 11, 4, 6, 30), // 01:30 EST
+	}, nextUTC(t, "0 30 1 * * *", "America/New_York", utc(2024, 11, 2, 0, 0), 3))
+
+	// 从重复区间内开始计算，当天的 01:30 已经发生过
+	assert.Equal(t, []time.Time{
+		utc(2024, 11, 4, 6, 30),
+	}, nextUTC(t, "0 30 1 * * *", "America/New_York", utc(2024, 11, 3, 6, 15), 1))
+
+	// 每小时：重复的 01:00 EST 不再触发
+	assert.Equal(t, []time.Time{
+		utc(2024, 11, 3, 5, 0), // 01:00 EDT
+		utc(2024, 11, 3, 7, 0), // 02:00 EST
+	}, nextUTC(t, "0 0 * * * *", "America/New_York", utc(2024, 11, 3, 4, 30), 2))
+}
+
+func TestParseEvery(t *testing.T) {
+	schedule, err := Parse("@every 90s", "Asia/Tokyo")
+	require.NoError(t, err)
+
+	from := utc(2024, 3, 10, 6, 59)
+	assert.Equal(t, from.Add(90*time.Second), schedule.Next(from).UTC())
+}
+
+func TestParseDialect(t *testing.T) {
+	from := utc(2024, 1, 15, 0, 0)
+
+	// 5 个字段时秒为 0，与 6 个字段等价
+	assert.Equal(t, nextUTC(t, "0 */5 * * * *", "UTC", from, 3), nextUTC(t, "*/5 * * * *", "UTC", from, 3))
+
+	// 描述符
+	assert.Equal(t, []time.Time{utc(2024, 1, 16, 0, 0)}, nextUTC(t, "@daily", "UTC", from, 1))
+	assert.Equal(t, []time.Time{utc(2024, 1, 15, 15, 0)}, nextUTC(t, "@daily", "Asia/Tokyo", from, 1))
+
+	// CRON_TZ 前缀在未指定时区时生效
+	assert.Equal(t, []time.Time{utc(2024, 1, 15, 14, 0)}, nextUTC(t, "CRON_TZ=America/New_York 0 0 9 * * *", "", from, 1))
+	assert.Equal(t, []time.Time{utc(2024, 1, 15, 9, 0)}, nextUTC(t, "CRON_TZ=America/New_York 0 0 9 * * *", "UTC", from, 1))
+
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"0 0 0 0 * * * *",
+		"0 0 12 L * MON",
+		"0 0 12 L 1#2 *",
+		"0 0 12 32W * *",
+		"0 0 12 ? * 1#6",
+		"0 0 12 ? * XL",
+	} {
+		assert.Error(t, Validate(expr, "UTC"), expr)
+	}
+}
+
+func TestParseQuartzDays(t *testing.T) {
+	from := utc(2024, 1, 15, 0, 0)
+
+	// 每月最后一天
+	assert.Equal(t, []time.Time{
+		utc(2024, 1, 31, 12, 0),
+		utc(2024, 2, 29, 12, 0),
+		utc(2024, 3, 31, 12, 0),
+	}, nextUTC(t, "0 0 12 L * ?", "UTC", from, 3))
+
+	// 每月倒数第 3 天
+	assert.Equal(t, []time.Time{utc(2024, 1, 28, 12, 0), utc(2024, 2, 26, 12, 0)},
+		nextUTC(t, "0 0 12 L-3 * ?", "UTC", from, 2))
+
+	// 每月最后一个工作日：2024-03-31 为周日
+	assert.Equal(t, []time.Time{utc(2024, 1, 31, 12, 0), utc(2024, 2, 29, 12, 0), utc(2024, 3, 29, 12, 0)},
+		nextUTC(t, "0 0 12 LW * ?", "UTC", from, 3))
+
+	// 离 15 号最近的工作日：2024-06-15 为周六，2024-09-15 为周日
+	assert.Equal(t, []time.Time{utc(2024, 6, 14, 12, 0)}, nextUTC(t, "0 0 12 15W 6 ?", "UTC", from, 1))
+	assert.Equal(t, []time.Time{utc(2024, 9, 16, 12, 0)}, nextUTC(t, "0 0 12 15W 9 ?", "UTC", from, 1))
+
+	// 1W 不跨月：2024-06-01 为周六，取 6-03 周一
+	assert.Equal(t, []time.Time{utc(2024, 6, 3, 12, 0)}, nextUTC(t, "0 0 12 1W 6 ?", "UTC", from, 1))
+
+	// 每月第二个周一
+	assert.Equal(t, []time.Time{utc(2024, 2, 12, 12, 0), utc(2024, 3, 11, 12, 0)},
+		nextUTC(t, "0 0 12 ? * MON#2", "UTC", from, 2))
+
+	// 每月最后一个周五，数字与名称等价
+	assert.Equal(t, []time.Time{utc(2024, 1, 26, 12, 0), utc(2024, 2, 23, 12, 0)},
+		nextUTC(t, "0 0 12 ? * 5L", "UTC", from, 2))
+	assert.Equal(t, nextUTC(t, "0 0 12 ? * 5L", "UTC", from, 2), nextUTC(t, "0 0 12 ? * FRIL", "UTC", from, 2))
+
+	// 按任务时区判断日期：东京 1-31 零点为 UTC 1-30 15:00
+	assert.Equal(t, []time.Time{utc(2024, 1, 30, 15, 0)}, nextUTC(t, "0 0 0 L * ?", "Asia/Tokyo", from, 1))
+}
@@ -0,0 +1,155 @@
+package cronexpr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearchDays 查找满足日期规则的触发时间时最多向后搜索的天数，与 robfig/cron 的 5 年上限一致
+const maxSearchDays = 5 * 366
+
+// dayRule 判断某个日期（已位于目标时区）是否满足 Quartz 日期扩展
+type dayRule func(t time.Time) bool
+
+// quartzSchedule 标准字段（日、星期字段视为 *）确定当天的触发时刻，再由 day 过滤日期
+type quartzSchedule struct {
+	base specSchedule
+	day  dayRule
+}
+
+func (s quartzSchedule) nextIn(t time.Time, loc *time.Location) time.Time {
+	for i := 0; i < maxSearchDays; i++ {
+		next := s.base.nextIn(t, loc)
+		if next.IsZero() {
+			return next
+		}
+
+		local := next.In(loc)
+		if s.day(local) {
+			return next
+		}
+
+		// 跳到次日零点之前，继续查找
+		y, m, d := local.Date()
+		t = time.Date(y, m, d+1, 0, 0, 0, 0, loc).Add(-time.Second)
+	}
+	return time.Time{}
+}
+
+// parseDomRule 解析日字段的 L、L-n、LW、nW，普通字段返回 nil
+func parseDomRule(field string) (dayRule, error) {
+	f := strings.ToUpper(field)
+	if !strings.ContainsAny(f, "LW") {
+		return nil, nil
+	}
+
+	switch {
+	case f == "L":
+		return func(t time.Time) bool {
+			return t.Day() == daysIn(t)
+		}, nil
+
+	case f == "LW":
+		return func(t time.Time) bool {
+			return t.Day() == nearestWeekday(t, daysIn(t))
+		}, nil
+
+	case strings.HasPrefix(f, "L-"):
+		n, err := strconv.Atoi(f[2:])
+		if err != nil || n < 1 || n > 30 {
+			return nil, fmt.Errorf("invalid day-of-month offset: %s", field)
+		}
+		return func(t time.Time) bool {
+			return t.Day() == daysIn(t)-n
+		}, nil
+
+	case strings.HasSuffix(f, "W"):
+		n, err := strconv.Atoi(f[:len(f)-1])
+		if err != nil || n < 1 || n > 31 {
+			return nil, fmt.Errorf("invalid nearest weekday: %s", field)
+		}
+		return func(t time.Time) bool {
+			return n <= daysIn(t) && t.Day() == nearestWeekday(t, n)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("invalid day-of-month: %s", field)
+}
+
+// parseDowRule 解析星期字段的 xL（当月最后一个星期x）和 x#n（当月第n个星期x），普通字段返回 nil
+func parseDowRule(field string) (dayRule, error) {
+	f := strings.ToUpper(field)
+
+	if i := strings.Index(f, "#"); i != -1 {
+		weekday, err := parseWeekday(f[:i])
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(f[i+1:])
+		if err != nil || n < 1 || n > 5 {
+			return nil, fmt.Errorf("invalid weekday occurrence: %s", field)
+		}
+		return func(t time.Time) bool {
+			return t.Weekday() == weekday && (t.Day()-1)/7+1 == n
+		}, nil
+	}
+
+	if len(f) > 1 && strings.HasSuffix(f, "L") {
+		weekday, err := parseWeekday(f[:len(f)-1])
+		if err != nil {
+			return nil, err
+		}
+		return func(t time.Time) bool {
+			return t.Weekday() == weekday && t.Day()+7 > daysIn(t)
+		}, nil
+	}
+
+	return nil, nil
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"SUN": time.Sunday,
+	"MON": time.Monday,
+	"TUE": time.Tuesday,
+	"WED": time.Wednesday,
+	"THU": time.Thursday,
+	"FRI": time.Friday,
+	"SAT": time.Saturday,
+}
+
+// parseWeekday 解析 0-7 或 SUN-SAT，0 和 7 均表示周日
+func parseWeekday(s string) (time.Weekday, error) {
+	if weekday, ok := weekdayNames[s]; ok {
+		return weekday, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 7 {
+		return 0, fmt.Errorf("invalid day-of-week: %s", s)
+	}
+	return time.Weekday(n % 7), nil
+}
+
+// daysIn 返回 t 所在月份的天数
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// nearestWeekday 返回 t 所在月份中离 day 号最近的工作日，不跨月
+func nearestWeekday(t time.Time, day int) int {
+	last := daysIn(t)
+	switch time.Date(t.Year(), t.Month(), day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return 3
+		}
+		return day - 1
+	case time.Sunday:
+		if day == last {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
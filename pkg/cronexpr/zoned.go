@@ -0,0 +1,104 @@
+package cronexpr
+
+import (
+	"time"
+)
+
+// zonedSchedule 在挂钟调度之上修正夏令时切换，使表达式严格按挂钟时间触发：
+//   - 时钟拨快（如 02:00 -> 03:00）时，落在被跳过区间内的挂钟时间顺延同样时长触发（02:30 -> 03:30），
+//     而不是像 SpecSchedule 那样整天跳过
+//   - 时钟拨回（如 02:00 -> 01:00）时，重复出现的挂钟时间只在第一次出现时触发，不会触发两次
+type zonedSchedule struct {
+	inner wallSchedule
+	loc   *time.Location
+}
+
+// Next 返回 t 之后的下一次触发时间
+func (s zonedSchedule) Next(t time.Time) time.Time {
+	loc := s.loc
+	orig := t.Location()
+	t = t.In(loc)
+
+	for {
+		next := s.inner.nextIn(t, loc)
+		if next.IsZero() {
+			return next
+		}
+
+		// 回看一段时间，t 本身处于拨回后的重复区间时也能识别
+		skip := false
+		for from := t.Add(-maxOffsetChange); ; {
+			tr, ok := nextTransition(loc, from, next)
+			if !ok {
+				break
+			}
+
+			before := offsetAt(loc, tr.Add(-time.Second))
+			delta := time.Duration(offsetAt(loc, tr)-before) * time.Second
+
+			if delta > 0 {
+				// 拨快：以切换前的固定偏移计算，被跳过的挂钟时间正好映射到 [tr, tr+delta)
+				fixed := time.FixedZone("", before)
+				if c := s.inner.nextIn(tr.Add(-time.Second), fixed); c.After(t) && c.Before(tr.Add(delta)) && c.Before(next) {
+					next = c
+					break
+				}
+				from = tr
+				continue
+			}
+
+			// 拨回：[tr, tr-delta) 内的挂钟时间在切换前已出现过一次
+			repeatEnd := tr.Add(-delta)
+			if !next.Before(tr) && next.Before(repeatEnd) {
+				t = repeatEnd.Add(-time.Second).In(loc)
+				skip = true
+				break
+			}
+			from = tr
+		}
+
+		if !skip {
+			return next.In(orig)
+		}
+	}
+}
+
+// maxOffsetChange 单次时区切换的最大偏移变化
+const maxOffsetChange = 3 * time.Hour
+
+// transitionScanStep 查找时区偏移变化的步长，远小于两次夏令时切换的间隔
+const transitionScanStep = 24 * time.Hour
+
+// nextTransition 返回 (from, to] 内第一个偏移发生变化的时刻（精确到秒）
+func nextTransition(loc *time.Location, from, to time.Time) (time.Time, bool) {
+	offset := offsetAt(loc, from)
+
+	lo := from
+	for lo.Before(to) {
+		hi := lo.Add(transitionScanStep)
+		if hi.After(to) {
+			hi = to
+		}
+
+		if offsetAt(loc, hi) != offset {
+			// 二分定位到第一个新偏移生效的秒
+			l, h := lo.Unix(), hi.Unix()
+			for h-l > 1 {
+				m := l + (h-l)/2
+				if offsetAt(loc, time.Unix(m, 0)) == offset {
+					l = m
+				} else {
+					h = m
+				}
+			}
+			return time.Unix(h, 0), true
+		}
+		lo = hi
+	}
+	return time.Time{}, false
+}
+
+func offsetAt(loc *time.Location, t time.Time) int {
+	_, offset := t.In(loc).Zone()
+	return offset
+}
@@ -0,0 +1,375 @@
+// Package executor 执行器 SDK。
+//
+// 业务方只需按任务名注册处理函数，SDK 负责向调度器注册、接收 /execute 与 /stop、
+// 并发控制、签名回调及重试、进度与日志上报以及优雅退出：
+//
+//	sdk, err := executor.New(executor.Config{
+//		SchedulerURL: "http://scheduler:8080",
+//		ExecutorID:   "report-worker-1",
+//		ListenAddr:   ":9090",
+//	})
+//	sdk.Handle("daily_report", func(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error) {
+//		executor.Logf(ctx, "generating report for %v", params["date"])
+//		return map[string]interface{}{"rows": 42}, nil
+//	})
+//	go sdk.ListenAndServe()
+//	...
+//	sdk.Shutdown(ctx)
+//
+// 处理函数应遵守 ctx：调度器停止执行或 Shutdown 超时时 ctx 会被取消。
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// HandlerFunc 任务处理函数，返回的 result 随回调上报；返回错误时执行记为失败
+type HandlerFunc func(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error)
+
+// Config 执行器配置
+type Config struct {
+	// SchedulerURL 调度器地址，例如 http://scheduler:8080
+	SchedulerURL string
+	// ExecutorID 执行器唯一ID，重启后保持不变
+	ExecutorID string
+	// RegistrationToken 调度器配置的注册令牌，重启后丢失回调密钥时凭它重新注册同一 ExecutorID
+	RegistrationToken string
+	// ExecutorName 执行器名称，默认与 ExecutorID 相同
+	ExecutorName string
+	// ListenAddr 监听地址，默认 :9090
+	ListenAddr string
+	// AdvertiseURL 调度器访问本执行器的地址，默认 http://localhost 加监听端口
+	AdvertiseURL string
+	// MaxConcurrency 同时运行的最大执行数，已满时拒绝 /execute，调度器会改选其他执行器
+	MaxConcurrency int
+	// CallbackRetries 回调和日志上报失败后的最大重试次数
+	CallbackRetries int
+	// CallbackBackoff 首次重试的等待时间，之后每次翻倍
+	CallbackBackoff time.Duration
+	// FinishedRetention 已结束执行的结果保留时间，供调度器在回调丢失时查询
+	FinishedRetention time.Duration
+	// Metadata 注册时上报的元数据
+	Metadata map[string]interface{}
+	// HTTPClient 访问调度器使用的客户端
+	HTTPClient *http.Client
+	// Logger 日志器，默认不输出
+	Logger *zap.Logger
+}
+
+// 配置默认值
+const (
+	defaultListenAddr        = ":9090"
+	defaultMaxConcurrency    = 10
+	defaultCallbackRetries   = 5
+	defaultCallbackBackoff   = time.Second
+	defaultFinishedRetention = time.Hour
+)
+
+var (
+	// ErrDraining 执行器正在退出，不再接收新的执行
+	ErrDraining = errors.New("executor is shutting down")
+	// errStopped 调度器停止了执行
+	errStopped = errors.New("execution stopped by scheduler")
+)
+
+// Executor 执行器
+type Executor struct {
+	config Config
+	logger *zap.Logger
+	client *http.Client
+
+	mu          sync.RWMutex
+	handlers    map[string]HandlerFunc
+	definitions []TaskDefinition
+	credentials registerResponse
+	running     map[string]*execution
+	finished    map[string]finishedExecution
+	draining    bool
+
+	slots  chan struct{}
+	wg     sync.WaitGroup
+	server *http.Server
+}
+
+// finishedExecution 已结束的执行，保留一段时间供调度器查询状态
+type finishedExecution struct {
+	callback   CallbackRequest
+	finishedAt time.Time
+}
+
+// New 创建执行器
+func New(cfg Config) (*Executor, error) {
+	if cfg.SchedulerURL == "" {
+		return nil, fmt.Errorf("scheduler url is required")
+	}
+	if cfg.ExecutorID == "" {
+		return nil, fmt.Errorf("executor id is required")
+	}
+	cfg.SchedulerURL = strings.TrimSuffix(cfg.SchedulerURL, "/")
+	if cfg.ExecutorName == "" {
+		cfg.ExecutorName = cfg.ExecutorID
+	}
+	if cfg.ListenAddr == "" {
+		cfg.ListenAddr = defaultListenAddr
+	}
+	if cfg.AdvertiseURL == "" {
+		_, port, err := net.SplitHostPort(cfg.ListenAddr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid listen addr %q: %w", cfg.ListenAddr, err)
+		}
+		cfg.AdvertiseURL = "http://localhost:" + port
+	}
+	cfg.AdvertiseURL = strings.TrimSuffix(cfg.AdvertiseURL, "/")
+	if cfg.MaxConcurrency <= 0 {
+		cfg.MaxConcurrency = defaultMaxConcurrency
+	}
+	if cfg.CallbackRetries < 0 {
+		cfg.CallbackRetries = 0
+	} else if cfg.CallbackRetries == 0 {
+		cfg.CallbackRetries = defaultCallbackRetries
+	}
+	if cfg.CallbackBackoff <= 0 {
+		cfg.CallbackBackoff = defaultCallbackBackoff
+	}
+	if cfg.FinishedRetention <= 0 {
+		cfg.FinishedRetention = defaultFinishedRetention
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = zap.NewNop()
+	}
+
+	return &Executor{
+		config:   cfg,
+		logger:   cfg.Logger,
+		client:   cfg.HTTPClient,
+		handlers: make(map[string]HandlerFunc),
+		running:  make(map[string]*execution),
+		finished: make(map[string]finishedExecution),
+		slots:    make(chan struct{}, cfg.MaxConcurrency),
+	}, nil
+}
+
+// Handle 注册任务处理函数，任务需已在调度器中存在，注册执行器时会与之建立关联
+func (e *Executor) Handle(name string, handler HandlerFunc) {
+	e.HandleTask(TaskDefinition{Name: name}, handler)
+}
+
+// HandleTask 注册任务处理函数及任务定义，任务不存在时调度器按定义创建
+func (e *Executor) HandleTask(def TaskDefinition, handler HandlerFunc) {
+	if def.ExecutionMode == "" {
+		def.ExecutionMode = "parallel"
+	}
+	if def.LoadBalanceStrategy == "" {
+		def.LoadBalanceStrategy = "round_robin"
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, exists := e.handlers[def.Name]; !exists {
+		e.definitions = append(e.definitions, def)
+	} else {
+		for i := range e.definitions {
+			if e.definitions[i].Name == def.Name {
+				e.definitions[i] = def
+			}
+		}
+	}
+	e.handlers[def.Name] = handler
+}
+
+// Handler 返回执行器的 HTTP 处理器，便于挂载到已有的服务上
+func (e *Executor) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", e.healthHandler)
+	mux.HandleFunc("POST /execute", e.executeHandler)
+	mux.HandleFunc("POST /stop", e.stopHandler)
+	mux.HandleFunc("GET /status/{id}", e.statusHandler)
+	return mux
+}
+
+// ListenAndServe 监听端口、向调度器注册并处理请求，Shutdown 后返回 nil
+func (e *Executor) ListenAndServe() error {
+	listener, err := net.Listen("tcp", e.config.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", e.config.ListenAddr, err)
+	}
+
+	server := &http.Server{Handler: e.Handler()}
+	e.mu.Lock()
+	e.server = server
+	e.mu.Unlock()
+
+	// 先监听再注册，注册成功后调度器的健康检查和分发立即可达
+	if err := e.Register(context.Background()); err != nil {
+		listener.Close()
+		return err
+	}
+
+	e.logger.Info("executor listening",
+		zap.String("executor_id", e.config.ExecutorID),
+		zap.String("listen_addr", e.config.ListenAddr),
+		zap.String("advertise_url", e.config.AdvertiseURL))
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown 优雅退出：不再接收新的执行，通知调度器进入维护状态，等待运行中的执行结束。
+// ctx 结束时仍未完成的执行会被取消并以失败回调，之后关闭 HTTP 服务
+func (e *Executor) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	e.draining = true
+	server := e.server
+	e.mu.Unlock()
+
+	if err := e.updateStatus(ctx, "maintenance", "executor shutting down"); err != nil {
+		e.logger.Warn("failed to mark executor as maintenance", zap.Error(err))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+		// 处理函数可能不响应取消，直接上报失败，之后的结果将被忽略
+		for _, exec := range e.runningExecutions() {
+			exec.cancel(ErrDraining)
+			e.finish(exec, CallbackRequest{
+				ExecutionID: exec.req.ExecutionID,
+				Status:      StatusFailed,
+				Result:      map[string]interface{}{"error": ErrDraining.Error()},
+				Logs:        "execution interrupted: " + ErrDraining.Error(),
+			})
+		}
+	}
+
+	if server != nil {
+		if closeErr := server.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+func (e *Executor) healthHandler(w http.ResponseWriter, r *http.Request) {
+	e.mu.RLock()
+	draining := e.draining
+	running := len(e.running)
+	e.mu.RUnlock()
+
+	status, code := "healthy", http.StatusOK
+	if draining {
+		// 退出期间报告不健康，避免调度器继续分发
+		status, code = "draining", http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, map[string]interface{}{
+		"status":          status,
+		"executor_id":     e.config.ExecutorID,
+		"running":         running,
+		"max_concurrency": e.config.MaxConcurrency,
+		"time":            time.Now().Format(time.RFC3339),
+	})
+}
+
+func (e *Executor) executeHandler(w http.ResponseWriter, r *http.Request) {
+	var req ExecuteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if req.ExecutionID == "" || req.CallbackURL == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "execution_id and callback_url are required"})
+		return
+	}
+
+	code, err := e.start(req)
+	if err != nil {
+		writeJSON(w, code, map[string]string{"error": err.Error(), "execution_id": req.ExecutionID})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{
+		"message":      "execution accepted",
+		"execution_id": req.ExecutionID,
+	})
+}
+
+func (e *Executor) stopHandler(w http.ResponseWriter, r *http.Request) {
+	var req stopRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	e.mu.RLock()
+	exec, ok := e.running[req.ExecutionID]
+	e.mu.RUnlock()
+	if !ok {
+		// 调度器收到非 200 时不会将执行标记为已取消
+		writeJSON(w, http.StatusNotFound, map[string]string{
+			"error":        "execution not found or already finished",
+			"execution_id": req.ExecutionID,
+		})
+		return
+	}
+
+	exec.cancel(errStopped)
+	e.logger.Info("execution stopped by scheduler", zap.String("execution_id", req.ExecutionID))
+	writeJSON(w, http.StatusOK, map[string]string{
+		"message":      "execution stopped",
+		"execution_id": req.ExecutionID,
+	})
+}
+
+// statusHandler 查询执行状态：已结束时返回回调内容，调度器在回调丢失时据此结束执行
+func (e *Executor) statusHandler(w http.ResponseWriter, r *http.Request) {
+	executionID := r.PathValue("id")
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if finished, ok := e.finished[executionID]; ok {
+		writeJSON(w, http.StatusOK, finished.callback)
+		return
+	}
+	status := StatusUnknown
+	if _, ok := e.running[executionID]; ok {
+		status = StatusRunning
+	}
+	writeJSON(w, http.StatusOK, CallbackRequest{ExecutionID: executionID, Status: status})
+}
+
+func (e *Executor) runningExecutions() []*execution {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	executions := make([]*execution, 0, len(e.running))
+	for _, exec := range e.running {
+		executions = append(executions, exec)
+	}
+	return executions
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,167 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jobs/scheduler/pkg/callbackauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScheduler 记录执行器发来的注册、状态更新和经过签名校验的回调
+type fakeScheduler struct {
+	*httptest.Server
+	mu         sync.Mutex
+	register   RegisterRequest
+	status     string
+	callbacks  chan CallbackRequest
+	logs       []string
+	failNextCB atomic.Bool
+}
+
+func newFakeScheduler(t *testing.T) *fakeScheduler {
+	s := &fakeScheduler{callbacks: make(chan CallbackRequest, 10)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/api/v1/executors/register":
+			assert.NoError(t, json.Unmarshal(body, &s.register))
+			writeJSON(w, http.StatusOK, registerResponse{ID: s.register.ExecutorID, CallbackSecret: "secret"})
+			return
+		case strings.HasSuffix(r.URL.Path, "/status"):
+			var req statusRequest
+			assert.NoError(t, json.Unmarshal(body, &req))
+			s.status = req.Status
+			writeJSON(w, http.StatusOK, map[string]string{"message": "status updated"})
+			return
+		}
+
+		if !callbackauth.Verify("secret", r.Header.Get(callbackauth.HeaderTimestamp), r.Header.Get(callbackauth.HeaderNonce), body, r.Header.Get(callbackauth.HeaderSignature)) {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid signature"})
+			return
+		}
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/callback"):
+			if s.failNextCB.CompareAndSwap(true, false) {
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "database unavailable"})
+				return
+			}
+			var callback CallbackRequest
+			assert.NoError(t, json.Unmarshal(body, &callback))
+			s.callbacks <- callback
+		case strings.HasSuffix(r.URL.Path, "/logs"):
+			var entry logRequest
+			assert.NoError(t, json.Unmarshal(body, &entry))
+			assert.Equal(t, 2, entry.Attempt)
+			s.logs = append(s.logs, entry.Content)
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"message": "ok"})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeScheduler) nextCallback(t *testing.T) CallbackRequest {
+	select {
+	case callback := <-s.callbacks:
+		return callback
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback not received")
+		return CallbackRequest{}
+	}
+}
+
+func TestExecutorLifecycle(t *testing.T) {
+	scheduler := newFakeScheduler(t)
+	sdk, err := New(Config{
+		SchedulerURL:    scheduler.URL,
+		ExecutorID:      "worker-1",
+		MaxConcurrency:  1,
+		CallbackRetries: 2,
+		CallbackBackoff: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	release := make(chan struct{})
+	sdk.Handle("daily_report", func(ctx context.Context, params map[string]interface{}) (map[string]interface{}, error) {
+		assert.NoError(t, Logf(ctx, "report for %v", params["date"]))
+		select {
+		case <-release:
+			return nil, errors.New("upstream unavailable")
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+	require.NoError(t, sdk.Register(context.Background()))
+	assert.Equal(t, "daily_report", scheduler.register.Tasks[0].Name)
+	assert.Equal(t, "parallel", scheduler.register.Tasks[0].ExecutionMode)
+
+	server := httptest.NewServer(sdk.Handler())
+	defer server.Close()
+	post := func(path string, body interface{}) int {
+		data, _ := json.Marshal(body)
+		resp, err := http.Post(server.URL+path, "application/json", bytes.NewReader(data))
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	execute := func(id string) int {
+		return post("/execute", ExecuteRequest{
+			ExecutionID: id,
+			TaskName:    "daily_report",
+			Parameters:  map[string]interface{}{"date": "2024-01-01"},
+			Attempt:     2,
+			CallbackURL: scheduler.URL + "/api/v1/executions/" + id + "/callback",
+		})
+	}
+
+	// 并发已满时拒绝，调度器会改选其他执行器
+	require.Equal(t, http.StatusAccepted, execute("exec-1"))
+	assert.Equal(t, http.StatusServiceUnavailable, execute("exec-2"))
+	assert.Equal(t, http.StatusNotFound, post("/execute", ExecuteRequest{ExecutionID: "exec-3", TaskName: "unknown", CallbackURL: scheduler.URL}))
+
+	// 停止时取消 ctx 并以 cancelled 回调
+	assert.Equal(t, http.StatusOK, post("/stop", stopRequest{ExecutionID: "exec-1"}))
+	assert.Equal(t, StatusCancelled, scheduler.nextCallback(t).Status)
+	assert.Equal(t, http.StatusNotFound, post("/stop", stopRequest{ExecutionID: "exec-1"}))
+
+	// 回调失败后重试，处理函数的错误记为失败
+	scheduler.failNextCB.Store(true)
+	require.Eventually(t, func() bool { return execute("exec-2") == http.StatusAccepted }, time.Second, 10*time.Millisecond)
+	close(release)
+	callback := scheduler.nextCallback(t)
+	assert.Equal(t, StatusFailed, callback.Status)
+	assert.Equal(t, "upstream unavailable", callback.Result["error"])
+	// 回调回显分发请求中的 attempt
+	assert.Equal(t, 2, callback.Attempt)
+
+	resp, err := http.Get(server.URL + "/status/exec-2")
+	require.NoError(t, err)
+	var status CallbackRequest
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	resp.Body.Close()
+	assert.Equal(t, StatusFailed, status.Status)
+
+	// 退出后不再接收新的执行
+	require.NoError(t, sdk.Shutdown(context.Background()))
+	assert.Equal(t, "maintenance", scheduler.status)
+	assert.Equal(t, http.StatusServiceUnavailable, execute("exec-4"))
+
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+	assert.Equal(t, []string{"report for 2024-01-01\n", "report for 2024-01-01\n"}, scheduler.logs)
+}
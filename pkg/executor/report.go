@@ -0,0 +1,151 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/jobs/scheduler/pkg/callbackauth"
+	"go.uber.org/zap"
+)
+
+// requestAuth 发往调度器的请求的认证方式
+type requestAuth int
+
+const (
+	authNone     requestAuth = iota
+	authSigned               // 使用注册获得的密钥签名
+	authRegister             // 注册请求：已有凭证时用其签名，配置了注册令牌时一并携带
+)
+
+// Register 向调度器注册执行器及已注册处理函数的任务，保存回调签名凭证。
+// 每次注册都会生成新的回调密钥，调度器不可达时按回调的重试策略重试。
+// 调度器中已有同一 ExecutorID 时，需用当前凭证签名或携带 Config.RegistrationToken 才能重新注册
+func (e *Executor) Register(ctx context.Context) error {
+	e.mu.RLock()
+	tasks := make([]TaskDefinition, len(e.definitions))
+	copy(tasks, e.definitions)
+	e.mu.RUnlock()
+
+	req := RegisterRequest{
+		ExecutorID:     e.config.ExecutorID,
+		ExecutorName:   e.config.ExecutorName,
+		ExecutorURL:    e.config.AdvertiseURL,
+		HealthCheckURL: e.config.AdvertiseURL + "/health",
+		Tasks:          tasks,
+		Metadata:       e.config.Metadata,
+	}
+
+	var resp registerResponse
+	url := e.config.SchedulerURL + "/api/v1/executors/register"
+	if err := e.send(ctx, http.MethodPost, url, req, authRegister, e.config.CallbackRetries, &resp); err != nil {
+		return fmt.Errorf("failed to register executor: %w", err)
+	}
+	if resp.ID == "" || resp.CallbackSecret == "" {
+		return fmt.Errorf("failed to register executor: response carries no callback credentials")
+	}
+
+	e.mu.Lock()
+	e.credentials = resp
+	e.mu.Unlock()
+
+	e.logger.Info("executor registered",
+		zap.String("executor_id", resp.ID),
+		zap.Int("tasks_count", len(tasks)))
+	return nil
+}
+
+// updateStatus 更新调度器中本执行器的状态
+func (e *Executor) updateStatus(ctx context.Context, status, reason string) error {
+	e.mu.RLock()
+	executorID := e.credentials.ID
+	e.mu.RUnlock()
+	if executorID == "" {
+		return fmt.Errorf("executor is not registered")
+	}
+
+	url := fmt.Sprintf("%s/api/v1/executors/%s/status", e.config.SchedulerURL, executorID)
+	return e.send(ctx, http.MethodPut, url, statusRequest{Status: status, Reason: reason}, authNone, 0, nil)
+}
+
+// send 发送 JSON 请求，网络错误、5xx 和 429 时按指数退避最多重试 retries 次；
+// 需要签名时每次重试重新签名
+func (e *Executor) send(ctx context.Context, method, url string, payload interface{}, auth requestAuth, retries int, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	backoff := e.config.CallbackBackoff
+	for attempt := 0; ; attempt++ {
+		retryable, err := e.sendOnce(ctx, method, url, body, auth, out)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= retries {
+			return err
+		}
+
+		e.logger.Warn("request to scheduler failed, retrying",
+			zap.String("url", url),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("backoff", backoff),
+			zap.Error(err))
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+func (e *Executor) sendOnce(ctx context.Context, method, url string, body []byte, auth requestAuth, out interface{}) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if auth != authNone {
+		e.mu.RLock()
+		credentials := e.credentials
+		e.mu.RUnlock()
+		if auth == authSigned || credentials.ID != "" {
+			if err := callbackauth.SignRequest(req, credentials.ID, credentials.CallbackSecret, body); err != nil {
+				return false, err
+			}
+		}
+	}
+	if auth == authRegister && e.config.RegistrationToken != "" {
+		req.Header.Set(callbackauth.HeaderRegistrationToken, e.config.RegistrationToken)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		_ = json.Unmarshal(data, &errResp)
+		retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return retryable, fmt.Errorf("%s %s returned status %d: %s", method, url, resp.StatusCode, errResp.Error)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return false, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return false, nil
+}
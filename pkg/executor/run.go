@@ -0,0 +1,184 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// execution 运行中的执行
+type execution struct {
+	req       ExecuteRequest
+	executor  *Executor
+	cancel    context.CancelCauseFunc
+	startTime time.Time
+	logSeq    atomic.Int64
+	once      sync.Once
+}
+
+type contextKey struct{}
+
+// start 接收执行并异步运行处理函数，返回拒绝时应响应的状态码
+func (e *Executor) start(req ExecuteRequest) (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.draining {
+		return http.StatusServiceUnavailable, ErrDraining
+	}
+	handler, ok := e.handlers[req.TaskName]
+	if !ok {
+		return http.StatusNotFound, fmt.Errorf("no handler registered for task %q", req.TaskName)
+	}
+	// 重复下发的执行已在运行，直接视为已接收
+	if _, ok := e.running[req.ExecutionID]; ok {
+		return http.StatusAccepted, nil
+	}
+
+	select {
+	case e.slots <- struct{}{}:
+	default:
+		// 拒绝后调度器会改选其他执行器
+		return http.StatusServiceUnavailable, fmt.Errorf("executor is at max concurrency %d", e.config.MaxConcurrency)
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	exec := &execution{
+		req:       req,
+		executor:  e,
+		cancel:    cancel,
+		startTime: time.Now(),
+	}
+	// 调度器判定执行丢失后会以同一ID重新下发，此前保留的结果作废
+	delete(e.finished, req.ExecutionID)
+	e.running[req.ExecutionID] = exec
+	e.wg.Add(1)
+
+	e.logger.Info("execution accepted",
+		zap.String("execution_id", req.ExecutionID),
+		zap.String("task_name", req.TaskName))
+
+	go e.run(ctx, exec, handler)
+	return http.StatusAccepted, nil
+}
+
+// run 运行处理函数并回调结果
+func (e *Executor) run(ctx context.Context, exec *execution, handler HandlerFunc) {
+	defer func() {
+		<-e.slots
+		e.wg.Done()
+	}()
+
+	result, err := e.invoke(context.WithValue(ctx, contextKey{}, exec), handler, exec.req.Parameters)
+	elapsed := time.Since(exec.startTime)
+
+	callback := CallbackRequest{
+		ExecutionID: exec.req.ExecutionID,
+		Status:      StatusSuccess,
+		Result:      result,
+		Logs:        fmt.Sprintf("%s finished in %s", exec.req.TaskName, elapsed),
+	}
+	switch {
+	case errors.Is(context.Cause(ctx), errStopped):
+		// 调度器已将执行标记为取消，无论处理函数结果如何都上报取消
+		callback.Status = StatusCancelled
+		callback.Logs = fmt.Sprintf("%s stopped by scheduler after %s", exec.req.TaskName, elapsed)
+	case err != nil:
+		if callback.Result == nil {
+			callback.Result = make(map[string]interface{})
+		}
+		callback.Status = StatusFailed
+		callback.Result["error"] = err.Error()
+		callback.Logs = fmt.Sprintf("%s failed after %s: %v", exec.req.TaskName, elapsed, err)
+	}
+
+	e.finish(exec, callback)
+}
+
+// invoke 调用处理函数，处理函数 panic 时视为失败
+func (e *Executor) invoke(ctx context.Context, handler HandlerFunc, params map[string]interface{}) (result map[string]interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("handler panicked: %v", p)
+		}
+	}()
+	return handler(ctx, params)
+}
+
+// finish 记录结果并回调调度器，每个执行只回调一次
+func (e *Executor) finish(exec *execution, callback CallbackRequest) {
+	callback.Attempt = exec.req.Attempt
+	exec.once.Do(func() {
+		// 先记录结果，回调失败时调度器仍可通过状态查询获知
+		e.mu.Lock()
+		now := time.Now()
+		for id, finished := range e.finished {
+			if now.Sub(finished.finishedAt) > e.config.FinishedRetention {
+				delete(e.finished, id)
+			}
+		}
+		delete(e.running, exec.req.ExecutionID)
+		e.finished[exec.req.ExecutionID] = finishedExecution{callback: callback, finishedAt: now}
+		e.mu.Unlock()
+		exec.cancel(nil)
+
+		if err := e.send(context.Background(), http.MethodPost, exec.req.CallbackURL, callback, authSigned, e.config.CallbackRetries, nil); err != nil {
+			e.logger.Error("failed to send execution callback",
+				zap.String("execution_id", exec.req.ExecutionID),
+				zap.String("status", callback.Status),
+				zap.Error(err))
+			return
+		}
+		e.logger.Info("execution finished",
+			zap.String("execution_id", exec.req.ExecutionID),
+			zap.String("status", callback.Status))
+	})
+}
+
+// ExecutionFromContext 返回处理函数 ctx 对应的执行请求
+func ExecutionFromContext(ctx context.Context) (ExecuteRequest, bool) {
+	exec, ok := ctx.Value(contextKey{}).(*execution)
+	if !ok {
+		return ExecuteRequest{}, false
+	}
+	return exec.req, true
+}
+
+// ReportProgress 上报执行进度，调度器同时据此顺延超时；只发送一次，失败时由下次上报弥补
+func ReportProgress(ctx context.Context, progress Progress) error {
+	exec, ok := ctx.Value(contextKey{}).(*execution)
+	if !ok {
+		return fmt.Errorf("context does not belong to an execution")
+	}
+	return exec.executor.send(ctx, http.MethodPost, siblingURL(exec.req.CallbackURL, "progress"),
+		progressRequest{ExecutionID: exec.req.ExecutionID, Attempt: exec.req.Attempt, Progress: progress}, authSigned, 0, nil)
+}
+
+// Logf 追加一行执行日志，失败时按回调的策略重试，调度器按序号去重
+func Logf(ctx context.Context, format string, args ...interface{}) error {
+	exec, ok := ctx.Value(contextKey{}).(*execution)
+	if !ok {
+		return fmt.Errorf("context does not belong to an execution")
+	}
+
+	content := fmt.Sprintf(format, args...)
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	// 取消后的收尾日志仍需送达
+	return exec.executor.send(context.WithoutCancel(ctx), http.MethodPost, siblingURL(exec.req.CallbackURL, "logs"),
+		logRequest{ExecutionID: exec.req.ExecutionID, Attempt: exec.req.Attempt, Seq: exec.logSeq.Add(1), Content: content},
+		authSigned, exec.executor.config.CallbackRetries, nil)
+}
+
+// siblingURL 进度和日志接口与回调接口位于同一路径下
+func siblingURL(callbackURL, name string) string {
+	return strings.TrimSuffix(callbackURL, "/callback") + "/" + name
+}
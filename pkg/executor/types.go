@@ -0,0 +1,93 @@
+package executor
+
+// 执行状态，回调时上报的终态
+const (
+	StatusSuccess   = "success"
+	StatusFailed    = "failed"
+	StatusCancelled = "cancelled"
+	StatusRunning   = "running"
+	StatusUnknown   = "unknown"
+)
+
+// TaskDefinition 注册时随执行器一起提交的任务定义
+// 同名任务已存在时调度器只建立关联并更新参数 schema，不修改任务的其他字段
+type TaskDefinition struct {
+	Name                string                 `json:"name"`
+	ExecutionMode       string                 `json:"execution_mode"`
+	CronExpression      string                 `json:"cron_expression"`
+	LoadBalanceStrategy string                 `json:"load_balance_strategy"`
+	MaxRetry            int                    `json:"max_retry"`
+	TimeoutSeconds      int                    `json:"timeout_seconds"`
+	Parameters          map[string]interface{} `json:"parameters"`
+	ParametersSchema    map[string]interface{} `json:"parameters_schema,omitempty"` // 参数的 JSON Schema，支持的关键字见 pkg/jsonschema
+	Status              string                 `json:"status"`
+}
+
+// RegisterRequest 执行器注册请求
+type RegisterRequest struct {
+	ExecutorID     string                 `json:"executor_id"`
+	ExecutorName   string                 `json:"executor_name"`
+	ExecutorURL    string                 `json:"executor_url"`
+	HealthCheckURL string                 `json:"health_check_url"`
+	Tasks          []TaskDefinition       `json:"tasks"`
+	Metadata       map[string]interface{} `json:"metadata"`
+}
+
+// registerResponse 注册响应中用于签名回调的凭证
+type registerResponse struct {
+	ID             string `json:"id"`
+	CallbackSecret string `json:"callback_secret"`
+}
+
+// ExecuteRequest 调度器下发的执行请求
+type ExecuteRequest struct {
+	ExecutionID  string                 `json:"execution_id"`
+	TaskID       string                 `json:"task_id"`
+	TaskName     string                 `json:"task_name"`
+	Parameters   map[string]interface{} `json:"parameters"`
+	FencingToken int64                  `json:"fencing_token"`
+	Attempt      int                    `json:"attempt"` // 第几次重试，0 为首次尝试，回调、进度和日志上报时原样回显
+	CallbackURL  string                 `json:"callback_url"`
+}
+
+// stopRequest 调度器下发的停止请求
+type stopRequest struct {
+	ExecutionID string `json:"execution_id"`
+}
+
+// CallbackRequest 执行结束后的回调，同时作为 /status 查询的响应
+type CallbackRequest struct {
+	ExecutionID string                 `json:"execution_id"`
+	Attempt     int                    `json:"attempt"`
+	Status      string                 `json:"status"`
+	Result      map[string]interface{} `json:"result"`
+	Logs        string                 `json:"logs"`
+}
+
+// Progress 执行进度，未设置的字段保持调度器上的原值
+type Progress struct {
+	Percent  *float64         `json:"percent,omitempty"`
+	Stage    string           `json:"stage,omitempty"`
+	Counters map[string]int64 `json:"counters,omitempty"`
+}
+
+// progressRequest 进度上报请求
+type progressRequest struct {
+	ExecutionID string `json:"execution_id"`
+	Attempt     int    `json:"attempt"`
+	Progress
+}
+
+// logRequest 日志追加请求，Seq 在每次执行内从1开始递增
+type logRequest struct {
+	ExecutionID string `json:"execution_id"`
+	Attempt     int    `json:"attempt"`
+	Seq         int64  `json:"seq"`
+	Content     string `json:"content"`
+}
+
+// statusRequest 更新执行器状态请求
+type statusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
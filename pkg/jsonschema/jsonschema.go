@@ -0,0 +1,471 @@
+// Package jsonschema 任务参数使用的 JSON Schema 子集，编译后按字段返回校验错误。
+//
+// 支持的关键字：
+//   - 通用：type（字符串或数组）、enum、const
+//   - 对象：properties、required、additionalProperties（布尔值或 schema）
+//   - 数组：items、minItems、maxItems
+//   - 字符串：minLength、maxLength、pattern、format（date、date-time、time、email、uri，其他格式忽略）
+//   - 数值：minimum、maximum、exclusiveMinimum、exclusiveMaximum、multipleOf
+//
+// title、description、default 等注解关键字被忽略；$ref、oneOf 等未支持的关键字在编译时报错，避免约束被静默忽略。
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalidSchema schema 本身不合法
+var ErrInvalidSchema = errors.New("invalid json schema")
+
+// RootField 值本身（而非某个字段）不满足 schema 时 FieldError.Field 的取值
+const RootField = "(root)"
+
+// FieldError 单个字段的校验错误，Field 为以 . 分隔的字段路径，数组元素为 [i]
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError 值不满足 schema，Errors 按字段路径排序
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		parts = append(parts, fe.Field+": "+fe.Message)
+	}
+	return "parameters do not match schema: " + strings.Join(parts, "; ")
+}
+
+// 注解关键字，不参与校验
+var annotations = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true,
+	"title": true, "description": true, "default": true, "examples": true,
+	"readOnly": true, "writeOnly": true, "deprecated": true,
+}
+
+var validTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true,
+	"integer": true, "boolean": true, "null": true,
+}
+
+// Schema 编译后的 schema
+type Schema struct {
+	types    []string
+	enum     []interface{}
+	hasConst bool
+	constVal interface{}
+
+	properties   map[string]*Schema
+	required     []string
+	additional   *Schema
+	noAdditional bool
+
+	items    *Schema
+	minItems *int
+	maxItems *int
+
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+	format    string
+
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+}
+
+// Compile 编译 schema，schema 可以是 map 或任意可序列化为 JSON 对象的值
+func Compile(schema interface{}) (*Schema, error) {
+	doc, err := normalize(schema)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	return compile(doc, RootField)
+}
+
+func compile(doc interface{}, path string) (*Schema, error) {
+	fail := func(format string, args ...interface{}) (*Schema, error) {
+		return nil, fmt.Errorf("%w: %s: %s", ErrInvalidSchema, path, fmt.Sprintf(format, args...))
+	}
+
+	// true/false 分别表示接受和拒绝任意值
+	if b, ok := doc.(bool); ok {
+		if b {
+			return &Schema{}, nil
+		}
+		return &Schema{hasConst: true, constVal: rejectAll{}}, nil
+	}
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return fail("schema must be an object")
+	}
+
+	s := &Schema{}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := m[key]
+		switch key {
+		case "type":
+			switch v := value.(type) {
+			case string:
+				s.types = []string{v}
+			case []interface{}:
+				for _, t := range v {
+					name, ok := t.(string)
+					if !ok {
+						return fail("type must be a string or an array of strings")
+					}
+					s.types = append(s.types, name)
+				}
+			default:
+				return fail("type must be a string or an array of strings")
+			}
+			for _, t := range s.types {
+				if !validTypes[t] {
+					return fail("unknown type %q", t)
+				}
+			}
+		case "enum":
+			values, ok := value.([]interface{})
+			if !ok || len(values) == 0 {
+				return fail("enum must be a non-empty array")
+			}
+			s.enum = values
+		case "const":
+			s.hasConst = true
+			s.constVal = value
+		case "properties":
+			props, ok := value.(map[string]interface{})
+			if !ok {
+				return fail("properties must be an object")
+			}
+			s.properties = make(map[string]*Schema, len(props))
+			for name, prop := range props {
+				compiled, err := compile(prop, joinField(path, name))
+				if err != nil {
+					return nil, err
+				}
+				s.properties[name] = compiled
+			}
+		case "required":
+			names, ok := value.([]interface{})
+			if !ok {
+				return fail("required must be an array of strings")
+			}
+			for _, n := range names {
+				name, ok := n.(string)
+				if !ok {
+					return fail("required must be an array of strings")
+				}
+				s.required = append(s.required, name)
+			}
+		case "additionalProperties":
+			if b, ok := value.(bool); ok {
+				s.noAdditional = !b
+				continue
+			}
+			compiled, err := compile(value, path)
+			if err != nil {
+				return nil, err
+			}
+			s.additional = compiled
+		case "items":
+			compiled, err := compile(value, path+"[]")
+			if err != nil {
+				return nil, err
+			}
+			s.items = compiled
+		case "minItems", "maxItems", "minLength", "maxLength":
+			n, ok := value.(float64)
+			if !ok || n < 0 || n != math.Trunc(n) {
+				return fail("%s must be a non-negative integer", key)
+			}
+			limit := int(n)
+			switch key {
+			case "minItems":
+				s.minItems = &limit
+			case "maxItems":
+				s.maxItems = &limit
+			case "minLength":
+				s.minLength = &limit
+			case "maxLength":
+				s.maxLength = &limit
+			}
+		case "pattern":
+			expr, ok := value.(string)
+			if !ok {
+				return fail("pattern must be a string")
+			}
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return fail("invalid pattern: %v", err)
+			}
+			s.pattern = re
+		case "format":
+			format, ok := value.(string)
+			if !ok {
+				return fail("format must be a string")
+			}
+			s.format = format
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "multipleOf":
+			n, ok := value.(float64)
+			if !ok {
+				return fail("%s must be a number", key)
+			}
+			switch key {
+			case "minimum":
+				s.minimum = &n
+			case "maximum":
+				s.maximum = &n
+			case "exclusiveMinimum":
+				s.exclusiveMinimum = &n
+			case "exclusiveMaximum":
+				s.exclusiveMaximum = &n
+			case "multipleOf":
+				if n <= 0 {
+					return fail("multipleOf must be greater than 0")
+				}
+				s.multipleOf = &n
+			}
+		default:
+			if !annotations[key] {
+				return fail("unsupported keyword %q", key)
+			}
+		}
+	}
+	return s, nil
+}
+
+// rejectAll false schema 的 const 值，不与任何值相等
+type rejectAll struct{}
+
+// Validate 校验值，不满足时返回 *ValidationError
+func (s *Schema) Validate(value interface{}) error {
+	doc, err := normalize(value)
+	if err != nil {
+		return &ValidationError{Errors: []FieldError{{Field: RootField, Message: err.Error()}}}
+	}
+
+	var errs []FieldError
+	s.validate(doc, RootField, &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return &ValidationError{Errors: errs}
+}
+
+func (s *Schema) validate(value interface{}, path string, errs *[]FieldError) {
+	add := func(format string, args ...interface{}) {
+		*errs = append(*errs, FieldError{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if s.hasConst {
+		if _, ok := s.constVal.(rejectAll); ok {
+			add("is not allowed")
+			return
+		}
+		if !reflect.DeepEqual(value, s.constVal) {
+			add("must be %s", encode(s.constVal))
+			return
+		}
+	}
+
+	if len(s.types) > 0 && !matchesType(value, s.types) {
+		add("must be %s", strings.Join(s.types, " or "))
+		return
+	}
+
+	if len(s.enum) > 0 {
+		found := false
+		for _, candidate := range s.enum {
+			if reflect.DeepEqual(value, candidate) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			add("must be one of %s", encode(s.enum))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		s.validateObject(v, path, errs)
+	case []interface{}:
+		if s.minItems != nil && len(v) < *s.minItems {
+			add("must contain at least %d items", *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			add("must contain at most %d items", *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range v {
+				s.items.validate(item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.minLength != nil && length < *s.minLength {
+			add("length must be at least %d", *s.minLength)
+		}
+		if s.maxLength != nil && length > *s.maxLength {
+			add("length must be at most %d", *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			add("must match pattern %q", s.pattern.String())
+		}
+		if s.format != "" && !validFormat(s.format, v) {
+			add("must be a valid %s", s.format)
+		}
+	case float64:
+		if s.minimum != nil && v < *s.minimum {
+			add("must be >= %v", *s.minimum)
+		}
+		if s.maximum != nil && v > *s.maximum {
+			add("must be <= %v", *s.maximum)
+		}
+		if s.exclusiveMinimum != nil && v <= *s.exclusiveMinimum {
+			add("must be > %v", *s.exclusiveMinimum)
+		}
+		if s.exclusiveMaximum != nil && v >= *s.exclusiveMaximum {
+			add("must be < %v", *s.exclusiveMaximum)
+		}
+		if s.multipleOf != nil {
+			quotient := v / *s.multipleOf
+			if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+				add("must be a multiple of %v", *s.multipleOf)
+			}
+		}
+	}
+}
+
+func (s *Schema) validateObject(obj map[string]interface{}, path string, errs *[]FieldError) {
+	for _, name := range s.required {
+		if _, ok := obj[name]; !ok {
+			*errs = append(*errs, FieldError{Field: joinField(path, name), Message: "is required"})
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		field := joinField(path, name)
+		if prop, ok := s.properties[name]; ok {
+			prop.validate(obj[name], field, errs)
+			continue
+		}
+		switch {
+		case s.noAdditional:
+			*errs = append(*errs, FieldError{Field: field, Message: "is not allowed"})
+		case s.additional != nil:
+			s.additional.validate(obj[name], field, errs)
+		}
+	}
+}
+
+func matchesType(value interface{}, types []string) bool {
+	for _, t := range types {
+		switch v := value.(type) {
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case float64:
+			if t == "number" || (t == "integer" && v == math.Trunc(v)) {
+				return true
+			}
+		case []interface{}:
+			if t == "array" {
+				return true
+			}
+		case map[string]interface{}:
+			if t == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func validFormat(format, v string) bool {
+	switch format {
+	case "date":
+		_, err := time.Parse(time.DateOnly, v)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, v)
+		return err == nil
+	case "time":
+		_, err := time.Parse(time.TimeOnly, v)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(v)
+		return err == nil && addr.Address == v
+	case "uri":
+		u, err := url.Parse(v)
+		return err == nil && u.Scheme != ""
+	}
+	return true
+}
+
+// normalize 将值转换为 JSON 解码后的通用形式（map[string]interface{}、[]interface{}、float64 等）
+func normalize(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func joinField(path, name string) string {
+	if path == RootField {
+		return name
+	}
+	return path + "." + name
+}
+
+func encode(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
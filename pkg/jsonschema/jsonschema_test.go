@@ -0,0 +1,107 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustCompile(t *testing.T, schema map[string]interface{}) *Schema {
+	t.Helper()
+	s, err := Compile(schema)
+	require.NoError(t, err)
+	return s
+}
+
+func fieldErrors(t *testing.T, err error) []FieldError {
+	t.Helper()
+	var verr *ValidationError
+	require.ErrorAs(t, err, &verr)
+	return verr.Errors
+}
+
+func TestValidate(t *testing.T) {
+	s := mustCompile(t, map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"date", "mode"},
+		"properties": map[string]interface{}{
+			"date":  map[string]interface{}{"type": "string", "format": "date"},
+			"mode":  map[string]interface{}{"enum": []interface{}{"full", "incremental"}},
+			"limit": map[string]interface{}{"type": "integer", "minimum": 1, "maximum": 1000},
+			"tags": map[string]interface{}{
+				"type":     "array",
+				"maxItems": 2,
+				"items":    map[string]interface{}{"type": "string", "pattern": "^[a-z]+$"},
+			},
+			"target": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": false,
+				"properties":           map[string]interface{}{"table": map[string]interface{}{"type": "string", "minLength": 1}},
+			},
+		},
+		"additionalProperties": false,
+	})
+
+	assert.NoError(t, s.Validate(map[string]interface{}{
+		"date":   "2024-01-01",
+		"mode":   "full",
+		"limit":  10,
+		"tags":   []string{"daily"},
+		"target": map[string]interface{}{"table": "orders"},
+	}))
+
+	err := s.Validate(map[string]interface{}{
+		"date":   "01/02/2024",
+		"limit":  1.5,
+		"tags":   []interface{}{"ok", "NOT", "x"},
+		"target": map[string]interface{}{"table": "", "schema": "public"},
+		"dryrun": true,
+	})
+	assert.Equal(t, []FieldError{
+		{Field: "date", Message: "must be a valid date"},
+		{Field: "dryrun", Message: "is not allowed"},
+		{Field: "limit", Message: "must be integer"},
+		{Field: "mode", Message: "is required"},
+		{Field: "tags", Message: "must contain at most 2 items"},
+		{Field: "tags[1]", Message: `must match pattern "^[a-z]+$"`},
+		{Field: "target.schema", Message: "is not allowed"},
+		{Field: "target.table", Message: "length must be at least 1"},
+	}, fieldErrors(t, err))
+
+	// 值本身类型不符时报告在根上
+	err = s.Validate([]interface{}{1})
+	assert.Equal(t, []FieldError{{Field: RootField, Message: "must be object"}}, fieldErrors(t, err))
+}
+
+func TestValidateNumbers(t *testing.T) {
+	s := mustCompile(t, map[string]interface{}{
+		"type":             []interface{}{"number", "null"},
+		"exclusiveMinimum": 0,
+		"multipleOf":       0.5,
+	})
+
+	assert.NoError(t, s.Validate(2.5))
+	assert.NoError(t, s.Validate(nil))
+	assert.Equal(t, []FieldError{{Field: RootField, Message: "must be > 0"}}, fieldErrors(t, s.Validate(0)))
+	assert.Equal(t, []FieldError{{Field: RootField, Message: "must be a multiple of 0.5"}}, fieldErrors(t, s.Validate(0.7)))
+	assert.Equal(t, []FieldError{{Field: RootField, Message: "must be number or null"}}, fieldErrors(t, s.Validate("1")))
+}
+
+func TestCompileRejectsInvalidSchema(t *testing.T) {
+	for _, schema := range []map[string]interface{}{
+		{"type": "text"},
+		{"required": "date"},
+		{"properties": map[string]interface{}{"date": map[string]interface{}{"pattern": "("}}},
+		{"minLength": -1},
+		{"oneOf": []interface{}{}},
+		{"$ref": "#/definitions/date"},
+	} {
+		_, err := Compile(schema)
+		assert.ErrorIs(t, err, ErrInvalidSchema, "%v", schema)
+	}
+
+	// 注解关键字被忽略
+	_, err := Compile(map[string]interface{}{"title": "params", "description": "x", "default": map[string]interface{}{}})
+	assert.NoError(t, err)
+}
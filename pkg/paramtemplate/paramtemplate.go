@@ -0,0 +1,159 @@
+// Package paramtemplate 任务参数模板，分发执行时按本次执行的上下文展开参数中的 text/template 表达式。
+//
+// 参数中包含 {{ 的字符串值（含嵌套对象和数组中的字符串）作为模板展开，其他值原样保留。可用的变量：
+//   - .ScheduledTime 计划触发时间（任务时区），直接输出为 RFC3339 格式
+//   - .ExecutionID、.TaskID、.TaskName
+//   - .RetryCount 当前重试次数，首次执行为 0
+//
+// 可用的函数：
+//   - addDays N、addHours N、addMinutes N：时间加减
+//   - date LAYOUT：按 Go 时间格式输出，如 date "2006-01-02"
+//
+// 示例：{{ .ScheduledTime | addDays -1 | date "2006-01-02" }}
+package paramtemplate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ErrInvalidTemplate 参数模板无法解析或展开
+var ErrInvalidTemplate = errors.New("invalid parameter template")
+
+// Error 某个参数的模板错误，Field 为以 . 分隔的字段路径，数组元素为 [i]
+type Error struct {
+	Field string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s: %v", ErrInvalidTemplate, e.Field, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return ErrInvalidTemplate
+}
+
+// Time 模板中的时间，直接输出时为 RFC3339 格式
+type Time struct {
+	time.Time
+}
+
+func (t Time) String() string {
+	return t.Format(time.RFC3339)
+}
+
+// Context 展开模板使用的执行上下文
+type Context struct {
+	ScheduledTime Time
+	ExecutionID   string
+	TaskID        string
+	TaskName      string
+	RetryCount    int
+}
+
+// NewContext 创建上下文，ScheduledTime 转换到 loc，loc 为 nil 时保持原时区
+func NewContext(scheduledTime time.Time, loc *time.Location, executionID, taskID, taskName string, retryCount int) Context {
+	if loc != nil {
+		scheduledTime = scheduledTime.In(loc)
+	}
+	return Context{
+		ScheduledTime: Time{scheduledTime},
+		ExecutionID:   executionID,
+		TaskID:        taskID,
+		TaskName:      taskName,
+		RetryCount:    retryCount,
+	}
+}
+
+var funcs = template.FuncMap{
+	"addDays":    func(n int, t Time) Time { return Time{t.AddDate(0, 0, n)} },
+	"addHours":   func(n int, t Time) Time { return Time{t.Add(time.Duration(n) * time.Hour)} },
+	"addMinutes": func(n int, t Time) Time { return Time{t.Add(time.Duration(n) * time.Minute)} },
+	"date":       func(layout string, t Time) string { return t.Format(layout) },
+}
+
+// IsTemplate 参数值是否需要展开
+func IsTemplate(s string) bool {
+	return strings.Contains(s, "{{")
+}
+
+// Render 返回展开模板后的参数副本，不修改 params；params 为 nil 时返回 nil
+func Render(params map[string]interface{}, ctx Context) (map[string]interface{}, error) {
+	if params == nil {
+		return nil, nil
+	}
+	rendered, err := renderValue(params, "", ctx)
+	if err != nil {
+		return nil, err
+	}
+	return rendered.(map[string]interface{}), nil
+}
+
+// Validate 用示例上下文展开一次参数，检查模板语法、变量和函数参数
+func Validate(params map[string]interface{}) error {
+	_, err := Render(params, SampleContext())
+	return err
+}
+
+// SampleContext 保存参数时用于校验的示例上下文
+func SampleContext() Context {
+	return NewContext(time.Now(), nil, "validate", "validate", "validate", 0)
+}
+
+func renderValue(value interface{}, field string, ctx Context) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		if !IsTemplate(v) {
+			return v, nil
+		}
+		return renderString(v, field, ctx)
+	case map[string]interface{}:
+		// 按键排序，多个字段出错时报告第一个
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out := make(map[string]interface{}, len(v))
+		for _, k := range keys {
+			child := k
+			if field != "" {
+				child = field + "." + k
+			}
+			rendered, err := renderValue(v[k], child, ctx)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = rendered
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			rendered, err := renderValue(item, fmt.Sprintf("%s[%d]", field, i), ctx)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = rendered
+		}
+		return out, nil
+	}
+	return value, nil
+}
+
+func renderString(text, field string, ctx Context) (string, error) {
+	tmpl, err := template.New(field).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", &Error{Field: field, Err: err}
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, ctx); err != nil {
+		return "", &Error{Field: field, Err: err}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,66 @@
+package paramtemplate
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	// UTC 1 月 1 日 16:30 在上海已是 1 月 2 日
+	ctx := NewContext(time.Date(2024, 1, 1, 16, 30, 0, 0, time.UTC), shanghai, "exec-1", "task-1", "daily_etl", 2)
+
+	params := map[string]interface{}{
+		"run_date":  `{{ .ScheduledTime | date "2006-01-02" }}`,
+		"prev_date": `{{ .ScheduledTime | addDays -1 | date "2006-01-02" }}`,
+		"window":    `{{ .ScheduledTime | addHours -1 | date "15:04" }}-{{ .ScheduledTime | date "15:04" }}`,
+		"at":        "{{ .ScheduledTime }}",
+		"batch":     "{{ .TaskName }}-{{ .ExecutionID }}-{{ .RetryCount }}",
+		"limit":     float64(100),
+		"nested": map[string]interface{}{
+			"paths": []interface{}{"/data/{{ .ScheduledTime | date \"2006/01/02\" }}", "/static"},
+		},
+	}
+
+	rendered, err := Render(params, ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"run_date":  "2024-01-02",
+		"prev_date": "2024-01-01",
+		"window":    "23:30-00:30",
+		"at":        "2024-01-02T00:30:00+08:00",
+		"batch":     "daily_etl-exec-1-2",
+		"limit":     float64(100),
+		"nested": map[string]interface{}{
+			"paths": []interface{}{"/data/2024/01/02", "/static"},
+		},
+	}, rendered)
+
+	// 原参数不被修改
+	assert.Equal(t, "{{ .ScheduledTime }}", params["at"])
+
+	rendered, err = Render(nil, ctx)
+	require.NoError(t, err)
+	assert.Nil(t, rendered)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(map[string]interface{}{"date": `{{ .ScheduledTime | date "2006-01-02" }}`, "plain": "x"}))
+
+	for field, params := range map[string]map[string]interface{}{
+		"date":          {"date": "{{ .ScheduledTime | date }"},
+		"name":          {"name": "{{ .LogicalDate }}"},
+		"offset":        {"offset": `{{ .ScheduledTime | addDays "one" }}`},
+		"outer.list[1]": {"outer": map[string]interface{}{"list": []interface{}{"ok", "{{ now }}"}}},
+	} {
+		err := Validate(params)
+		assert.ErrorIs(t, err, ErrInvalidTemplate, field)
+		var terr *Error
+		require.ErrorAs(t, err, &terr, field)
+		assert.Equal(t, field, terr.Field)
+	}
+}
@@ -75,6 +75,9 @@ func SetupTest(t *testing.T) *TestSetup {
 
 	// 创建存储
 	storageConfig := storage.Config{
+		Driver:                cfg.Database.Driver,
+		DSN:                   cfg.Database.DSN,
+		SSLMode:               cfg.Database.SSLMode,
 		Host:                  cfg.Database.Host,
 		Port:                  cfg.Database.Port,
 		Database:              cfg.Database.Database,
@@ -421,6 +424,7 @@ func createMockExecutor(t *testing.T, port string) *httptest.Server {
 				if callbackURL, ok := req["callback_url"].(string); ok {
 					callback := map[string]interface{}{
 						"execution_id": req["execution_id"],
+						"attempt":      req["attempt"],
 						"status":       "success",
 						"result":       map[string]interface{}{"test": "result"},
 						"logs":         "Task completed successfully",